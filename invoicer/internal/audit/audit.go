@@ -0,0 +1,164 @@
+// Package audit records the change history of invoices and quotes: who made
+// each change, when, and a field-level diff of the invoice before and after.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"reflect"
+	"sort"
+)
+
+// ignored lists fields left out of diffs: persistence metadata, and the status
+// history, which the entries' actions and times already record.
+var ignored = []string{"id", "userId", "createdAt", "updatedAt", "statusHistory"}
+
+// Journal returns a store.Journal that records a change to an invoice as
+// entry, with the changes between its state before and after. The entry's
+// action, actor and time are taken from entry; its invoice, owner and changes
+// are filled in.
+func Journal(entry models.AuditEntry) store.Journal {
+	return func(before, after *models.Invoice) (*models.AuditEntry, error) {
+		invoice := after
+		if invoice == nil {
+			invoice = before
+		}
+		recorded := entry
+		recorded.InvoiceID = invoice.ID
+		recorded.UserID = invoice.UserID
+
+		recorded.Changes = []models.FieldChange{}
+		if after != nil {
+			changes, err := Diff(before, after)
+			if err != nil {
+				return nil, err
+			}
+			recorded.Changes = append(recorded.Changes, changes...)
+		}
+		return &recorded, nil
+	}
+}
+
+// Diff returns the fields that differ between two states of an invoice, in
+// JSON path order. A nil before compares as an empty invoice, and its changes
+// then only have new values. Objects are
+// compared field by field and arrays element by element, so changing one line
+// item's rate yields a single "items[0].rate" change.
+func Diff(before, after *models.Invoice) ([]models.FieldChange, error) {
+	old, err := tree(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := tree(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []models.FieldChange
+	if err := diff("", old, updated, &changes); err != nil {
+		return nil, err
+	}
+	if before == nil {
+		for i := range changes {
+			changes[i].Old = nil
+		}
+	}
+	return changes, nil
+}
+
+// tree returns the invoice's JSON form as nested maps and slices, without the
+// ignored fields. Numbers are kept as written.
+func tree(invoice *models.Invoice) (map[string]interface{}, error) {
+	if invoice == nil {
+		invoice = &models.Invoice{}
+	}
+	data, err := json.Marshal(invoice)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var m map[string]interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, field := range ignored {
+		delete(m, field)
+	}
+	return m, nil
+}
+
+// diff appends the changes between two JSON values at path.
+func diff(path string, old, updated interface{}, changes *[]models.FieldChange) error {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		if u, ok := updated.(map[string]interface{}); ok {
+			for _, key := range keys(o, u) {
+				field := key
+				if path != "" {
+					field = path + "." + key
+				}
+				if err := diff(field, o[key], u[key], changes); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if u, ok := updated.([]interface{}); ok {
+			for i := 0; i < len(o) || i < len(u); i++ {
+				var oldItem, updatedItem interface{}
+				if i < len(o) {
+					oldItem = o[i]
+				}
+				if i < len(u) {
+					updatedItem = u[i]
+				}
+				if err := diff(fmt.Sprintf("%s[%d]", path, i), oldItem, updatedItem, changes); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	if reflect.DeepEqual(old, updated) {
+		return nil
+	}
+	change := models.FieldChange{Field: path}
+	var err error
+	if change.Old, err = raw(old); err != nil {
+		return err
+	}
+	if change.New, err = raw(updated); err != nil {
+		return err
+	}
+	*changes = append(*changes, change)
+	return nil
+}
+
+// keys returns the keys of both maps, sorted.
+func keys(a, b map[string]interface{}) []string {
+	var all []string
+	for key := range a {
+		all = append(all, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			all = append(all, key)
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// raw returns the JSON encoding of a value, or nil for an absent one.
+func raw(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/store"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := &models.Invoice{
+		ID:         "inv_1",
+		ClientName: "Globex",
+		Currency:   "USD",
+		Items: []models.LineItem{
+			{Description: "Design", Quantity: 1, Rate: money.New(10000, "USD")},
+		},
+	}
+	after := before.Clone()
+	after.UpdatedAt = time.Now()
+	after.ClientName = "Initech"
+	after.Items[0].Rate = money.New(12500, "USD")
+	after.Items = append(after.Items, models.LineItem{Description: "Hosting", Quantity: 2})
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	want := []struct{ field, old, new string }{
+		{"clientName", `"Globex"`, `"Initech"`},
+		{"items[0].rate", `100.00`, `125.00`},
+		{"items[1]", ``, `{"amount":0.00,"description":"Hosting","discountRate":0,"quantity":2,"rate":0.00,"taxRate":0}`},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Field != w.field || string(c.Old) != w.old || string(c.New) != w.new {
+			t.Errorf("changes[%d]: expected %s %s → %s, got %s %s → %s", i, w.field, w.old, w.new, c.Field, c.Old, c.New)
+		}
+	}
+
+	if changes, _ := Diff(after, after.Clone()); len(changes) != 0 {
+		t.Errorf("expected no changes between equal invoices, got %+v", changes)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	history := store.NewMemoryAuditStore()
+	invoices := store.NewMemoryInvoiceStore(history, nil)
+
+	invoice, err := invoices.Create("user_1", &models.Invoice{ClientName: "Globex", Status: models.StatusDraft},
+		Journal(models.AuditEntry{Action: models.AuditCreated, Actor: "user_1"}))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	invoices.Update("user_1", invoice.ID, func(inv *models.Invoice) error {
+		inv.Status = models.StatusIssued
+		return nil
+	}, Journal(models.AuditEntry{Action: models.AuditStatusChanged, Actor: "user_2"}))
+	invoices.Delete("user_1", invoice.ID, nil, Journal(models.AuditEntry{Action: models.AuditDeleted, Actor: "user_1"}))
+
+	entries, _ := history.List("user_1", invoice.ID)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if created := entries[0]; len(created.Changes) != 2 || created.Changes[0].Field != "clientName" || created.Changes[0].Old != nil {
+		t.Errorf("expected the created entry to list the new fields, got %+v", created.Changes)
+	}
+	if changed := entries[1]; changed.Actor != "user_2" || len(changed.Changes) != 1 || string(changed.Changes[0].New) != `"issued"` {
+		t.Errorf("expected a status change by user_2, got %+v", changed)
+	}
+	if deleted := entries[2]; deleted.InvoiceID != invoice.ID || deleted.Changes == nil || len(deleted.Changes) != 0 {
+		t.Errorf("expected a deletion without changes, got %+v", deleted)
+	}
+}
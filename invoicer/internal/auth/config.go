@@ -22,6 +22,10 @@ type AuthConfig struct {
 	// Rate limiting
 	RateLimitPerMin     int
 	RateLimitAuthPerMin int
+
+	// User storage
+	UserStoreDriver string // "sqlite" or "memory"
+	DatabasePath    string // SQLite database file (sqlite driver only)
 }
 
 // LoadAuthConfig reads auth configuration from environment variables.
@@ -58,6 +62,19 @@ func LoadAuthConfig() (*AuthConfig, error) {
 		rateLimitAuth = n
 	}
 
+	storeDriver := "sqlite"
+	if v := os.Getenv("USER_STORE"); v != "" {
+		if v != "sqlite" && v != "memory" {
+			return nil, fmt.Errorf("invalid USER_STORE %q: must be \"sqlite\" or \"memory\"", v)
+		}
+		storeDriver = v
+	}
+
+	dbPath := "invoicer.db"
+	if v := os.Getenv("DATABASE_PATH"); v != "" {
+		dbPath = v
+	}
+
 	return &AuthConfig{
 		JWTSecret:           secret,
 		JWTExpiry:           expiry,
@@ -67,5 +84,7 @@ func LoadAuthConfig() (*AuthConfig, error) {
 		GoogleRedirectURL:   os.Getenv("GOOGLE_REDIRECT_URL"),
 		RateLimitPerMin:     rateLimit,
 		RateLimitAuthPerMin: rateLimitAuth,
+		UserStoreDriver:     storeDriver,
+		DatabasePath:        dbPath,
 	}, nil
 }
@@ -23,11 +23,11 @@ type googleUserInfo struct {
 // OAuthService wraps Google OAuth2 configuration.
 type OAuthService struct {
 	config *oauth2.Config
-	store  *UserStore
+	store  UserStore
 }
 
 // NewOAuthService creates a new OAuth service. Returns nil if Google credentials are not configured.
-func NewOAuthService(clientID, clientSecret, redirectURL string, store *UserStore) *OAuthService {
+func NewOAuthService(clientID, clientSecret, redirectURL string, store UserStore) *OAuthService {
 	if clientID == "" || clientSecret == "" {
 		return nil
 	}
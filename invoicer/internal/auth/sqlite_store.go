@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// migrations are applied in order at startup. Each entry is run exactly once;
+// never edit an entry that has shipped — append a new one instead.
+var migrations = []string{
+	// 1: users table
+	`CREATE TABLE users (
+		seq           INTEGER PRIMARY KEY AUTOINCREMENT,
+		id            TEXT NOT NULL UNIQUE,
+		email         TEXT NOT NULL UNIQUE,
+		name          TEXT NOT NULL,
+		password_hash TEXT NOT NULL DEFAULT '',
+		provider      TEXT NOT NULL,
+		created_at    TEXT NOT NULL
+	)`,
+}
+
+// SQLiteUserStore is a UserStore backed by a SQLite database file.
+type SQLiteUserStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserStore opens (or creates) the SQLite database at path and
+// applies any pending schema migrations.
+func NewSQLiteUserStore(path string) (*SQLiteUserStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialising access through one
+	// connection avoids "database is locked" errors under concurrent requests.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteUserStore{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *SQLiteUserStore) Close() error {
+	return s.db.Close()
+}
+
+// migrate applies pending migrations, recording each applied version in schema_migrations.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateUser hashes the password and stores a new user. Returns error if email is taken.
+func (s *SQLiteUserStore) CreateUser(email, password, name, provider string) (*User, error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.insertUser(email, name, hash, provider)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: users.email") {
+			return nil, fmt.Errorf("email already registered")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByEmail looks up a user by email.
+func (s *SQLiteUserStore) GetUserByEmail(email string) (*User, error) {
+	return s.queryUser(`SELECT id, email, name, password_hash, provider, created_at FROM users WHERE email = ?`, email)
+}
+
+// GetUserByID looks up a user by ID.
+func (s *SQLiteUserStore) GetUserByID(id string) (*User, error) {
+	return s.queryUser(`SELECT id, email, name, password_hash, provider, created_at FROM users WHERE id = ?`, id)
+}
+
+// CheckPassword verifies a plaintext password against the stored hash.
+func (s *SQLiteUserStore) CheckPassword(user *User, password string) error {
+	return checkPassword(user, password)
+}
+
+// UpsertOAuthUser creates or retrieves an existing OAuth user (no password).
+func (s *SQLiteUserStore) UpsertOAuthUser(email, name, provider string) (*User, error) {
+	user, err := s.GetUserByEmail(email)
+	if err == nil {
+		return user, nil
+	}
+
+	user, err = s.insertUser(email, name, "", provider)
+	if err != nil {
+		// Lost a race with a concurrent upsert for the same email
+		if strings.Contains(err.Error(), "UNIQUE constraint failed: users.email") {
+			return s.GetUserByEmail(email)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// insertUser inserts a row and derives the public "user_<n>" ID from its sequence number.
+func (s *SQLiteUserStore) insertUser(email, name, hash, provider string) (*User, error) {
+	createdAt := time.Now().UTC()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The placeholder ID is unique per email and replaced once the sequence number is known.
+	res, err := tx.Exec(`INSERT INTO users (id, email, name, password_hash, provider, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		"pending:"+email, email, name, hash, provider, createdAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user sequence: %w", err)
+	}
+
+	id := fmt.Sprintf("user_%d", seq)
+	if _, err := tx.Exec(`UPDATE users SET id = ? WHERE seq = ?`, id, seq); err != nil {
+		return nil, fmt.Errorf("failed to assign user ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit user: %w", err)
+	}
+
+	return &User{
+		ID:           id,
+		Email:        email,
+		Name:         name,
+		PasswordHash: hash,
+		Provider:     provider,
+		CreatedAt:    createdAt,
+	}, nil
+}
+
+func (s *SQLiteUserStore) queryUser(query string, arg string) (*User, error) {
+	var (
+		user      User
+		createdAt string
+	)
+	err := s.db.QueryRow(query, arg).Scan(&user.ID, &user.Email, &user.Name, &user.PasswordHash, &user.Provider, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	user.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid created_at for user %s: %w", user.ID, err)
+	}
+	return &user, nil
+}
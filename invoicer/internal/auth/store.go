@@ -8,24 +8,55 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// UserStore is a thread-safe in-memory user store.
-type UserStore struct {
+// UserStore persists registered users.
+type UserStore interface {
+	// CreateUser hashes the password and stores a new user. Returns error if email is taken.
+	CreateUser(email, password, name, provider string) (*User, error)
+
+	// GetUserByEmail looks up a user by email.
+	GetUserByEmail(email string) (*User, error)
+
+	// GetUserByID looks up a user by ID.
+	GetUserByID(id string) (*User, error)
+
+	// CheckPassword verifies a plaintext password against the stored hash.
+	CheckPassword(user *User, password string) error
+
+	// UpsertOAuthUser creates or retrieves an existing OAuth user (no password).
+	UpsertOAuthUser(email, name, provider string) (*User, error)
+}
+
+// NewUserStoreFromConfig returns the user store backend selected by the config.
+func NewUserStoreFromConfig(cfg *AuthConfig) (UserStore, error) {
+	switch cfg.UserStoreDriver {
+	case "memory":
+		return NewMemoryUserStore(), nil
+	case "sqlite":
+		return NewSQLiteUserStore(cfg.DatabasePath)
+	default:
+		return nil, fmt.Errorf("unknown user store driver %q", cfg.UserStoreDriver)
+	}
+}
+
+// MemoryUserStore is a thread-safe in-memory user store. Its contents are lost on restart,
+// so it is intended for tests and local development.
+type MemoryUserStore struct {
 	mu      sync.RWMutex
 	users   map[string]*User  // keyed by user ID
 	byEmail map[string]string // email -> user ID index
 	nextID  int
 }
 
-// NewUserStore creates an empty user store.
-func NewUserStore() *UserStore {
-	return &UserStore{
+// NewMemoryUserStore creates an empty in-memory user store.
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
 		users:   make(map[string]*User),
 		byEmail: make(map[string]string),
 	}
 }
 
 // CreateUser hashes the password and stores a new user. Returns error if email is taken.
-func (s *UserStore) CreateUser(email, password, name, provider string) (*User, error) {
+func (s *MemoryUserStore) CreateUser(email, password, name, provider string) (*User, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -33,13 +64,9 @@ func (s *UserStore) CreateUser(email, password, name, provider string) (*User, e
 		return nil, fmt.Errorf("email already registered")
 	}
 
-	var hash string
-	if password != "" {
-		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-		if err != nil {
-			return nil, fmt.Errorf("failed to hash password: %w", err)
-		}
-		hash = string(h)
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
 	}
 
 	s.nextID++
@@ -60,7 +87,7 @@ func (s *UserStore) CreateUser(email, password, name, provider string) (*User, e
 }
 
 // GetUserByEmail looks up a user by email.
-func (s *UserStore) GetUserByEmail(email string) (*User, error) {
+func (s *MemoryUserStore) GetUserByEmail(email string) (*User, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -72,7 +99,7 @@ func (s *UserStore) GetUserByEmail(email string) (*User, error) {
 }
 
 // GetUserByID looks up a user by ID.
-func (s *UserStore) GetUserByID(id string) (*User, error) {
+func (s *MemoryUserStore) GetUserByID(id string) (*User, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -84,12 +111,12 @@ func (s *UserStore) GetUserByID(id string) (*User, error) {
 }
 
 // CheckPassword verifies a plaintext password against the stored hash.
-func (s *UserStore) CheckPassword(user *User, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+func (s *MemoryUserStore) CheckPassword(user *User, password string) error {
+	return checkPassword(user, password)
 }
 
 // UpsertOAuthUser creates or retrieves an existing OAuth user (no password).
-func (s *UserStore) UpsertOAuthUser(email, name, provider string) (*User, error) {
+func (s *MemoryUserStore) UpsertOAuthUser(email, name, provider string) (*User, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -112,3 +139,20 @@ func (s *UserStore) UpsertOAuthUser(email, name, provider string) (*User, error)
 	s.byEmail[email] = id
 	return user, nil
 }
+
+// hashPassword bcrypt-hashes a password. An empty password (OAuth users) yields an empty hash.
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(h), nil
+}
+
+// checkPassword verifies a plaintext password against the user's stored hash.
+func checkPassword(user *User, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+}
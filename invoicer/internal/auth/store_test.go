@@ -1,104 +1,157 @@
 package auth
 
 import (
+	"path/filepath"
 	"testing"
 )
 
-func TestUserStore_CreateAndGetUser(t *testing.T) {
-	store := NewUserStore()
-
-	user, err := store.CreateUser("test@example.com", "password123", "Test User", "local")
-	if err != nil {
-		t.Fatalf("CreateUser failed: %v", err)
-	}
-	if user.Email != "test@example.com" {
-		t.Errorf("expected email 'test@example.com', got %q", user.Email)
-	}
-	if user.PasswordHash == "" {
-		t.Error("expected non-empty password hash")
-	}
-	if user.PasswordHash == "password123" {
-		t.Error("password hash should not be the raw password")
-	}
-
-	// Get by email
-	found, err := store.GetUserByEmail("test@example.com")
-	if err != nil {
-		t.Fatalf("GetUserByEmail failed: %v", err)
-	}
-	if found.ID != user.ID {
-		t.Errorf("expected ID %q, got %q", user.ID, found.ID)
-	}
+// forEachUserStore runs fn against every UserStore backend.
+func forEachUserStore(t *testing.T, fn func(t *testing.T, store UserStore)) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, NewMemoryUserStore())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteUserStore(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteUserStore failed: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		fn(t, store)
+	})
+}
 
-	// Get by ID
-	found2, err := store.GetUserByID(user.ID)
-	if err != nil {
-		t.Fatalf("GetUserByID failed: %v", err)
-	}
-	if found2.Email != user.Email {
-		t.Errorf("expected email %q, got %q", user.Email, found2.Email)
-	}
+func TestUserStore_CreateAndGetUser(t *testing.T) {
+	forEachUserStore(t, func(t *testing.T, store UserStore) {
+		user, err := store.CreateUser("test@example.com", "password123", "Test User", "local")
+		if err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+		if user.Email != "test@example.com" {
+			t.Errorf("expected email 'test@example.com', got %q", user.Email)
+		}
+		if user.PasswordHash == "" {
+			t.Error("expected non-empty password hash")
+		}
+		if user.PasswordHash == "password123" {
+			t.Error("password hash should not be the raw password")
+		}
+
+		// Get by email
+		found, err := store.GetUserByEmail("test@example.com")
+		if err != nil {
+			t.Fatalf("GetUserByEmail failed: %v", err)
+		}
+		if found.ID != user.ID {
+			t.Errorf("expected ID %q, got %q", user.ID, found.ID)
+		}
+
+		// Get by ID
+		found2, err := store.GetUserByID(user.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID failed: %v", err)
+		}
+		if found2.Email != user.Email {
+			t.Errorf("expected email %q, got %q", user.Email, found2.Email)
+		}
+	})
 }
 
 func TestUserStore_DuplicateEmail(t *testing.T) {
-	store := NewUserStore()
+	forEachUserStore(t, func(t *testing.T, store UserStore) {
+		_, err := store.CreateUser("dup@example.com", "password123", "User 1", "local")
+		if err != nil {
+			t.Fatalf("first CreateUser failed: %v", err)
+		}
+
+		_, err = store.CreateUser("dup@example.com", "password123", "User 2", "local")
+		if err == nil {
+			t.Fatal("expected error for duplicate email")
+		}
+	})
+}
 
-	_, err := store.CreateUser("dup@example.com", "password123", "User 1", "local")
-	if err != nil {
-		t.Fatalf("first CreateUser failed: %v", err)
-	}
+func TestUserStore_CheckPassword(t *testing.T) {
+	forEachUserStore(t, func(t *testing.T, store UserStore) {
+		user, _ := store.CreateUser("pwd@example.com", "correctpassword", "User", "local")
+
+		// Correct password
+		if err := store.CheckPassword(user, "correctpassword"); err != nil {
+			t.Errorf("expected correct password to pass: %v", err)
+		}
+
+		// Wrong password
+		if err := store.CheckPassword(user, "wrongpassword"); err == nil {
+			t.Error("expected wrong password to fail")
+		}
+	})
+}
 
-	_, err = store.CreateUser("dup@example.com", "password123", "User 2", "local")
-	if err == nil {
-		t.Fatal("expected error for duplicate email")
-	}
+func TestUserStore_UpsertOAuthUser(t *testing.T) {
+	forEachUserStore(t, func(t *testing.T, store UserStore) {
+		// First upsert — creates user
+		user1, err := store.UpsertOAuthUser("oauth@example.com", "OAuth User", "google")
+		if err != nil {
+			t.Fatalf("UpsertOAuthUser(create) failed: %v", err)
+		}
+
+		// Second upsert — returns same user
+		user2, err := store.UpsertOAuthUser("oauth@example.com", "OAuth User", "google")
+		if err != nil {
+			t.Fatalf("UpsertOAuthUser(existing) failed: %v", err)
+		}
+		if user1.ID != user2.ID {
+			t.Errorf("expected same user ID, got %q and %q", user1.ID, user2.ID)
+		}
+	})
 }
 
-func TestUserStore_CheckPassword(t *testing.T) {
-	store := NewUserStore()
+func TestUserStore_UserNotFound(t *testing.T) {
+	forEachUserStore(t, func(t *testing.T, store UserStore) {
+		_, err := store.GetUserByEmail("nonexistent@example.com")
+		if err == nil {
+			t.Error("expected error for non-existent email")
+		}
+
+		_, err = store.GetUserByID("user_999")
+		if err == nil {
+			t.Error("expected error for non-existent ID")
+		}
+	})
+}
 
-	user, _ := store.CreateUser("pwd@example.com", "correctpassword", "User", "local")
+func TestSQLiteUserStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "persist.db")
 
-	// Correct password
-	if err := store.CheckPassword(user, "correctpassword"); err != nil {
-		t.Errorf("expected correct password to pass: %v", err)
+	store, err := NewSQLiteUserStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteUserStore failed: %v", err)
 	}
-
-	// Wrong password
-	if err := store.CheckPassword(user, "wrongpassword"); err == nil {
-		t.Error("expected wrong password to fail")
+	user, err := store.CreateUser("keep@example.com", "password123", "Keeper", "local")
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
 	}
-}
+	store.Close()
 
-func TestUserStore_UpsertOAuthUser(t *testing.T) {
-	store := NewUserStore()
-
-	// First upsert — creates user
-	user1, err := store.UpsertOAuthUser("oauth@example.com", "OAuth User", "google")
+	// Reopening runs migrations again; they must be a no-op on an up-to-date schema
+	reopened, err := NewSQLiteUserStore(path)
 	if err != nil {
-		t.Fatalf("UpsertOAuthUser(create) failed: %v", err)
+		t.Fatalf("reopen failed: %v", err)
 	}
+	defer reopened.Close()
 
-	// Second upsert — returns same user
-	user2, err := store.UpsertOAuthUser("oauth@example.com", "OAuth User", "google")
+	found, err := reopened.GetUserByID(user.ID)
 	if err != nil {
-		t.Fatalf("UpsertOAuthUser(existing) failed: %v", err)
+		t.Fatalf("GetUserByID after reopen failed: %v", err)
 	}
-	if user1.ID != user2.ID {
-		t.Errorf("expected same user ID, got %q and %q", user1.ID, user2.ID)
+	if err := reopened.CheckPassword(found, "password123"); err != nil {
+		t.Errorf("expected stored password hash to survive reopen: %v", err)
 	}
-}
 
-func TestUserStore_UserNotFound(t *testing.T) {
-	store := NewUserStore()
-
-	_, err := store.GetUserByEmail("nonexistent@example.com")
-	if err == nil {
-		t.Error("expected error for non-existent email")
+	next, err := reopened.CreateUser("next@example.com", "password123", "Next", "local")
+	if err != nil {
+		t.Fatalf("CreateUser after reopen failed: %v", err)
 	}
-
-	_, err = store.GetUserByID("user_999")
-	if err == nil {
-		t.Error("expected error for non-existent ID")
+	if next.ID == user.ID {
+		t.Errorf("expected a fresh ID after reopen, got reused %q", next.ID)
 	}
 }
@@ -0,0 +1,342 @@
+package calc
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"math"
+	"strings"
+)
+
+// ToleranceMinor is the largest difference, in minor units, between a
+// client-supplied amount and the computed amount that is still considered a
+// match. It absorbs the per-step rounding the UI does not perform.
+const ToleranceMinor = 1
+
+// LineTotals holds the computed amounts for a single line item.
+type LineTotals struct {
+	Base     money.Money      // quantity × rate, rounded once
+	Discount money.Money      // line-level discount, before or after tax
+	Net      money.Money      // base − any pre-tax discount, less the tax backed out of it when tax-inclusive
+	Taxes    []models.TaxLine // line-level taxes on the net amount
+	Tax      money.Money      // sum of the line-level taxes
+	Amount   money.Money      // net + tax − any after-tax discount
+}
+
+// Totals holds the computed amounts for a whole invoice.
+type Totals struct {
+	Lines          []LineTotals
+	Subtotal       money.Money      // sum of line amounts
+	DiscountAmount money.Money      // invoice-level discount, before or after tax, excluding late fees
+	InvoiceTaxes   []models.TaxLine // invoice-level taxes on the subtotal, less a pre-tax discount, excluding late fees
+	TaxAmount      money.Money      // sum of the invoice-level taxes
+	TaxSummary     []models.TaxLine // line and invoice-level taxes grouped by name and rate
+	Rounding       money.Money      // cash rounding added to the total, if the invoice asks for it
+	Total          money.Money      // subtotal − discount, plus tax unless tax-inclusive, plus rounding
+	AmountPaid     money.Money      // sum of recorded payments
+	CreditedAmount money.Money      // sum of credit notes issued against the invoice
+	LateFeeAmount  money.Money      // sum of late fees charged since the invoice was issued
+	BalanceDue     money.Money      // total + late fees − amount paid − credited amount
+}
+
+// Compute calculates every amount on the invoice from quantities, rates and
+// percentages. Client-supplied amounts are ignored. Each step is rounded to the
+// currency's minor units before it is used in the next, so printed figures
+// always add up.
+//
+// Discounts come off before tax unless they are marked after tax, in which
+// case they come off the amount including tax and leave the tax unchanged.
+// Fixed discounts never exceed the amount they are taken from.
+//
+// On a tax-inclusive invoice, rates already include tax: each tax is backed
+// out of the discounted line (or subtotal) instead of added to it, so the
+// amounts the customer was quoted stay as they are.
+//
+// With CashRounding set, the total is rounded to the smallest amount that can
+// be paid in cash in the invoice's currency (e.g. 0.05 CHF), and the
+// difference is reported as Rounding.
+func Compute(invoice *models.Invoice) Totals {
+	currency := invoice.Currency
+	totals := Totals{
+		Lines:    make([]LineTotals, len(invoice.Items)),
+		Subtotal: money.New(0, currency),
+	}
+
+	for i, item := range invoice.Items {
+		base := item.Rate.Extend(item.Quantity, currency)
+		d := item.AppliedDiscount()
+		discount := money.New(0, currency)
+		if !isAfterTax(d) {
+			discount = discountOn(base, d)
+		}
+		net, taxes, tax := applyTaxes(base.Sub(discount), item.AppliedTaxes(), invoice.TaxInclusive)
+		amount := net.Add(tax)
+		if isAfterTax(d) {
+			discount = discountOn(amount, d)
+			amount = amount.Sub(discount)
+		}
+
+		totals.Lines[i] = LineTotals{Base: base, Discount: discount, Net: net, Taxes: taxes, Tax: tax, Amount: amount}
+		totals.Subtotal = totals.Subtotal.Add(amount)
+	}
+
+	taxable := totals.Subtotal
+	d := invoice.AppliedDiscount()
+	totals.DiscountAmount = money.New(0, currency)
+	if !isAfterTax(d) {
+		totals.DiscountAmount = discountOn(taxable, d)
+	}
+	_, totals.InvoiceTaxes, totals.TaxAmount = applyTaxes(taxable.Sub(totals.DiscountAmount), invoice.AppliedTaxes(), invoice.TaxInclusive)
+	if isAfterTax(d) {
+		gross := taxable
+		if !invoice.TaxInclusive {
+			gross = gross.Add(totals.TaxAmount)
+		}
+		totals.DiscountAmount = discountOn(gross, d)
+	}
+	totals.TaxSummary = summarize(totals)
+	totals.Total = totals.Subtotal.Sub(totals.DiscountAmount)
+	if !invoice.TaxInclusive {
+		totals.Total = totals.Total.Add(totals.TaxAmount)
+	}
+	totals.Rounding = money.New(0, currency)
+	if invoice.CashRounding {
+		totals.Rounding = totals.Total.RoundToCash().Sub(totals.Total)
+		totals.Total = totals.Total.Add(totals.Rounding)
+	}
+
+	settle(&totals, invoice)
+	return totals
+}
+
+// settle adds up the payments, credit notes and late fees recorded against
+// the invoice and the balance they leave of totals.Total.
+func settle(totals *Totals, invoice *models.Invoice) {
+	currency := invoice.Currency
+	totals.AmountPaid = money.New(0, currency)
+	for _, p := range invoice.Payments {
+		totals.AmountPaid = totals.AmountPaid.Add(p.Amount.WithCurrency(currency))
+	}
+	totals.CreditedAmount = money.New(0, currency)
+	for _, c := range invoice.CreditNotes {
+		totals.CreditedAmount = totals.CreditedAmount.Add(c.Amount.WithCurrency(currency))
+	}
+	totals.LateFeeAmount = money.New(0, currency)
+	for _, fee := range invoice.LateFees {
+		totals.LateFeeAmount = totals.LateFeeAmount.Add(fee.Amount.WithCurrency(currency))
+	}
+	totals.BalanceDue = totals.Total.Add(totals.LateFeeAmount).Sub(totals.AmountPaid).Sub(totals.CreditedAmount)
+}
+
+// discountOn returns the discount d takes off amount, or zero for no
+// discount. A fixed discount is capped at the amount and takes its sign, so
+// credit notes (with negative amounts) get a negative discount.
+func discountOn(amount money.Money, d *models.Discount) money.Money {
+	if d == nil {
+		return money.New(0, amount.Currency())
+	}
+	if d.Kind != models.DiscountFixed {
+		return amount.Percent(d.Rate)
+	}
+	fixed := d.Amount.WithCurrency(amount.Currency()).Abs()
+	if fixed.Cmp(amount.Abs()) > 0 {
+		fixed = amount.Abs()
+	}
+	if amount.IsNegative() {
+		return fixed.Neg()
+	}
+	return fixed
+}
+
+// isAfterTax reports whether d comes off the amount including tax.
+func isAfterTax(d *models.Discount) bool {
+	return d != nil && d.AfterTax
+}
+
+// applyTaxes returns the net amount of a line or subtotal, the taxes charged on
+// it and their sum. Taxes are added to amount, or backed out of it when it is
+// tax-inclusive.
+func applyTaxes(amount money.Money, taxes []models.Tax, inclusive bool) (money.Money, []models.TaxLine, money.Money) {
+	if !inclusive {
+		lines, sum := chargeTaxes(amount, taxes)
+		return amount, lines, sum
+	}
+	return backOutTaxes(amount, taxes)
+}
+
+// backOutTaxes splits a tax-inclusive gross amount into its net amount and the
+// taxes charged on it. The net amount is rounded once; each tax is then charged
+// on it as usual and the last tax absorbs the rounding difference, so net plus
+// taxes always equals gross.
+func backOutTaxes(gross money.Money, taxes []models.Tax) (money.Money, []models.TaxLine, money.Money) {
+	net := gross.WithoutPercent(effectiveRate(taxes))
+	lines, sum := chargeTaxes(net, taxes)
+	if len(lines) > 0 {
+		last := len(lines) - 1
+		lines[last].Amount = lines[last].Amount.Add(gross.Sub(net).Sub(sum))
+		sum = gross.Sub(net)
+	}
+	return net, lines, sum
+}
+
+// effectiveRate returns the percentage the taxes add to an amount together,
+// counting each compound tax on the taxes before it: 10% plus a 5% compound
+// tax adds 15.5%.
+func effectiveRate(taxes []models.Tax) float64 {
+	var sum float64
+	for _, tax := range taxes {
+		base := 100.0
+		if tax.Compound {
+			base += sum
+		}
+		sum += base * tax.Rate / 100
+	}
+	// Drop float noise such as 7.700000000000001 so the rate is exact.
+	return math.Round(sum*1e9) / 1e9
+}
+
+// chargeTaxes charges each tax on base in order and returns the charges and
+// their sum. A compound tax is charged on base plus the taxes before it.
+func chargeTaxes(base money.Money, taxes []models.Tax) ([]models.TaxLine, money.Money) {
+	lines := make([]models.TaxLine, len(taxes))
+	sum := money.New(0, base.Currency())
+	for i, tax := range taxes {
+		taxBase := base
+		if tax.Compound {
+			taxBase = base.Add(sum)
+		}
+		amount := taxBase.Percent(tax.Rate)
+		lines[i] = models.TaxLine{Name: tax.Name, Rate: tax.Rate, Compound: tax.Compound, Base: taxBase, Amount: amount}
+		sum = sum.Add(amount)
+	}
+	return lines, sum
+}
+
+// summarize groups the line and invoice-level taxes by name, rate and
+// compounding, in the order they first appear. Each group's base and amount
+// are sums of the already-rounded charges, so the summary adds up to the
+// printed lines.
+func summarize(totals Totals) []models.TaxLine {
+	type key struct {
+		name     string
+		rate     float64
+		compound bool
+	}
+	var summary []models.TaxLine
+	index := make(map[key]int)
+	add := func(charge models.TaxLine) {
+		k := key{charge.Name, charge.Rate, charge.Compound}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(summary)
+			summary = append(summary, charge)
+			return
+		}
+		summary[i].Base = summary[i].Base.Add(charge.Base)
+		summary[i].Amount = summary[i].Amount.Add(charge.Amount)
+	}
+
+	for _, line := range totals.Lines {
+		for _, charge := range line.Taxes {
+			add(charge)
+		}
+	}
+	for _, charge := range totals.InvoiceTaxes {
+		add(charge)
+	}
+	return summary
+}
+
+// Apply computes the totals and overwrites the invoice's amounts with them.
+func Apply(invoice *models.Invoice) Totals {
+	invoice.StampCurrency()
+	totals := Compute(invoice)
+
+	for i := range invoice.Items {
+		invoice.Items[i].Amount = totals.Lines[i].Amount
+	}
+	invoice.Subtotal = totals.Subtotal
+	invoice.DiscountAmount = totals.DiscountAmount
+	invoice.TaxAmount = totals.TaxAmount
+	invoice.TaxSummary = totals.TaxSummary
+	invoice.Rounding = totals.Rounding
+	invoice.Total = totals.Total
+	invoice.AmountPaid = totals.AmountPaid
+	invoice.CreditedAmount = totals.CreditedAmount
+	invoice.LateFeeAmount = totals.LateFeeAmount
+	invoice.BalanceDue = totals.BalanceDue
+
+	return totals
+}
+
+// Settle refreshes the amount paid, credited amount, late fees and balance due
+// from the invoice's payments, credit notes and late fees. The lines and total
+// of an issued invoice are frozen, so they are taken as stored rather than
+// computed again.
+func Settle(invoice *models.Invoice) {
+	invoice.StampCurrency()
+	totals := Totals{Total: invoice.Total}
+	settle(&totals, invoice)
+
+	invoice.AmountPaid = totals.AmountPaid
+	invoice.CreditedAmount = totals.CreditedAmount
+	invoice.LateFeeAmount = totals.LateFeeAmount
+	invoice.BalanceDue = totals.BalanceDue
+}
+
+// Balance returns the balance Settle would leave on the invoice, without
+// changing it.
+func Balance(invoice *models.Invoice) money.Money {
+	totals := Totals{Total: invoice.Total.WithCurrency(invoice.Currency)}
+	settle(&totals, invoice)
+	return totals.BalanceDue
+}
+
+// Mismatch describes a client-supplied amount that disagrees with the computed one.
+type Mismatch struct {
+	Field    string
+	Supplied money.Money
+	Expected money.Money
+}
+
+// MismatchError is returned by Verify when one or more amounts do not match.
+type MismatchError struct {
+	Mismatches []Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	parts := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		parts[i] = fmt.Sprintf("%s (sent %s, expected %s)", m.Field, m.Supplied, m.Expected)
+	}
+	return "totals do not match: " + strings.Join(parts, "; ")
+}
+
+// Verify compares the invoice's client-supplied amounts with the computed totals
+// and returns a *MismatchError listing every amount outside ToleranceMinor.
+func Verify(invoice *models.Invoice) error {
+	totals := Compute(invoice)
+	currency := invoice.Currency
+
+	var mismatches []Mismatch
+	check := func(field string, supplied, expected money.Money) {
+		supplied = supplied.WithCurrency(currency)
+		if supplied.Sub(expected).Abs().Minor() > ToleranceMinor {
+			mismatches = append(mismatches, Mismatch{Field: field, Supplied: supplied, Expected: expected})
+		}
+	}
+
+	for i, item := range invoice.Items {
+		check(fmt.Sprintf("items[%d].amount", i), item.Amount, totals.Lines[i].Amount)
+	}
+	check("subtotal", invoice.Subtotal, totals.Subtotal)
+	check("discountAmount", invoice.DiscountAmount, totals.DiscountAmount)
+	check("taxAmount", invoice.TaxAmount, totals.TaxAmount)
+	check("rounding", invoice.Rounding, totals.Rounding)
+	check("total", invoice.Total, totals.Total)
+
+	if len(mismatches) > 0 {
+		return &MismatchError{Mismatches: mismatches}
+	}
+	return nil
+}
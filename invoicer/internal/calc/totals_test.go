@@ -0,0 +1,388 @@
+package calc
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+)
+
+func usd(minor int64) money.Money {
+	return money.New(minor, "USD")
+}
+
+func sampleInvoice() *models.Invoice {
+	return &models.Invoice{
+		Currency: "USD",
+		Items: []models.LineItem{
+			// 2 × 100 = 200, −10% = 180, +18% = 212.40
+			{Description: "Design", Quantity: 2, Rate: usd(10000), DiscountRate: 10, TaxRate: 18},
+			// 3 × 33.33 = 99.99
+			{Description: "Support", Quantity: 3, Rate: usd(3333)},
+		},
+		DiscountRate: 5,  // 312.39 × 5% = 15.62
+		TaxRate:      10, // 296.77 × 10% = 29.68
+	}
+}
+
+func TestCompute(t *testing.T) {
+	totals := Compute(sampleInvoice())
+
+	want := []LineTotals{
+		{Base: usd(20000), Discount: usd(2000), Tax: usd(3240), Amount: usd(21240)},
+		{Base: usd(9999), Discount: usd(0), Tax: usd(0), Amount: usd(9999)},
+	}
+	for i, w := range want {
+		got := totals.Lines[i]
+		if got.Base != w.Base || got.Discount != w.Discount || got.Tax != w.Tax || got.Amount != w.Amount {
+			t.Errorf("line %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	if totals.Subtotal != usd(31239) {
+		t.Errorf("expected subtotal 312.39, got %v", totals.Subtotal)
+	}
+	if totals.DiscountAmount != usd(1562) {
+		t.Errorf("expected discount 15.62, got %v", totals.DiscountAmount)
+	}
+	if totals.TaxAmount != usd(2968) {
+		t.Errorf("expected tax 29.68, got %v", totals.TaxAmount)
+	}
+	if totals.Total != usd(32645) {
+		t.Errorf("expected total 326.45, got %v", totals.Total)
+	}
+}
+
+func TestCompute_NoDriftAcrossManyLines(t *testing.T) {
+	invoice := &models.Invoice{Currency: "USD"}
+	for i := 0; i < 1000; i++ {
+		invoice.Items = append(invoice.Items, models.LineItem{Quantity: 1, Rate: usd(10)}) // 0.10 each
+	}
+
+	if total := Compute(invoice).Total; total != usd(10000) {
+		t.Errorf("expected total 100.00, got %v", total)
+	}
+}
+
+func TestApply_OverwritesClientAmounts(t *testing.T) {
+	invoice := sampleInvoice()
+	invoice.Items[0].Amount = usd(100)
+	invoice.Total = usd(100_000_000)
+
+	Apply(invoice)
+
+	if invoice.Items[0].Amount != usd(21240) {
+		t.Errorf("expected line amount 212.40, got %v", invoice.Items[0].Amount)
+	}
+	if invoice.Total != usd(32645) {
+		t.Errorf("expected total 326.45, got %v", invoice.Total)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	invoice := sampleInvoice()
+	Apply(invoice)
+
+	if err := Verify(invoice); err != nil {
+		t.Fatalf("expected computed totals to verify, got %v", err)
+	}
+
+	// Within tolerance: unrounded client arithmetic
+	invoice.Subtotal = usd(31240)
+	if err := Verify(invoice); err != nil {
+		t.Errorf("expected amount within tolerance to verify, got %v", err)
+	}
+
+	invoice.Total = usd(99900)
+	err := Verify(invoice)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected *MismatchError, got %v", err)
+	}
+	if len(mismatch.Mismatches) != 1 || mismatch.Mismatches[0].Field != "total" {
+		t.Errorf("expected a single mismatch on total, got %+v", mismatch.Mismatches)
+	}
+}
+
+func TestApply_BalanceDue(t *testing.T) {
+	invoice := sampleInvoice()
+	invoice.Payments = []models.Payment{{Amount: usd(10000)}, {Amount: usd(5000)}}
+	invoice.CreditNotes = []models.CreditNoteRef{{Amount: usd(2500)}}
+	Apply(invoice)
+
+	if invoice.AmountPaid != usd(15000) {
+		t.Errorf("expected amount paid 150.00, got %s", invoice.AmountPaid)
+	}
+	if invoice.CreditedAmount != usd(2500) {
+		t.Errorf("expected credited amount 25.00, got %s", invoice.CreditedAmount)
+	}
+	if want := invoice.Total.Sub(usd(17500)); invoice.BalanceDue != want {
+		t.Errorf("expected balance due %s, got %s", want, invoice.BalanceDue)
+	}
+}
+
+func TestCompute_LateFeesAddToBalanceDue(t *testing.T) {
+	invoice := sampleInvoice()
+	invoice.LateFees = []models.LateFee{{Description: "Late fee", Amount: money.New(2500, "")}}
+	invoice.Payments = []models.Payment{{Amount: usd(5000)}}
+	totals := Compute(invoice)
+
+	if totals.Subtotal != usd(31239) || totals.DiscountAmount != usd(1562) || totals.TaxAmount != usd(2968) || totals.Total != usd(32645) {
+		t.Errorf("expected the fee to leave subtotal, discount, tax and total unchanged, got %v / %v / %v / %v",
+			totals.Subtotal, totals.DiscountAmount, totals.TaxAmount, totals.Total)
+	}
+	// 326.45 + 25.00 − 50.00
+	if totals.LateFeeAmount != usd(2500) || totals.BalanceDue != usd(30145) {
+		t.Errorf("expected late fees 25.00 and balance due 301.45, got %v and %v", totals.LateFeeAmount, totals.BalanceDue)
+	}
+}
+
+func TestCompute_NamedAndCompoundTaxes(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Items: []models.LineItem{
+			// 1000.00: CGST 9% = 90.00, SGST 9% = 90.00
+			{Description: "Consulting", Quantity: 1, Rate: usd(100000), Taxes: []models.Tax{{Name: "CGST", Rate: 9}, {Name: "SGST", Rate: 9}}},
+			// 500.00 − 10% = 450.00: CGST 9% = 40.50, SGST 9% = 40.50
+			{Description: "Training", Quantity: 1, Rate: usd(50000), DiscountRate: 10, Taxes: []models.Tax{{Name: "CGST", Rate: 9}, {Name: "SGST", Rate: 9}}},
+			// 200.00: VAT 20% = 40.00, eco levy 2% compound on 240.00 = 4.80
+			{Description: "Appliance", Quantity: 1, Rate: usd(20000), Taxes: []models.Tax{{Name: "VAT", Rate: 20}, {Name: "Eco levy", Rate: 2, Compound: true}}},
+		},
+	}
+	totals := Compute(invoice)
+
+	if totals.Lines[2].Tax != usd(4480) || totals.Lines[2].Amount != usd(24480) {
+		t.Errorf("expected compound line tax 44.80 and amount 244.80, got %s / %s", totals.Lines[2].Tax, totals.Lines[2].Amount)
+	}
+
+	want := []models.TaxLine{
+		{Name: "CGST", Rate: 9, Base: usd(145000), Amount: usd(13050)},
+		{Name: "SGST", Rate: 9, Base: usd(145000), Amount: usd(13050)},
+		{Name: "VAT", Rate: 20, Base: usd(20000), Amount: usd(4000)},
+		{Name: "Eco levy", Rate: 2, Compound: true, Base: usd(24000), Amount: usd(480)},
+	}
+	if len(totals.TaxSummary) != len(want) {
+		t.Fatalf("expected %d summary rows, got %+v", len(want), totals.TaxSummary)
+	}
+	for i, w := range want {
+		if totals.TaxSummary[i] != w {
+			t.Errorf("summary row %d: expected %+v, got %+v", i, w, totals.TaxSummary[i])
+		}
+	}
+	if totals.Total != usd(195580) {
+		t.Errorf("expected total 1955.80, got %s", totals.Total)
+	}
+}
+
+func TestCompute_InvoiceLevelTaxesJoinSummary(t *testing.T) {
+	invoice := sampleInvoice()
+	invoice.TaxRate = 0
+	invoice.Taxes = []models.Tax{{Name: "Tax", Rate: 10}}
+	totals := Compute(invoice)
+
+	// Same result as the unnamed 10% invoice tax of sampleInvoice
+	if totals.TaxAmount != usd(2968) || totals.Total != usd(32645) {
+		t.Errorf("expected tax 29.68 and total 326.45, got %s / %s", totals.TaxAmount, totals.Total)
+	}
+	// The line's 18% and the invoice's 10% are separate rows
+	if len(totals.TaxSummary) != 2 || totals.TaxSummary[0].Rate != 18 || totals.TaxSummary[1].Base != usd(29677) {
+		t.Errorf("unexpected summary: %+v", totals.TaxSummary)
+	}
+}
+
+func TestCompute_TaxInclusive(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency:     "USD",
+		TaxInclusive: true,
+		Items: []models.LineItem{
+			// 120.00 including VAT 20%: 100.00 + 20.00
+			{Description: "Kettle", Quantity: 1, Rate: usd(12000), TaxRate: 20},
+			// 10.00 including 18%: 8.47 net, CGST 0.76, SGST takes the cent left over
+			{Description: "Tea", Quantity: 1, Rate: usd(1000), Taxes: []models.Tax{{Name: "CGST", Rate: 9}, {Name: "SGST", Rate: 9}}},
+			// 231.00 including VAT 10% and a 5% compound levy (15.5%): 200.00 + 20.00 + 11.00
+			{Description: "Heater", Quantity: 1, Rate: usd(23100), Taxes: []models.Tax{{Name: "VAT", Rate: 10}, {Name: "Eco levy", Rate: 5, Compound: true}}},
+		},
+	}
+	totals := Compute(invoice)
+
+	want := []LineTotals{
+		{Net: usd(10000), Tax: usd(2000), Amount: usd(12000)},
+		{Net: usd(847), Tax: usd(153), Amount: usd(1000)},
+		{Net: usd(20000), Tax: usd(3100), Amount: usd(23100)},
+	}
+	for i, w := range want {
+		got := totals.Lines[i]
+		if got.Net != w.Net || got.Tax != w.Tax || got.Amount != w.Amount {
+			t.Errorf("line %d: expected net %s, tax %s, amount %s; got %s, %s, %s", i, w.Net, w.Tax, w.Amount, got.Net, got.Tax, got.Amount)
+		}
+	}
+	if taxes := totals.Lines[1].Taxes; taxes[0].Amount != usd(76) || taxes[1].Amount != usd(77) {
+		t.Errorf("expected CGST 0.76 and SGST 0.77, got %+v", taxes)
+	}
+	if totals.Subtotal != usd(36100) || totals.Total != usd(36100) {
+		t.Errorf("expected subtotal and total 361.00, got %s / %s", totals.Subtotal, totals.Total)
+	}
+
+	// Invoice-level taxes are backed out of the discounted subtotal too.
+	invoice = &models.Invoice{
+		Currency:     "USD",
+		TaxInclusive: true,
+		Items:        []models.LineItem{{Description: "Kettle", Quantity: 1, Rate: usd(12000)}},
+		DiscountRate: 10, // 12.00
+		TaxRate:      20, // 108.00 including 18.00
+	}
+	totals = Compute(invoice)
+	if totals.TaxAmount != usd(1800) || totals.Total != usd(10800) {
+		t.Errorf("expected tax 18.00 included in a total of 108.00, got %s / %s", totals.TaxAmount, totals.Total)
+	}
+	if totals.TaxSummary[0].Base != usd(9000) {
+		t.Errorf("expected a taxable base of 90.00, got %s", totals.TaxSummary[0].Base)
+	}
+}
+
+func TestCompute_FixedAndAfterTaxDiscounts(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Items: []models.LineItem{
+			// 100.00 − 15.00 = 85.00, +10% = 93.50
+			{Description: "Design", Quantity: 1, Rate: usd(10000), TaxRate: 10, Discount: &models.Discount{Kind: models.DiscountFixed, Amount: usd(1500)}},
+			// 200.00 +10% = 220.00, then 10% off = 198.00
+			{Description: "Build", Quantity: 1, Rate: usd(20000), TaxRate: 10, Discount: &models.Discount{Kind: models.DiscountPercent, Rate: 10, AfterTax: true}},
+			// A fixed discount larger than the line only takes it to zero
+			{Description: "Sample", Quantity: 1, Rate: usd(1000), Discount: &models.Discount{Kind: models.DiscountFixed, Amount: usd(2500)}},
+		},
+		// 291.50 + 10% = 320.65, then 20.00 off
+		TaxRate:  10,
+		Discount: &models.Discount{Label: "Loyalty", Kind: models.DiscountFixed, Amount: usd(2000), AfterTax: true},
+	}
+	totals := Compute(invoice)
+
+	want := []LineTotals{
+		{Discount: usd(1500), Tax: usd(850), Amount: usd(9350)},
+		{Discount: usd(2200), Tax: usd(2000), Amount: usd(19800)},
+		{Discount: usd(1000), Tax: usd(0), Amount: usd(0)},
+	}
+	for i, w := range want {
+		got := totals.Lines[i]
+		if got.Discount != w.Discount || got.Tax != w.Tax || got.Amount != w.Amount {
+			t.Errorf("line %d: expected discount %s, tax %s, amount %s; got %s, %s, %s", i, w.Discount, w.Tax, w.Amount, got.Discount, got.Tax, got.Amount)
+		}
+	}
+	if totals.TaxAmount != usd(2915) || totals.DiscountAmount != usd(2000) || totals.Total != usd(30065) {
+		t.Errorf("expected tax 29.15, discount 20.00 and total 300.65, got %s, %s, %s", totals.TaxAmount, totals.DiscountAmount, totals.Total)
+	}
+}
+
+func TestCompute_VolumeTiers(t *testing.T) {
+	tiers := []models.DiscountTier{{MinQuantity: 50, Rate: 5}, {MinQuantity: 100, Rate: 10}}
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Items: []models.LineItem{
+			{Description: "Widgets", Quantity: 150, Rate: usd(100), DiscountTiers: tiers}, // 150.00 − 10%
+			{Description: "Widgets", Quantity: 60, Rate: usd(100), DiscountTiers: tiers},  // 60.00 − 5%
+			{Description: "Widgets", Quantity: 20, Rate: usd(100), DiscountTiers: tiers},  // below every tier
+		},
+	}
+	totals := Compute(invoice)
+
+	for i, want := range []money.Money{usd(13500), usd(5700), usd(2000)} {
+		if totals.Lines[i].Amount != want {
+			t.Errorf("line %d: expected %s, got %s", i, want, totals.Lines[i].Amount)
+		}
+	}
+}
+
+func TestCompute_CurrencyMinorUnits(t *testing.T) {
+	// Rates decoded from JSON are unstamped until the invoice's currency is known.
+	invoice := &models.Invoice{
+		Currency: "JPY",
+		Items:    []models.LineItem{{Description: "Tea", Quantity: 3, Rate: money.New(33350, "")}},
+		TaxRate:  10,
+	}
+	totals := Apply(invoice)
+	// The rate is not rounded to ¥334 first: 3 × 333.50 = 1000.5 → 1001, tax 100.1 → 100
+	if totals.Subtotal != money.New(1001, "JPY") || totals.TaxAmount != money.New(100, "JPY") || totals.Total.String() != "1101" {
+		t.Errorf("expected ¥1001 + ¥100 = ¥1101, got %s + %s = %s", totals.Subtotal, totals.TaxAmount, totals.Total)
+	}
+	if invoice.Items[0].Rate.String() != "333.50" {
+		t.Errorf("expected the rate to be kept at 333.50, got %s", invoice.Items[0].Rate)
+	}
+
+	invoice = &models.Invoice{
+		Currency: "KWD",
+		Items:    []models.LineItem{{Description: "Tea", Quantity: 2, Rate: money.FromFloat(1.125, "")}},
+	}
+	if totals := Apply(invoice); totals.Total.String() != "2.250" {
+		t.Errorf("expected KWD 2.250, got %s", totals.Total)
+	}
+}
+
+func TestCompute_SubCentRatesAtHighQuantities(t *testing.T) {
+	rate := func(s string) money.Money {
+		m, err := money.Parse(s, "")
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", s, err)
+		}
+		return m
+	}
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Items: []models.LineItem{
+			{Description: "Messages", Quantity: 1000, Rate: rate("0.125")},       // 125.00, not 1000 × 0.13
+			{Description: "API calls", Quantity: 2500000, Rate: rate("0.00015")}, // 375.00
+			{Description: "Storage", Quantity: 1234567, Rate: rate("0.000015")},  // 18.518505 → 18.52
+		},
+		TaxRate: 10,
+	}
+	totals := Apply(invoice)
+
+	for i, want := range []string{"125.00", "375.00", "18.52"} {
+		if got := totals.Lines[i].Amount.String(); got != want {
+			t.Errorf("line %d: expected %s, got %s", i, want, got)
+		}
+	}
+	if totals.Subtotal.String() != "518.52" || totals.TaxAmount.String() != "51.85" || totals.Total.String() != "570.37" {
+		t.Errorf("expected 518.52 + 51.85 = 570.37, got %s + %s = %s", totals.Subtotal, totals.TaxAmount, totals.Total)
+	}
+	if got := invoice.Items[1].Rate.String(); got != "0.00015" {
+		t.Errorf("expected the rate to keep its precision, got %s", got)
+	}
+}
+
+func TestCompute_OverflowIsReported(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Items:    []models.LineItem{{Description: "Too much", Quantity: 1e18, Rate: usd(10000)}},
+	}
+	if totals := Compute(invoice); !totals.Total.Overflowed() || !totals.BalanceDue.Overflowed() {
+		t.Errorf("expected the total of 1e18 × 100.00 to overflow, got %s", totals.Total)
+	}
+}
+
+func TestCompute_CashRounding(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency:     "CHF",
+		CashRounding: true,
+		Items:        []models.LineItem{{Description: "Coffee", Quantity: 3, Rate: money.New(433, "CHF")}},
+	}
+	totals := Compute(invoice)
+	// 12.99 rounds to 13.00
+	if totals.Rounding != money.New(1, "CHF") || totals.Total != money.New(1300, "CHF") || totals.BalanceDue != money.New(1300, "CHF") {
+		t.Errorf("expected rounding 0.01 and total 13.00, got %s and %s", totals.Rounding, totals.Total)
+	}
+
+	invoice.CashRounding = false
+	if totals := Compute(invoice); !totals.Rounding.IsZero() || totals.Total != money.New(1299, "CHF") {
+		t.Errorf("expected no rounding without cashRounding, got %s and %s", totals.Rounding, totals.Total)
+	}
+
+	// Currencies without a cash increment are left as they are.
+	invoice = &models.Invoice{
+		Currency:     "USD",
+		CashRounding: true,
+		Items:        []models.LineItem{{Description: "Coffee", Quantity: 3, Rate: usd(433)}},
+	}
+	if totals := Compute(invoice); !totals.Rounding.IsZero() || totals.Total != usd(1299) {
+		t.Errorf("expected USD totals to be unrounded, got %s and %s", totals.Rounding, totals.Total)
+	}
+}
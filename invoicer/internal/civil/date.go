@@ -0,0 +1,130 @@
+package civil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layout is the wire format of a Date.
+const Layout = "2006-01-02"
+
+// ErrInvalid is wrapped by errors for strings that are not valid dates.
+var ErrInvalid = errors.New("invalid date")
+
+// Date is a calendar date without a time of day or time zone, such as an
+// invoice date. The zero Date means "no date".
+//
+// In JSON a Date is a "YYYY-MM-DD" string; the zero Date is "". Decoding
+// rejects anything else, including impossible dates such as "2026-02-30".
+type Date struct {
+	t time.Time // midnight UTC; zero for no date
+}
+
+// New returns the date year-month-day. Out-of-range values are normalised,
+// so New(2026, 1, 32) is 1 February 2026.
+func New(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Of returns the UTC calendar date of t.
+func Of(t time.Time) Date {
+	t = t.UTC()
+	return New(t.Year(), t.Month(), t.Day())
+}
+
+// Parse parses a "YYYY-MM-DD" date.
+func Parse(s string) (Date, error) {
+	t, err := time.Parse(Layout, strings.TrimSpace(s))
+	if err != nil {
+		return Date{}, fmt.Errorf("%w %q: must be in YYYY-MM-DD format", ErrInvalid, s)
+	}
+	return Date{t: t}, nil
+}
+
+// MustParse is like Parse but panics on error. It is intended for tests and
+// constants.
+func MustParse(s string) Date {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool { return d.t.IsZero() }
+
+// Time returns midnight UTC at the start of the date.
+func (d Date) Time() time.Time { return d.t }
+
+// String formats the date as "YYYY-MM-DD", or "" for the zero Date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(Layout)
+}
+
+// AddDays returns the date n days after d (before, if n is negative).
+func (d Date) AddDays(n int) Date {
+	return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// AddMonths returns the date n months after d. The day is clamped to the end
+// of shorter months, so 31 January plus one month is the last day of February.
+func (d Date) AddMonths(n int) Date {
+	last := New(d.t.Year(), d.t.Month()+time.Month(n)+1, 0)
+	if d.t.Day() > last.t.Day() {
+		return last
+	}
+	return New(d.t.Year(), d.t.Month()+time.Month(n), d.t.Day())
+}
+
+// EndOfMonth returns the last day of d's month.
+func (d Date) EndOfMonth() Date {
+	return New(d.t.Year(), d.t.Month()+1, 0)
+}
+
+// DaysUntil returns the number of days from d to o, negative if o is earlier.
+func (d Date) DaysUntil(o Date) int {
+	return int(o.t.Sub(d.t).Hours() / 24)
+}
+
+// Before reports whether d is earlier than o.
+func (d Date) Before(o Date) bool { return d.t.Before(o.t) }
+
+// After reports whether d is later than o.
+func (d Date) After(o Date) bool { return d.t.After(o.t) }
+
+// MarshalJSON encodes the date as a "YYYY-MM-DD" string, or "" for the zero Date.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a "YYYY-MM-DD" string, "" or null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w %s: must be a YYYY-MM-DD string", ErrInvalid, data)
+	}
+	if strings.TrimSpace(s) == "" {
+		*d = Date{}
+		return nil
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,72 @@
+package civil
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	d, err := Parse("2026-03-01")
+	if err != nil || d.String() != "2026-03-01" {
+		t.Fatalf("expected 2026-03-01, got %q (%v)", d, err)
+	}
+
+	for _, s := range []string{"", "2026-02-30", "01/03/2026", "2026-3-1"} {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): expected ErrInvalid, got %v", s, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := MustParse("2026-01-31")
+
+	if got := d.AddDays(30).String(); got != "2026-03-02" {
+		t.Errorf("AddDays(30): got %s", got)
+	}
+	if got := d.AddMonths(1).String(); got != "2026-02-28" {
+		t.Errorf("AddMonths(1): expected the day clamped to 2026-02-28, got %s", got)
+	}
+	if got := d.AddMonths(2).String(); got != "2026-03-31" {
+		t.Errorf("AddMonths(2): got %s", got)
+	}
+	if got := MustParse("2028-02-10").EndOfMonth().String(); got != "2028-02-29" {
+		t.Errorf("EndOfMonth in a leap year: got %s", got)
+	}
+	if got := d.DaysUntil(MustParse("2026-03-02")); got != 30 {
+		t.Errorf("DaysUntil: got %d", got)
+	}
+	if !d.Before(d.AddDays(1)) || d.After(d) {
+		t.Error("unexpected ordering")
+	}
+	if got := Of(time.Date(2026, time.May, 4, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))); got.String() != "2026-05-05" {
+		t.Errorf("Of: expected the UTC date, got %s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A Date `json:"a"`
+		B Date `json:"b"`
+		C Date `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"2026-03-01","b":"","c":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if v.A.String() != "2026-03-01" || !v.B.IsZero() || !v.C.IsZero() {
+		t.Errorf("unexpected decode: %+v", v)
+	}
+
+	out, _ := json.Marshal(v)
+	if string(out) != `{"a":"2026-03-01","b":"","c":""}` {
+		t.Errorf("unexpected encode: %s", out)
+	}
+
+	for _, body := range []string{`{"a":"2026-13-01"}`, `{"a":20260301}`} {
+		if err := json.Unmarshal([]byte(body), &v); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", body, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package creditnotes
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrNotCreditable is returned when crediting an invoice that is not open
+	// (a draft, which should be edited instead, or one already paid or void).
+	ErrNotCreditable = errors.New("credit notes can only be issued against issued invoices that are not yet paid")
+
+	// ErrExceedsBalance is returned when the credit is larger than the balance due.
+	ErrExceedsBalance = errors.New("credit exceeds the balance due")
+
+	// ErrInvalidCredit wraps validation failures of the selected lines.
+	ErrInvalidCredit = errors.New("invalid credit note")
+)
+
+// Line selects a line of the original invoice to credit.
+type Line struct {
+	Index    int      `json:"index"`              // position in the original invoice's items
+	Quantity *float64 `json:"quantity,omitempty"` // quantity to credit; defaults to all that is not yet credited
+}
+
+// Build creates an issued credit note for the selected lines of the original
+// invoice. A line can only be credited up to the quantity that earlier credit
+// notes left uncredited. Lines are copied with negated quantities, so every amount on the
+// credit note is negative. The original's discounts and tax rates carry over
+// so the credited amounts match what was billed; fixed discounts are credited
+// in proportion to the amount credited.
+func Build(original *models.Invoice, lines []Line, at time.Time) (*models.Invoice, error) {
+	if !lifecycle.IsOpen(original) {
+		return nil, ErrNotCreditable
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: select at least one line to credit", ErrInvalidCredit)
+	}
+
+	items := make([]models.LineItem, 0, len(lines))
+	creditedLines := make([]models.CreditedLine, 0, len(lines))
+	credited := creditedQuantities(original)
+	seen := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		if line.Index < 0 || line.Index >= len(original.Items) {
+			return nil, fmt.Errorf("%w: line %d does not exist", ErrInvalidCredit, line.Index)
+		}
+		if seen[line.Index] {
+			return nil, fmt.Errorf("%w: line %d selected more than once", ErrInvalidCredit, line.Index)
+		}
+		seen[line.Index] = true
+
+		item := original.Items[line.Index]
+		remaining := remainingQuantity(item, credited[line.Index])
+		if remaining.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: line %d has already been credited in full", ErrInvalidCredit, line.Index)
+		}
+		quantity, _ := remaining.Float64()
+		if line.Quantity != nil {
+			quantity = *line.Quantity
+		}
+		if quantity <= 0 || decimal(quantity).Cmp(remaining) > 0 {
+			limit, _ := remaining.Float64()
+			return nil, fmt.Errorf("%w: quantity for line %d must be between 0 and %g", ErrInvalidCredit, line.Index, limit)
+		}
+
+		share := new(big.Rat).Quo(decimal(quantity), decimal(item.Quantity))
+		item.Discount = creditedDiscount(item.AppliedDiscount(), share)
+		item.DiscountRate = 0
+		item.DiscountTiers = nil
+		item.Quantity = -quantity
+		items = append(items, item)
+		creditedLines = append(creditedLines, models.CreditedLine{Index: line.Index, Quantity: quantity})
+	}
+
+	creditNote := &models.Invoice{
+		DocumentType:               models.DocumentCreditNote,
+		OriginalInvoiceID:          original.ID,
+		OriginalInvoiceNumber:      original.InvoiceNumber,
+		CreditedLines:              creditedLines,
+		InvoiceDate:                civil.Of(at),
+		BusinessProfileID:          original.BusinessProfileID,
+		BusinessName:               original.BusinessName,
+		BusinessEmail:              original.BusinessEmail,
+		BusinessPhone:              original.BusinessPhone,
+		BusinessAddress:            original.BusinessAddress,
+		BusinessTaxID:              original.BusinessTaxID,
+		BusinessRegistrationNumber: original.BusinessRegistrationNumber,
+		BusinessBank:               original.BusinessBank,
+		ClientName:                 original.ClientName,
+		ClientEmail:                original.ClientEmail,
+		ClientAddress:              original.ClientAddress,
+		Items:                      items,
+		DiscountRate:               original.DiscountRate,
+		Discount:                   creditedDiscount(original.Discount, big.NewRat(1, 1)),
+		PromoCode:                  original.PromoCode,
+		TaxRate:                    original.TaxRate,
+		Taxes:                      original.Taxes,
+		TaxInclusive:               original.TaxInclusive,
+		CashRounding:               original.CashRounding,
+		Currency:                   original.Currency,
+		BaseCurrency:               original.BaseCurrency,
+		ExchangeRate:               original.ExchangeRate,
+		ExchangeRateDate:           original.ExchangeRateDate,
+		SelectedTemplate:           original.SelectedTemplate,
+	}
+	if d := original.Discount; d != nil && d.Kind == models.DiscountFixed {
+		// Both subtotals are in the invoice's currency, so their minor units
+		// give the exact share credited.
+		credited, billed := calc.Compute(creditNote).Subtotal, calc.Compute(original).Subtotal
+		share := new(big.Rat)
+		if !billed.IsZero() {
+			share.SetFrac64(-credited.Minor(), billed.Minor())
+		}
+		creditNote.Discount = creditedDiscount(d, share)
+	}
+	calc.Apply(creditNote)
+
+	if err := checkBalance(original, creditNote); err != nil {
+		return nil, err
+	}
+
+	lifecycle.Init(creditNote, at)
+	if err := lifecycle.Transition(creditNote, models.StatusIssued, at); err != nil {
+		return nil, err
+	}
+	return creditNote, nil
+}
+
+// creditedDiscount returns the discount to credit for a share of what it was
+// billed on: percentages carry over, and fixed amounts are credited in
+// proportion. Discounts resolved from volume tiers keep the tier the whole
+// line reached.
+func creditedDiscount(d *models.Discount, share *big.Rat) *models.Discount {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	if c.Kind == models.DiscountFixed {
+		c.Amount = c.Amount.MulRat(share)
+	}
+	return &c
+}
+
+// creditedQuantities returns the quantity of each line of the invoice that
+// its credit notes have credited so far, keyed by line index.
+func creditedQuantities(invoice *models.Invoice) map[int]*big.Rat {
+	credited := make(map[int]*big.Rat)
+	for _, ref := range invoice.CreditNotes {
+		for _, line := range ref.Lines {
+			if credited[line.Index] == nil {
+				credited[line.Index] = new(big.Rat)
+			}
+			credited[line.Index].Add(credited[line.Index], decimal(line.Quantity))
+		}
+	}
+	return credited
+}
+
+// remainingQuantity returns the quantity of item that can still be credited.
+func remainingQuantity(item models.LineItem, credited *big.Rat) *big.Rat {
+	remaining := decimal(item.Quantity)
+	if credited != nil {
+		remaining.Sub(remaining, credited)
+	}
+	return remaining
+}
+
+// decimal returns a quantity at its shortest decimal representation, so that
+// crediting 0.1 and then 0.2 of a line of 0.3 leaves exactly nothing.
+func decimal(quantity float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(quantity, 'f', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Apply records a credit note against the original invoice and reduces its
+// balance due. An invoice whose balance is fully settled moves to paid.
+func Apply(original, creditNote *models.Invoice, at time.Time) error {
+	if !lifecycle.IsOpen(original) {
+		return ErrNotCreditable
+	}
+	if err := checkBalance(original, creditNote); err != nil {
+		return err
+	}
+	// Another credit note may have been applied since this one was built.
+	credited := creditedQuantities(original)
+	for _, line := range creditNote.CreditedLines {
+		if line.Index < 0 || line.Index >= len(original.Items) {
+			return fmt.Errorf("%w: line %d does not exist", ErrInvalidCredit, line.Index)
+		}
+		if decimal(line.Quantity).Cmp(remainingQuantity(original.Items[line.Index], credited[line.Index])) > 0 {
+			return fmt.Errorf("%w: line %d has already been credited", ErrInvalidCredit, line.Index)
+		}
+	}
+
+	original.CreditNotes = append(original.CreditNotes, models.CreditNoteRef{
+		ID:        creditNote.ID,
+		Number:    creditNote.InvoiceNumber,
+		Amount:    creditNote.Total.Neg(),
+		Lines:     creditNote.CreditedLines,
+		CreatedAt: at,
+	})
+	calc.Settle(original)
+
+	if !original.BalanceDue.IsPositive() {
+		return lifecycle.Transition(original, models.StatusPaid, at)
+	}
+	return nil
+}
+
+// checkBalance ensures the credit does not exceed the original's balance due.
+func checkBalance(original, creditNote *models.Invoice) error {
+	balance := calc.Balance(original)
+	if creditNote.Total.Neg().Cmp(balance) > 0 {
+		return fmt.Errorf("%w (balance due is %s)", ErrExceedsBalance, balance)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package creditnotes
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func usd(minor int64) money.Money {
+	return money.New(minor, "USD")
+}
+
+// issuedInvoice returns an issued invoice with a total of $330.00
+// (two lines plus 10% tax).
+func issuedInvoice() *models.Invoice {
+	invoice := &models.Invoice{
+		ID:            "inv_1",
+		Status:        models.StatusIssued,
+		InvoiceNumber: "INV-2026-00001",
+		BusinessName:  "Acme",
+		ClientName:    "Globex",
+		Currency:      "USD",
+		TaxRate:       10,
+		Items: []models.LineItem{
+			{Description: "Design", Quantity: 2, Rate: usd(10000)},
+			{Description: "Hosting", Quantity: 1, Rate: usd(10000)},
+		},
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func TestBuildAndApply(t *testing.T) {
+	original := issuedInvoice()
+	at := time.Date(2026, time.April, 2, 10, 0, 0, 0, time.UTC)
+	one := 1.0
+
+	creditNote, err := Build(original, []Line{{Index: 0, Quantity: &one}}, at)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if !creditNote.IsCreditNote() || creditNote.OriginalInvoiceNumber != "INV-2026-00001" {
+		t.Errorf("credit note not linked to original: %+v", creditNote)
+	}
+	if creditNote.Status != models.StatusIssued {
+		t.Errorf("expected credit note to be issued, got %q", creditNote.Status)
+	}
+	if creditNote.Items[0].Quantity != -1 || creditNote.Total != usd(-11000) {
+		t.Errorf("expected quantity -1 and total -110.00, got %g and %s", creditNote.Items[0].Quantity, creditNote.Total)
+	}
+
+	creditNote.ID, creditNote.InvoiceNumber = "inv_2", "CN-2026-00001"
+	if err := Apply(original, creditNote, at); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if original.CreditedAmount != usd(11000) || original.BalanceDue != usd(22000) {
+		t.Errorf("expected credited 110.00 / due 220.00, got %s / %s", original.CreditedAmount, original.BalanceDue)
+	}
+	if original.Status != models.StatusIssued {
+		t.Errorf("expected partially credited invoice to stay issued, got %q", original.Status)
+	}
+}
+
+func TestApply_FullCreditSettlesInvoice(t *testing.T) {
+	original := issuedInvoice()
+	at := time.Now()
+
+	creditNote, err := Build(original, []Line{{Index: 0}, {Index: 1}}, at)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := Apply(original, creditNote, at); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !original.BalanceDue.IsZero() || original.Status != models.StatusPaid {
+		t.Errorf("expected settled invoice, got balance %s and status %q", original.BalanceDue, original.Status)
+	}
+}
+
+func TestApply_KeepsIssuedTotals(t *testing.T) {
+	original := issuedInvoice()
+	// The total as issued, which a later change to the calculation would
+	// no longer produce from the lines.
+	original.Total = usd(32999)
+	at := time.Now()
+
+	creditNote, err := Build(original, []Line{{Index: 1}}, at)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := Apply(original, creditNote, at); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if original.Total != usd(32999) || original.Items[0].Amount != usd(20000) {
+		t.Errorf("expected the issued amounts to be kept, got total %s and line %s", original.Total, original.Items[0].Amount)
+	}
+	if original.BalanceDue != usd(21999) {
+		t.Errorf("expected the balance to follow the issued total, got %s", original.BalanceDue)
+	}
+}
+
+func TestBuild_Rejected(t *testing.T) {
+	three := 3.0
+	cases := []struct {
+		name   string
+		status models.InvoiceStatus
+		paid   money.Money
+		lines  []Line
+		want   error
+	}{
+		{"draft", models.StatusDraft, usd(0), []Line{{Index: 0}}, ErrNotCreditable},
+		{"no lines", models.StatusIssued, usd(0), nil, ErrInvalidCredit},
+		{"unknown line", models.StatusIssued, usd(0), []Line{{Index: 5}}, ErrInvalidCredit},
+		{"duplicate line", models.StatusIssued, usd(0), []Line{{Index: 1}, {Index: 1}}, ErrInvalidCredit},
+		{"quantity too large", models.StatusIssued, usd(0), []Line{{Index: 0, Quantity: &three}}, ErrInvalidCredit},
+		{"exceeds balance", models.StatusPartiallyPaid, usd(30000), []Line{{Index: 1}}, ErrExceedsBalance},
+	}
+	for _, c := range cases {
+		original := issuedInvoice()
+		original.Status = c.status
+		if !c.paid.IsZero() {
+			original.Payments = []models.Payment{{Amount: c.paid}}
+		}
+
+		if _, err := Build(original, c.lines, time.Now()); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestBuild_CreditsDiscountsAsBilled(t *testing.T) {
+	original := &models.Invoice{
+		ID:            "inv_1",
+		Status:        models.StatusIssued,
+		InvoiceNumber: "INV-2026-00001",
+		Currency:      "USD",
+		Items: []models.LineItem{
+			// 150 × 1.00 − 10% volume discount = 135.00
+			{Description: "Widgets", Quantity: 150, Rate: usd(100), DiscountTiers: []models.DiscountTier{{MinQuantity: 100, Rate: 10}}},
+			// 4 × 25.00 − 20.00 = 80.00
+			{Description: "Setup", Quantity: 4, Rate: usd(2500), Discount: &models.Discount{Kind: models.DiscountFixed, Amount: usd(2000)}},
+		},
+		// 215.00 − 43.00
+		Discount: &models.Discount{Kind: models.DiscountFixed, Amount: usd(4300)},
+	}
+	calc.Apply(original)
+
+	ten, one := 10.0, 1.0
+	creditNote, err := Build(original, []Line{{Index: 0, Quantity: &ten}, {Index: 1, Quantity: &one}}, time.Now())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// 10 widgets keep the 10% tier: −9.00; one setup credits a quarter of the
+	// fixed discount: −25.00 + 5.00 = −20.00
+	if creditNote.Items[0].Amount != usd(-900) || creditNote.Items[1].Amount != usd(-2000) {
+		t.Errorf("expected lines of -9.00 and -20.00, got %s and %s", creditNote.Items[0].Amount, creditNote.Items[1].Amount)
+	}
+	// The invoice discount is credited in proportion: 29.00 / 215.00 of 43.00 = 5.80
+	if creditNote.DiscountAmount != usd(-580) || creditNote.Total != usd(-2320) {
+		t.Errorf("expected discount -5.80 and total -23.20, got %s and %s", creditNote.DiscountAmount, creditNote.Total)
+	}
+}
+
+func TestBuild_LimitsToRemainingQuantity(t *testing.T) {
+	original := issuedInvoice()
+	at := time.Now()
+	one, two := 1.0, 2.0
+
+	first, err := Build(original, []Line{{Index: 0, Quantity: &one}}, at)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	// Built from the same state, but applied after first
+	stale, _ := Build(original, []Line{{Index: 0, Quantity: &two}}, at)
+	if err := Apply(original, first, at); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := original.CreditNotes[0].Lines; len(got) != 1 || got[0] != (models.CreditedLine{Index: 0, Quantity: 1}) {
+		t.Errorf("expected the credited quantity to be recorded, got %+v", got)
+	}
+
+	if _, err := Build(original, []Line{{Index: 0, Quantity: &two}}, at); !errors.Is(err, ErrInvalidCredit) {
+		t.Errorf("expected crediting more than the remaining quantity to fail, got %v", err)
+	}
+	if err := Apply(original, stale, at); !errors.Is(err, ErrInvalidCredit) {
+		t.Errorf("expected a stale credit note to be rejected, got %v", err)
+	}
+
+	// Without a quantity, the rest of the line is credited
+	rest, err := Build(original, []Line{{Index: 0}}, at)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if rest.Items[0].Quantity != -1 || rest.CreditedLines[0].Quantity != 1 {
+		t.Errorf("expected the remaining quantity of 1 to be credited, got %g", rest.Items[0].Quantity)
+	}
+	if err := Apply(original, rest, at); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if _, err := Build(original, []Line{{Index: 0}}, at); !errors.Is(err, ErrInvalidCredit) {
+		t.Errorf("expected a fully credited line to be rejected, got %v", err)
+	}
+}
+
+func TestBuild_FractionalQuantitiesAddUpExactly(t *testing.T) {
+	original := issuedInvoice()
+	original.Items[1].Quantity = 0.3
+	calc.Apply(original)
+	at := time.Now()
+
+	for _, q := range []float64{0.1, 0.2} {
+		creditNote, err := Build(original, []Line{{Index: 1, Quantity: &q}}, at)
+		if err != nil {
+			t.Fatalf("Build(%g) failed: %v", q, err)
+		}
+		if err := Apply(original, creditNote, at); err != nil {
+			t.Fatalf("Apply(%g) failed: %v", q, err)
+		}
+	}
+	if _, err := Build(original, []Line{{Index: 1}}, at); !errors.Is(err, ErrInvalidCredit) {
+		t.Errorf("expected 0.1 + 0.2 to credit a line of 0.3 in full, got %v", err)
+	}
+}
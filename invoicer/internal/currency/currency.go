@@ -0,0 +1,62 @@
+// Package currency is a registry of the ISO 4217 currencies invoices can be
+// issued in: their symbols, where the symbol goes, how many decimal places
+// amounts have and the smallest amount that can be paid in cash.
+package currency
+
+import "strings"
+
+// DefaultMinorUnits is the number of decimal places assumed for amounts whose
+// currency is unknown or not yet set.
+const DefaultMinorUnits = 2
+
+// MaxMinorUnits is the largest number of decimal places any currency uses.
+const MaxMinorUnits = 4
+
+// Currency describes one ISO 4217 currency.
+type Currency struct {
+	Code        string // ISO 4217 alphabetic code, e.g. "CHF"
+	Name        string // English name
+	Symbol      string // e.g. "$", "kr"; the code when there is no distinct symbol
+	SymbolAfter bool   // the symbol follows the amount: "100.00 kr"
+	MinorUnits  int    // decimal places: 2 for USD, 0 for JPY, 3 for KWD
+
+	// CashIncrement is the smallest amount, in minor units, that can be paid
+	// in cash, e.g. 5 for CHF (0.05). It is 0 when every minor unit can be.
+	CashIncrement int64
+}
+
+// Lookup returns the currency with the given code, ignoring case and
+// surrounding space.
+func Lookup(code string) (Currency, bool) {
+	c, ok := registry[Normalize(code)]
+	return c, ok
+}
+
+// Valid reports whether code is a known currency code. Codes are expected in
+// their normalized, upper-case form.
+func Valid(code string) bool {
+	_, ok := registry[code]
+	return ok
+}
+
+// Normalize trims and upper-cases a currency code.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// MinorUnits returns the number of decimal places for a currency code, or
+// DefaultMinorUnits if the code is unknown or empty.
+func MinorUnits(code string) int {
+	if c, ok := registry[code]; ok {
+		return c.MinorUnits
+	}
+	return DefaultMinorUnits
+}
+
+var registry = func() map[string]Currency {
+	m := make(map[string]Currency, len(iso4217))
+	for _, c := range iso4217 {
+		m[c.Code] = c
+	}
+	return m
+}()
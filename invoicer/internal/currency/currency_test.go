@@ -0,0 +1,60 @@
+package currency
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		code       string
+		minorUnits int
+		cash       int64
+	}{
+		{"USD", 2, 0},
+		{" jpy ", 0, 0},
+		{"KWD", 3, 0},
+		{"CLF", 4, 0},
+		{"CHF", 2, 5},
+		{"SEK", 2, 100},
+	}
+	for _, c := range cases {
+		cur, ok := Lookup(c.code)
+		if !ok {
+			t.Errorf("Lookup(%q): expected a currency", c.code)
+			continue
+		}
+		if cur.MinorUnits != c.minorUnits || cur.CashIncrement != c.cash {
+			t.Errorf("Lookup(%q): expected %d minor units and cash increment %d, got %+v", c.code, c.minorUnits, c.cash, cur)
+		}
+	}
+
+	for _, code := range []string{"", "XXX", "XAU", "ABC", "usd"} {
+		if Valid(code) {
+			t.Errorf("expected %q to be invalid", code)
+		}
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	if got := MinorUnits("BHD"); got != 3 {
+		t.Errorf("BHD: expected 3, got %d", got)
+	}
+	if got := MinorUnits(""); got != DefaultMinorUnits {
+		t.Errorf("empty code: expected %d, got %d", DefaultMinorUnits, got)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if len(registry) != len(iso4217) {
+		t.Errorf("expected %d unique codes, got %d", len(iso4217), len(registry))
+	}
+	for code, c := range registry {
+		if len(code) != 3 || Normalize(code) != code {
+			t.Errorf("%q is not an upper-case three-letter code", code)
+		}
+		if c.Symbol == "" || c.Name == "" {
+			t.Errorf("%s: expected a name and symbol", code)
+		}
+		if c.MinorUnits < 0 || c.MinorUnits > MaxMinorUnits {
+			t.Errorf("%s: minor units %d out of range", code, c.MinorUnits)
+		}
+	}
+}
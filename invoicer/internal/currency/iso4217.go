@@ -0,0 +1,176 @@
+package currency
+
+// iso4217 lists the active ISO 4217 currencies, including fund codes such as
+// CLF and USN. Codes without minor units (precious metals, XDR, the test code
+// XTS and XXX for "no currency") are left out, as nothing can be invoiced in
+// them.
+//
+// Cash increments follow the countries that have withdrawn their smallest
+// coins and round cash payments, e.g. Switzerland to 0.05 and Sweden to 1.
+var iso4217 = []Currency{
+	{"AED", "UAE Dirham", "د.إ", false, 2, 0},
+	{"AFN", "Afghani", "؋", false, 2, 0},
+	{"ALL", "Lek", "L", false, 2, 0},
+	{"AMD", "Armenian Dram", "֏", false, 2, 0},
+	{"AOA", "Kwanza", "Kz", false, 2, 0},
+	{"ARS", "Argentine Peso", "$", false, 2, 0},
+	{"AUD", "Australian Dollar", "A$", false, 2, 5},
+	{"AWG", "Aruban Florin", "ƒ", false, 2, 0},
+	{"AZN", "Azerbaijan Manat", "₼", false, 2, 0},
+	{"BAM", "Convertible Mark", "KM", false, 2, 0},
+	{"BBD", "Barbados Dollar", "Bds$", false, 2, 0},
+	{"BDT", "Taka", "৳", false, 2, 0},
+	{"BGN", "Bulgarian Lev", "лв", true, 2, 0},
+	{"BHD", "Bahraini Dinar", "BD", false, 3, 0},
+	{"BIF", "Burundi Franc", "FBu", false, 0, 0},
+	{"BMD", "Bermudian Dollar", "$", false, 2, 0},
+	{"BND", "Brunei Dollar", "B$", false, 2, 0},
+	{"BOB", "Boliviano", "Bs", false, 2, 0},
+	{"BOV", "Mvdol", "BOV", false, 2, 0},
+	{"BRL", "Brazilian Real", "R$", false, 2, 0},
+	{"BSD", "Bahamian Dollar", "B$", false, 2, 0},
+	{"BTN", "Ngultrum", "Nu.", false, 2, 0},
+	{"BWP", "Pula", "P", false, 2, 0},
+	{"BYN", "Belarusian Ruble", "Br", true, 2, 0},
+	{"BZD", "Belize Dollar", "BZ$", false, 2, 0},
+	{"CAD", "Canadian Dollar", "C$", false, 2, 5},
+	{"CDF", "Congolese Franc", "FC", false, 2, 0},
+	{"CHE", "WIR Euro", "CHE", false, 2, 0},
+	{"CHF", "Swiss Franc", "CHF", false, 2, 5},
+	{"CHW", "WIR Franc", "CHW", false, 2, 0},
+	{"CLF", "Unidad de Fomento", "UF", false, 4, 0},
+	{"CLP", "Chilean Peso", "$", false, 0, 0},
+	{"CNY", "Yuan Renminbi", "¥", false, 2, 0},
+	{"COP", "Colombian Peso", "$", false, 2, 0},
+	{"COU", "Unidad de Valor Real", "COU", false, 2, 0},
+	{"CRC", "Costa Rican Colon", "₡", false, 2, 0},
+	{"CUP", "Cuban Peso", "$", false, 2, 0},
+	{"CVE", "Cabo Verde Escudo", "Esc", true, 2, 0},
+	{"CZK", "Czech Koruna", "Kč", true, 2, 100},
+	{"DJF", "Djibouti Franc", "Fdj", false, 0, 0},
+	{"DKK", "Danish Krone", "kr.", true, 2, 50},
+	{"DOP", "Dominican Peso", "RD$", false, 2, 0},
+	{"DZD", "Algerian Dinar", "DA", true, 2, 0},
+	{"EGP", "Egyptian Pound", "E£", false, 2, 0},
+	{"ERN", "Nakfa", "Nfk", false, 2, 0},
+	{"ETB", "Ethiopian Birr", "Br", false, 2, 0},
+	{"EUR", "Euro", "€", false, 2, 0},
+	{"FJD", "Fiji Dollar", "FJ$", false, 2, 0},
+	{"FKP", "Falkland Islands Pound", "£", false, 2, 0},
+	{"GBP", "Pound Sterling", "£", false, 2, 0},
+	{"GEL", "Lari", "₾", false, 2, 0},
+	{"GHS", "Ghana Cedi", "GH₵", false, 2, 0},
+	{"GIP", "Gibraltar Pound", "£", false, 2, 0},
+	{"GMD", "Dalasi", "D", false, 2, 0},
+	{"GNF", "Guinean Franc", "FG", false, 0, 0},
+	{"GTQ", "Quetzal", "Q", false, 2, 0},
+	{"GYD", "Guyana Dollar", "G$", false, 2, 0},
+	{"HKD", "Hong Kong Dollar", "HK$", false, 2, 0},
+	{"HNL", "Lempira", "L", false, 2, 0},
+	{"HTG", "Gourde", "G", false, 2, 0},
+	{"HUF", "Forint", "Ft", true, 2, 500},
+	{"IDR", "Rupiah", "Rp", false, 2, 0},
+	{"ILS", "New Israeli Sheqel", "₪", false, 2, 10},
+	{"INR", "Indian Rupee", "₹", false, 2, 0},
+	{"IQD", "Iraqi Dinar", "IQD", false, 3, 0},
+	{"IRR", "Iranian Rial", "﷼", false, 2, 0},
+	{"ISK", "Iceland Krona", "kr", true, 0, 0},
+	{"JMD", "Jamaican Dollar", "J$", false, 2, 0},
+	{"JOD", "Jordanian Dinar", "JD", false, 3, 0},
+	{"JPY", "Yen", "¥", false, 0, 0},
+	{"KES", "Kenyan Shilling", "KSh", false, 2, 0},
+	{"KGS", "Som", "сом", true, 2, 0},
+	{"KHR", "Riel", "៛", false, 2, 0},
+	{"KMF", "Comorian Franc", "CF", false, 0, 0},
+	{"KPW", "North Korean Won", "₩", false, 2, 0},
+	{"KRW", "Won", "₩", false, 0, 0},
+	{"KWD", "Kuwaiti Dinar", "KD", false, 3, 0},
+	{"KYD", "Cayman Islands Dollar", "CI$", false, 2, 0},
+	{"KZT", "Tenge", "₸", false, 2, 0},
+	{"LAK", "Lao Kip", "₭", false, 2, 0},
+	{"LBP", "Lebanese Pound", "LBP", false, 2, 0},
+	{"LKR", "Sri Lanka Rupee", "Rs", false, 2, 0},
+	{"LRD", "Liberian Dollar", "L$", false, 2, 0},
+	{"LSL", "Loti", "L", false, 2, 0},
+	{"LYD", "Libyan Dinar", "LD", false, 3, 0},
+	{"MAD", "Moroccan Dirham", "MAD", true, 2, 0},
+	{"MDL", "Moldovan Leu", "L", true, 2, 0},
+	{"MGA", "Malagasy Ariary", "Ar", false, 2, 0},
+	{"MKD", "Denar", "ден", true, 2, 0},
+	{"MMK", "Kyat", "K", false, 2, 0},
+	{"MNT", "Tugrik", "₮", false, 2, 0},
+	{"MOP", "Pataca", "MOP$", false, 2, 0},
+	{"MRU", "Ouguiya", "UM", true, 2, 0},
+	{"MUR", "Mauritius Rupee", "Rs", false, 2, 0},
+	{"MVR", "Rufiyaa", "Rf", false, 2, 0},
+	{"MWK", "Malawi Kwacha", "MK", false, 2, 0},
+	{"MXN", "Mexican Peso", "$", false, 2, 0},
+	{"MXV", "Mexican Unidad de Inversion (UDI)", "MXV", false, 2, 0},
+	{"MYR", "Malaysian Ringgit", "RM", false, 2, 0},
+	{"MZN", "Mozambique Metical", "MT", true, 2, 0},
+	{"NAD", "Namibia Dollar", "N$", false, 2, 0},
+	{"NGN", "Naira", "₦", false, 2, 0},
+	{"NIO", "Cordoba Oro", "C$", false, 2, 0},
+	{"NOK", "Norwegian Krone", "kr", true, 2, 100},
+	{"NPR", "Nepalese Rupee", "Rs", false, 2, 0},
+	{"NZD", "New Zealand Dollar", "NZ$", false, 2, 10},
+	{"OMR", "Rial Omani", "OMR", false, 3, 0},
+	{"PAB", "Balboa", "B/.", false, 2, 0},
+	{"PEN", "Sol", "S/", false, 2, 0},
+	{"PGK", "Kina", "K", false, 2, 0},
+	{"PHP", "Philippine Peso", "₱", false, 2, 0},
+	{"PKR", "Pakistan Rupee", "Rs", false, 2, 0},
+	{"PLN", "Zloty", "zł", true, 2, 0},
+	{"PYG", "Guarani", "₲", false, 0, 0},
+	{"QAR", "Qatari Rial", "QR", false, 2, 0},
+	{"RON", "Romanian Leu", "lei", true, 2, 0},
+	{"RSD", "Serbian Dinar", "din.", true, 2, 0},
+	{"RUB", "Russian Ruble", "₽", true, 2, 0},
+	{"RWF", "Rwanda Franc", "FRw", false, 0, 0},
+	{"SAR", "Saudi Riyal", "SR", false, 2, 0},
+	{"SBD", "Solomon Islands Dollar", "SI$", false, 2, 0},
+	{"SCR", "Seychelles Rupee", "SR", false, 2, 0},
+	{"SDG", "Sudanese Pound", "SDG", false, 2, 0},
+	{"SEK", "Swedish Krona", "kr", true, 2, 100},
+	{"SGD", "Singapore Dollar", "S$", false, 2, 5},
+	{"SHP", "Saint Helena Pound", "£", false, 2, 0},
+	{"SLE", "Leone", "Le", false, 2, 0},
+	{"SOS", "Somali Shilling", "Sh", false, 2, 0},
+	{"SRD", "Surinam Dollar", "$", false, 2, 0},
+	{"SSP", "South Sudanese Pound", "SSP", false, 2, 0},
+	{"STN", "Dobra", "Db", false, 2, 0},
+	{"SVC", "El Salvador Colon", "₡", false, 2, 0},
+	{"SYP", "Syrian Pound", "LS", false, 2, 0},
+	{"SZL", "Lilangeni", "E", false, 2, 0},
+	{"THB", "Baht", "฿", false, 2, 0},
+	{"TJS", "Somoni", "SM", true, 2, 0},
+	{"TMT", "Turkmenistan New Manat", "m", true, 2, 0},
+	{"TND", "Tunisian Dinar", "DT", true, 3, 0},
+	{"TOP", "Pa'anga", "T$", false, 2, 0},
+	{"TRY", "Turkish Lira", "₺", false, 2, 0},
+	{"TTD", "Trinidad and Tobago Dollar", "TT$", false, 2, 0},
+	{"TWD", "New Taiwan Dollar", "NT$", false, 2, 0},
+	{"TZS", "Tanzanian Shilling", "TSh", false, 2, 0},
+	{"UAH", "Hryvnia", "₴", false, 2, 0},
+	{"UGX", "Uganda Shilling", "USh", false, 0, 0},
+	{"USD", "US Dollar", "$", false, 2, 0},
+	{"USN", "US Dollar (Next day)", "USN", false, 2, 0},
+	{"UYI", "Uruguay Peso en Unidades Indexadas (UI)", "UYI", false, 0, 0},
+	{"UYU", "Peso Uruguayo", "$U", false, 2, 0},
+	{"UYW", "Unidad Previsional", "UYW", false, 4, 0},
+	{"UZS", "Uzbekistan Sum", "soʻm", true, 2, 0},
+	{"VED", "Bolívar Soberano", "Bs.D", false, 2, 0},
+	{"VES", "Bolívar Soberano", "Bs.S", false, 2, 0},
+	{"VND", "Dong", "₫", true, 0, 0},
+	{"VUV", "Vatu", "VT", true, 0, 0},
+	{"WST", "Tala", "WS$", false, 2, 0},
+	{"XAF", "CFA Franc BEAC", "FCFA", true, 0, 0},
+	{"XCD", "East Caribbean Dollar", "EC$", false, 2, 0},
+	{"XCG", "Caribbean Guilder", "Cg", false, 2, 0},
+	{"XOF", "CFA Franc BCEAO", "CFA", true, 0, 0},
+	{"XPF", "CFP Franc", "F", true, 0, 0},
+	{"YER", "Yemeni Rial", "YER", false, 2, 0},
+	{"ZAR", "Rand", "R", false, 2, 10},
+	{"ZMW", "Zambian Kwacha", "ZK", false, 2, 0},
+	{"ZWG", "Zimbabwe Gold", "ZiG", false, 2, 0},
+}
@@ -0,0 +1,76 @@
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+)
+
+// ErrCurrencyMismatch is returned when a line would take its rate from a
+// catalog item priced in a currency other than the invoice's.
+var ErrCurrencyMismatch = errors.New("catalog item is priced in another currency")
+
+// Directory fills invoices from the user's saved clients, business profiles
+// and catalog items.
+type Directory struct {
+	clients  store.ClientStore
+	profiles store.BusinessProfileStore
+	catalog  store.CatalogStore
+}
+
+// New creates a directory backed by the given stores.
+func New(clients store.ClientStore, profiles store.BusinessProfileStore, catalog store.CatalogStore) *Directory {
+	return &Directory{clients: clients, profiles: profiles, catalog: catalog}
+}
+
+// Apply copies the referenced client, business profile and catalog items into
+// the invoice. Client defaults take precedence over profile defaults, and all
+// of them only fill fields the invoice leaves empty. Each reference is
+// resolved independently; the first error (store.ErrClientNotFound,
+// store.ErrBusinessProfileNotFound, or store.ErrCatalogItemNotFound or
+// ErrCurrencyMismatch naming the line) is returned.
+func (d *Directory) Apply(userID string, invoice *models.Invoice) error {
+	var firstErr error
+	record := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if invoice.ClientID != "" {
+		if client, err := d.clients.Get(userID, invoice.ClientID); err != nil {
+			record(err)
+		} else {
+			client.ApplyTo(invoice)
+		}
+	}
+
+	if invoice.BusinessProfileID != "" {
+		if profile, err := d.profiles.Get(userID, invoice.BusinessProfileID); err != nil {
+			record(err)
+		} else {
+			profile.ApplyTo(invoice)
+		}
+	}
+
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+		if item.CatalogItemID == "" {
+			continue
+		}
+		entry, err := d.catalog.Get(userID, item.CatalogItemID)
+		if err != nil {
+			record(fmt.Errorf("items[%d].catalogItemId: %w", i, err))
+			continue
+		}
+		if item.Rate.IsZero() && !entry.Rate.IsZero() && entry.Currency != invoice.Currency {
+			record(fmt.Errorf("items[%d].catalogItemId: %w (%s, not %s); set the line's rate",
+				i, ErrCurrencyMismatch, entry.Currency, invoice.Currency))
+			continue
+		}
+		entry.ApplyTo(item)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,86 @@
+package directory
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	clients := store.NewMemoryClientStore()
+	profiles := store.NewMemoryBusinessProfileStore()
+	catalog := store.NewMemoryCatalogStore()
+	d := New(clients, profiles, catalog)
+
+	client, _ := clients.Create("user_1", &models.Client{Name: "Globex", Currency: "EUR"})
+	profile, _ := profiles.Create("user_1", &models.BusinessProfile{
+		Name:     "Acme Ltd",
+		TaxID:    "GB123456789",
+		Currency: "GBP",
+		Bank:     models.BankDetails{IBAN: "GB82WEST12345698765432"},
+	})
+
+	consulting, _ := catalog.Create("user_1", &models.CatalogItem{
+		Name:     "Consulting",
+		Unit:     "hour",
+		Rate:     money.New(12000, ""),
+		Currency: "EUR",
+		TaxRate:  20,
+	})
+
+	invoice := &models.Invoice{
+		ClientID:          client.ID,
+		BusinessProfileID: profile.ID,
+		BusinessName:      "typo",
+		Items: []models.LineItem{
+			{CatalogItemID: consulting.ID, Quantity: 3},
+			{CatalogItemID: consulting.ID, Description: "Rush work", Quantity: 1, Rate: money.New(18000, "")},
+		},
+	}
+	if err := d.Apply("user_1", invoice); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if invoice.ClientName != "Globex" || invoice.BusinessName != "Acme Ltd" || invoice.BusinessTaxID != "GB123456789" {
+		t.Errorf("expected client and business details, got %q %q %q", invoice.ClientName, invoice.BusinessName, invoice.BusinessTaxID)
+	}
+	if invoice.BusinessBank.IBAN != "GB82WEST12345698765432" {
+		t.Errorf("expected bank details, got %+v", invoice.BusinessBank)
+	}
+	first, second := invoice.Items[0], invoice.Items[1]
+	if first.Description != "Consulting" || first.Unit != "hour" || first.Rate.Minor() != 12000 || first.TaxRate != 20 {
+		t.Errorf("expected catalog defaults on the first line, got %+v", first)
+	}
+	if second.Description != "Rush work" || second.Rate.Minor() != 18000 || second.TaxRate != 20 {
+		t.Errorf("expected explicit line fields to be kept, got %+v", second)
+	}
+	if invoice.Currency != "EUR" {
+		t.Errorf("expected client currency to take precedence, got %q", invoice.Currency)
+	}
+
+	other := &models.Invoice{BusinessProfileID: profile.ID}
+	if err := d.Apply("user_2", other); !errors.Is(err, store.ErrBusinessProfileNotFound) {
+		t.Errorf("expected ErrBusinessProfileNotFound for another user's profile, got %v", err)
+	}
+
+	// A rate is only filled in the currency it was set in.
+	gbp := &models.Invoice{Currency: "GBP", Items: []models.LineItem{
+		{CatalogItemID: consulting.ID, Quantity: 1, Rate: money.New(9000, "")},
+		{CatalogItemID: consulting.ID, Quantity: 1},
+	}}
+	err := d.Apply("user_1", gbp)
+	if !errors.Is(err, ErrCurrencyMismatch) || !strings.HasPrefix(err.Error(), "items[1].catalogItemId: ") {
+		t.Errorf("expected ErrCurrencyMismatch naming the second line, got %v", err)
+	}
+	if gbp.Items[0].Description != "Consulting" || gbp.Items[1].Rate.Minor() != 0 {
+		t.Errorf("expected only the line with its own rate to be filled, got %+v", gbp.Items)
+	}
+
+	unknown := &models.Invoice{Items: []models.LineItem{{Description: "x"}, {CatalogItemID: "cat_9"}}}
+	err = d.Apply("user_1", unknown)
+	if !errors.Is(err, store.ErrCatalogItemNotFound) || err.Error() != "items[1].catalogItemId: catalog item not found" {
+		t.Errorf("expected ErrCatalogItemNotFound naming the line, got %v", err)
+	}
+}
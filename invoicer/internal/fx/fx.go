@@ -0,0 +1,159 @@
+// Package fx converts invoices to their business's base currency. Each invoice
+// is stamped with the exchange rate of the day it is issued, and reports
+// convert it at that rate rather than today's.
+package fx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/store"
+	"math"
+	"os"
+	"strconv"
+)
+
+var (
+	// ErrNoRate is returned when the rate table has no rate between two
+	// currencies on or before the date asked for.
+	ErrNoRate = errors.New("no exchange rate")
+
+	// ErrInvalidRates is wrapped by errors for malformed rate tables.
+	ErrInvalidRates = errors.New("invalid exchange rates")
+)
+
+// Normalize upper-cases the currency codes in a day's rates.
+func Normalize(r *models.ExchangeRates) {
+	r.Base = currency.Normalize(r.Base)
+	rates := make(map[string]float64, len(r.Rates))
+	for code, rate := range r.Rates {
+		rates[currency.Normalize(code)] = rate
+	}
+	r.Rates = rates
+}
+
+// Validate checks that a day's rates have a date, a known base currency and
+// at least one positive rate for another known currency.
+func Validate(r *models.ExchangeRates) error {
+	if r.Date.IsZero() {
+		return fmt.Errorf("%w: date is required", ErrInvalidRates)
+	}
+	if !currency.Valid(r.Base) {
+		return fmt.Errorf("%w: base %q is not an ISO 4217 currency code", ErrInvalidRates, r.Base)
+	}
+	if len(r.Rates) == 0 {
+		return fmt.Errorf("%w: at least one rate is required", ErrInvalidRates)
+	}
+	for code, rate := range r.Rates {
+		if !currency.Valid(code) {
+			return fmt.Errorf("%w: %q is not an ISO 4217 currency code", ErrInvalidRates, code)
+		}
+		if code == r.Base {
+			return fmt.Errorf("%w: %s is the base currency", ErrInvalidRates, code)
+		}
+		if !(rate > 0) || math.IsInf(rate, 0) {
+			return fmt.Errorf("%w: rate for %s must be greater than zero", ErrInvalidRates, code)
+		}
+	}
+	return nil
+}
+
+// Load reads a JSON array of days' rates from a file, validates them and
+// adds them to the table. It returns how many days were loaded.
+func Load(path string, rates store.ExchangeRateStore) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var days []*models.ExchangeRates
+	if err := json.Unmarshal(data, &days); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRates, err)
+	}
+	for i, day := range days {
+		Normalize(day)
+		if err := Validate(day); err != nil {
+			return 0, fmt.Errorf("rates[%d]: %w", i, err)
+		}
+	}
+	for _, day := range days {
+		if err := rates.Put(day); err != nil {
+			return 0, err
+		}
+	}
+	return len(days), nil
+}
+
+// Rate returns how many units of to one unit of from buys, from the latest
+// day's rates on or before on that quote both currencies, and the date of
+// those rates. Rates between two quoted currencies are crossed through the
+// base and rounded to ten significant digits.
+func Rate(rates store.ExchangeRateStore, from, to string, on civil.Date) (float64, civil.Date, error) {
+	if from == to {
+		return 1, on, nil
+	}
+	days, err := rates.List()
+	if err != nil {
+		return 0, civil.Date{}, err
+	}
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		if day.Date.After(on) {
+			continue
+		}
+		fromRate, fromOK := quoted(day, from)
+		toRate, toOK := quoted(day, to)
+		if fromOK && toOK {
+			return crossRate(fromRate, toRate), day.Date, nil
+		}
+	}
+	return 0, civil.Date{}, fmt.Errorf("%w from %s to %s on or before %s", ErrNoRate, from, to, on)
+}
+
+// quoted returns the day's rate for a currency against its base.
+func quoted(day *models.ExchangeRates, code string) (float64, bool) {
+	if code == day.Base {
+		return 1, true
+	}
+	rate, ok := day.Rates[code]
+	return rate, ok
+}
+
+// crossRate returns the rate from a currency quoted at fromRate to one quoted
+// at toRate against the same base.
+func crossRate(fromRate, toRate float64) float64 {
+	if fromRate == 1 {
+		return toRate
+	}
+	rate, _ := strconv.ParseFloat(strconv.FormatFloat(toRate/fromRate, 'g', 10, 64), 64)
+	return rate
+}
+
+// Stamp records on the invoice the rate from its currency to its base
+// currency on the given date. Invoices without a base currency get no rate.
+func Stamp(invoice *models.Invoice, rates store.ExchangeRateStore, on civil.Date) error {
+	if invoice.BaseCurrency == "" {
+		invoice.ExchangeRate = 0
+		invoice.ExchangeRateDate = civil.Date{}
+		return nil
+	}
+	rate, date, err := Rate(rates, invoice.Currency, invoice.BaseCurrency, on)
+	if err != nil {
+		return err
+	}
+	invoice.ExchangeRate = rate
+	invoice.ExchangeRateDate = date
+	return nil
+}
+
+// ToBase converts an amount on the invoice to its base currency at the
+// stamped rate. It reports false if the invoice has no stamped rate.
+func ToBase(invoice *models.Invoice, amount money.Money) (money.Money, bool) {
+	if invoice.BaseCurrency == "" || invoice.ExchangeRate == 0 {
+		return money.Money{}, false
+	}
+	return amount.WithCurrency(invoice.Currency).Convert(invoice.ExchangeRate, invoice.BaseCurrency), true
+}
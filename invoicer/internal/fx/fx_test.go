@@ -0,0 +1,125 @@
+package fx
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/store"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func rateTable(t *testing.T) store.ExchangeRateStore {
+	t.Helper()
+	rates := store.NewMemoryExchangeRateStore()
+	rates.Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"INR": 90.5, "USD": 1.08}})
+	rates.Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-05"), Base: "EUR", Rates: map[string]float64{"USD": 1.1}})
+	return rates
+}
+
+func TestValidate(t *testing.T) {
+	valid := &models.ExchangeRates{Date: civil.MustParse("2026-03-02"), Base: "eur", Rates: map[string]float64{"inr": 90.5}}
+	Normalize(valid)
+	if err := Validate(valid); err != nil {
+		t.Errorf("expected normalized rates to be valid, got %v", err)
+	}
+
+	invalid := []*models.ExchangeRates{
+		{Base: "EUR", Rates: map[string]float64{"INR": 90.5}},
+		{Date: civil.MustParse("2026-03-02"), Base: "XYZ", Rates: map[string]float64{"INR": 90.5}},
+		{Date: civil.MustParse("2026-03-02"), Base: "EUR"},
+		{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"EUR": 1}},
+		{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"INR": 0}},
+	}
+	for i, r := range invalid {
+		if err := Validate(r); !errors.Is(err, ErrInvalidRates) {
+			t.Errorf("invalid[%d]: expected ErrInvalidRates, got %v", i, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	os.WriteFile(path, []byte(`[
+		{"date": "2026-03-02", "base": "eur", "rates": {"inr": 90.5}},
+		{"date": "2026-03-03", "base": "EUR", "rates": {"INR": 90.7}}
+	]`), 0o600)
+
+	rates := store.NewMemoryExchangeRateStore()
+	if n, err := Load(path, rates); err != nil || n != 2 {
+		t.Fatalf("expected 2 days loaded, got %d (err %v)", n, err)
+	}
+	if list, _ := rates.List(); list[0].Base != "EUR" || list[0].Rates["INR"] != 90.5 {
+		t.Errorf("expected normalized rates, got %+v", list[0])
+	}
+
+	os.WriteFile(path, []byte(`[{"date": "2026-03-04", "base": "EUR", "rates": {"INR": -1}}]`), 0o600)
+	if _, err := Load(path, rates); !errors.Is(err, ErrInvalidRates) {
+		t.Errorf("expected ErrInvalidRates, got %v", err)
+	}
+	if list, _ := rates.List(); len(list) != 2 {
+		t.Errorf("expected an invalid file to load nothing, got %d days", len(list))
+	}
+}
+
+func TestRate(t *testing.T) {
+	rates := rateTable(t)
+
+	tests := []struct {
+		from, to string
+		on       string
+		rate     float64
+		date     string
+	}{
+		{"EUR", "INR", "2026-03-02", 90.5, "2026-03-02"},
+		{"INR", "EUR", "2026-03-02", 0.01104972376, "2026-03-02"},
+		{"USD", "INR", "2026-03-04", 83.7962963, "2026-03-02"},
+		{"EUR", "USD", "2026-03-09", 1.1, "2026-03-05"},
+		// The latest day only quotes USD, so INR comes from an earlier day.
+		{"EUR", "INR", "2026-03-09", 90.5, "2026-03-02"},
+		{"INR", "INR", "2026-01-01", 1, "2026-01-01"},
+	}
+	for _, tt := range tests {
+		rate, date, err := Rate(rates, tt.from, tt.to, civil.MustParse(tt.on))
+		if err != nil || rate != tt.rate || date.String() != tt.date {
+			t.Errorf("%s→%s on %s: expected %v (%s), got %v (%s, err %v)", tt.from, tt.to, tt.on, tt.rate, tt.date, rate, date, err)
+		}
+	}
+
+	if _, _, err := Rate(rates, "EUR", "INR", civil.MustParse("2026-03-01")); !errors.Is(err, ErrNoRate) {
+		t.Errorf("expected ErrNoRate before the first day, got %v", err)
+	}
+	if _, _, err := Rate(rates, "EUR", "GBP", civil.MustParse("2026-03-09")); !errors.Is(err, ErrNoRate) {
+		t.Errorf("expected ErrNoRate for an unquoted currency, got %v", err)
+	}
+}
+
+func TestStampAndToBase(t *testing.T) {
+	rates := rateTable(t)
+	invoice := &models.Invoice{Currency: "EUR", BaseCurrency: "INR", Total: money.New(11900, "EUR")}
+
+	if err := Stamp(invoice, rates, civil.MustParse("2026-03-03")); err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	if invoice.ExchangeRate != 90.5 || invoice.ExchangeRateDate.String() != "2026-03-02" {
+		t.Errorf("expected 90.5 from 2026-03-02, got %v from %s", invoice.ExchangeRate, invoice.ExchangeRateDate)
+	}
+	if total, ok := ToBase(invoice, invoice.Total); !ok || total.String() != "10769.50" || total.Currency() != "INR" {
+		t.Errorf("expected INR 10769.50, got %s %s (ok %v)", total.Currency(), total, ok)
+	}
+
+	invoice.BaseCurrency = ""
+	if err := Stamp(invoice, rates, civil.MustParse("2026-03-03")); err != nil || invoice.ExchangeRate != 0 {
+		t.Errorf("expected no rate without a base currency, got %v (err %v)", invoice.ExchangeRate, err)
+	}
+	if _, ok := ToBase(invoice, invoice.Total); ok {
+		t.Error("expected no conversion without a stamped rate")
+	}
+
+	invoice.BaseCurrency = "GBP"
+	if err := Stamp(invoice, rates, civil.MustParse("2026-03-03")); !errors.Is(err, ErrNoRate) {
+		t.Errorf("expected ErrNoRate, got %v", err)
+	}
+}
@@ -11,12 +11,12 @@ import (
 // AuthHandler handles authentication HTTP requests.
 type AuthHandler struct {
 	jwtService   *auth.JWTService
-	userStore    *auth.UserStore
+	userStore    auth.UserStore
 	oauthService *auth.OAuthService
 }
 
 // NewAuthHandler creates a new auth handler.
-func NewAuthHandler(jwtService *auth.JWTService, userStore *auth.UserStore, oauthService *auth.OAuthService) *AuthHandler {
+func NewAuthHandler(jwtService *auth.JWTService, userStore auth.UserStore, oauthService *auth.OAuthService) *AuthHandler {
 	return &AuthHandler{
 		jwtService:   jwtService,
 		userStore:    userStore,
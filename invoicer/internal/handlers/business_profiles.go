@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/overdue"
+	"invoice-generator/invoicer/internal/store"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	ibanRegex = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicRegex  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// BusinessProfileHandler handles business profile requests.
+type BusinessProfileHandler struct {
+	profiles store.BusinessProfileStore
+}
+
+// NewBusinessProfileHandler creates a new business profile handler.
+func NewBusinessProfileHandler(profiles store.BusinessProfileStore) *BusinessProfileHandler {
+	return &BusinessProfileHandler{profiles: profiles}
+}
+
+// CreateProfile handles POST /api/business-profiles
+func (h *BusinessProfileHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	profile, ok := decodeProfile(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.profiles.Create(claims.UserID, profile)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListProfiles handles GET /api/business-profiles
+func (h *BusinessProfileHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	profiles, err := h.profiles.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list business profiles")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// GetProfile handles GET /api/business-profiles/{id}
+func (h *BusinessProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	profile, err := h.profiles.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpdateProfile handles PUT /api/business-profiles/{id}. Existing invoices keep
+// the details they copied; only invoices saved afterwards see the change.
+func (h *BusinessProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	profile, ok := decodeProfile(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.profiles.Update(claims.UserID, mux.Vars(r)["id"], profile)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteProfile handles DELETE /api/business-profiles/{id}
+func (h *BusinessProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	if err := h.profiles.Delete(claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeProfile reads and validates a business profile from the request body,
+// writing an error response and returning false if it is invalid.
+func decodeProfile(w http.ResponseWriter, r *http.Request) (*models.BusinessProfile, bool) {
+	var profile models.BusinessProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	profile.Name = strings.TrimSpace(profile.Name)
+	profile.Email = strings.TrimSpace(profile.Email)
+	profile.TaxID = strings.TrimSpace(profile.TaxID)
+	profile.RegistrationNumber = strings.TrimSpace(profile.RegistrationNumber)
+	profile.Currency = currency.Normalize(profile.Currency)
+	profile.BaseCurrency = currency.Normalize(profile.BaseCurrency)
+	profile.Bank.BankName = strings.TrimSpace(profile.Bank.BankName)
+	profile.Bank.AccountName = strings.TrimSpace(profile.Bank.AccountName)
+	profile.Bank.AccountNumber = strings.TrimSpace(profile.Bank.AccountNumber)
+	profile.Bank.IBAN = compactCode(profile.Bank.IBAN)
+	profile.Bank.BIC = compactCode(profile.Bank.BIC)
+	if profile.LateFee != nil {
+		profile.LateFee.Kind = strings.ToLower(strings.TrimSpace(profile.LateFee.Kind))
+		profile.LateFee.Currency = currency.Normalize(profile.LateFee.Currency)
+	}
+
+	if err := validateProfile(&profile); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, false
+	}
+	return &profile, true
+}
+
+// validateProfile checks the profile's identity, bank details and late fee policy.
+func validateProfile(profile *models.BusinessProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if profile.Email != "" && !emailRegex.MatchString(profile.Email) {
+		return fmt.Errorf("invalid email address")
+	}
+	if err := validateCurrency(profile.Currency, false); err != nil {
+		return err
+	}
+	if err := validateCurrency(profile.BaseCurrency, false); err != nil {
+		return fmt.Errorf("baseCurrency: %v", err)
+	}
+	if profile.Bank.IBAN != "" && !validIBAN(profile.Bank.IBAN) {
+		return fmt.Errorf("bank.iban is not a valid IBAN")
+	}
+	if profile.Bank.BIC != "" && !bicRegex.MatchString(profile.Bank.BIC) {
+		return fmt.Errorf("bank.bic must be an 8 or 11 character SWIFT/BIC code")
+	}
+	if profile.LateFee != nil {
+		if err := overdue.ValidatePolicy(profile.LateFee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compactCode uppercases a bank code and strips the spaces people type to
+// group its digits.
+func compactCode(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// validIBAN checks the IBAN's format and its ISO 13616 mod-97 check digits.
+func validIBAN(iban string) bool {
+	if !ibanRegex.MatchString(iban) {
+		return false
+	}
+
+	// Move the country code and check digits to the end and replace letters
+	// with two-digit numbers (A=10 … Z=35); the result must be 1 mod 97.
+	var digits strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			fmt.Fprintf(&digits, "%d", c-'A'+10)
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// writeProfileError maps business profile store errors to HTTP responses.
+func writeProfileError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrBusinessProfileNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "Business profile not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access business profile")
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CatalogHandler handles product and service catalog requests.
+type CatalogHandler struct {
+	catalog store.CatalogStore
+}
+
+// NewCatalogHandler creates a new catalog handler.
+func NewCatalogHandler(catalog store.CatalogStore) *CatalogHandler {
+	return &CatalogHandler{catalog: catalog}
+}
+
+// CreateItem handles POST /api/catalog
+func (h *CatalogHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	item, ok := decodeCatalogItem(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.catalog.Create(claims.UserID, item)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListItems handles GET /api/catalog
+func (h *CatalogHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	items, err := h.catalog.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list catalog items")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// GetItem handles GET /api/catalog/{id}
+func (h *CatalogHandler) GetItem(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	item, err := h.catalog.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+// UpdateItem handles PUT /api/catalog/{id}. Existing invoices keep the
+// details they copied; only invoices saved afterwards see the change.
+func (h *CatalogHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	item, ok := decodeCatalogItem(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.catalog.Update(claims.UserID, mux.Vars(r)["id"], item)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteItem handles DELETE /api/catalog/{id}
+func (h *CatalogHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	if err := h.catalog.Delete(claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeCatalogItem reads and validates a catalog item from the request body,
+// writing an error response and returning false if it is invalid.
+func decodeCatalogItem(w http.ResponseWriter, r *http.Request) (*models.CatalogItem, bool) {
+	var item models.CatalogItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	item.Name = strings.TrimSpace(item.Name)
+	item.Description = strings.TrimSpace(item.Description)
+	item.SKU = strings.TrimSpace(item.SKU)
+	item.Unit = strings.TrimSpace(item.Unit)
+	item.Currency = currency.Normalize(item.Currency)
+
+	if err := validateCatalogItem(&item); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, false
+	}
+	return &item, true
+}
+
+// validateCatalogItem checks the item's name and default amounts.
+func validateCatalogItem(item *models.CatalogItem) error {
+	if item.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if item.Rate.IsNegative() {
+		return fmt.Errorf("rate must not be negative")
+	}
+	if err := validateCurrency(item.Currency, !item.Rate.IsZero()); err != nil {
+		return err
+	}
+	if err := validateTaxes("", item.Taxes, item.TaxRate); err != nil {
+		return err
+	}
+	return validateTiers("", item.DiscountTiers)
+}
+
+// writeCatalogError maps catalog store errors to HTTP responses.
+func writeCatalogError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrCatalogItemNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Catalog item not found")
+	case errors.Is(err, store.ErrDuplicateSKU):
+		writeError(w, http.StatusConflict, "conflict", "SKU already in use")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access catalog item")
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/pdf"
+	"invoice-generator/invoicer/internal/store"
+	"invoice-generator/invoicer/internal/terms"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ClientHandler handles client directory requests.
+type ClientHandler struct {
+	clients store.ClientStore
+}
+
+// NewClientHandler creates a new client directory handler.
+func NewClientHandler(clients store.ClientStore) *ClientHandler {
+	return &ClientHandler{clients: clients}
+}
+
+// CreateClient handles POST /api/clients
+func (h *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	client, ok := decodeClient(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.clients.Create(claims.UserID, client)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListClients handles GET /api/clients
+func (h *ClientHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	clients, err := h.clients.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list clients")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, clients)
+}
+
+// GetClient handles GET /api/clients/{id}
+func (h *ClientHandler) GetClient(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	client, err := h.clients.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, client)
+}
+
+// UpdateClient handles PUT /api/clients/{id}. Existing invoices keep the
+// details they copied; only invoices saved afterwards see the change.
+func (h *ClientHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	client, ok := decodeClient(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.clients.Update(claims.UserID, mux.Vars(r)["id"], client)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteClient handles DELETE /api/clients/{id}
+func (h *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	if err := h.clients.Delete(claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeClientError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeClient reads and validates a client from the request body, writing
+// an error response and returning false if it is invalid.
+func decodeClient(w http.ResponseWriter, r *http.Request) (*models.Client, bool) {
+	var client models.Client
+	if err := json.NewDecoder(r.Body).Decode(&client); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	client.Name = strings.TrimSpace(client.Name)
+	client.Email = strings.TrimSpace(client.Email)
+	client.Currency = currency.Normalize(client.Currency)
+
+	if err := validateClient(&client); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, false
+	}
+	return &client, true
+}
+
+// validateClient checks the client's contact details and defaults.
+func validateClient(client *models.Client) error {
+	if client.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if client.Email != "" && !emailRegex.MatchString(client.Email) {
+		return fmt.Errorf("invalid email address")
+	}
+	if err := validateCurrency(client.Currency, false); err != nil {
+		return err
+	}
+	if client.PaymentTerms != "" {
+		t, err := terms.Parse(client.PaymentTerms)
+		if err != nil {
+			return err
+		}
+		client.PaymentTerms = t.Code
+	}
+	if err := validateTaxes("", client.Taxes, client.TaxRate); err != nil {
+		return err
+	}
+	if client.DiscountRate < 0 || client.DiscountRate > 100 {
+		return fmt.Errorf("discountRate must be between 0 and 100")
+	}
+	if client.Template != "" && !pdf.IsTemplate(client.Template) {
+		return fmt.Errorf("template must be one of %s", strings.Join(pdf.Templates, ", "))
+	}
+	return nil
+}
+
+// writeClientError maps client store errors to HTTP responses.
+func writeClientError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrClientNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "Client not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access client")
+}
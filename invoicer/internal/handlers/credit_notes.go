@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/creditnotes"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/snapshot"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CreditNoteRequest is the body for POST /api/invoices/{id}/credit-notes.
+type CreditNoteRequest struct {
+	Lines []creditnotes.Line `json:"lines"`
+	Date  civil.Date         `json:"date"` // YYYY-MM-DD; defaults to today
+	Notes string             `json:"notes"`
+}
+
+// CreditNoteResponse is returned after issuing a credit note.
+type CreditNoteResponse struct {
+	CreditNote *models.Invoice `json:"creditNote"`
+	Invoice    *models.Invoice `json:"invoice"`
+}
+
+// CreateCreditNote handles POST /api/invoices/{id}/credit-notes. It issues a
+// credit note for the selected lines of the invoice, numbered from the credit
+// note sequence and frozen like an issued invoice, and reduces the invoice's
+// balance due by its amount.
+func (h *InvoiceHandler) CreateCreditNote(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+	id := mux.Vars(r)["id"]
+
+	var req CreditNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	original, err := h.store.Get(claims.UserID, id)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	creditNote, err := creditnotes.Build(original, req.Lines, now)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	if !req.Date.IsZero() {
+		creditNote.InvoiceDate = req.Date
+	}
+	creditNote.Notes = req.Notes
+
+	// The credit note and the original's balance are saved inside the
+	// allocation commit, so a failure on either side leaves no gap in the
+	// credit note sequence.
+	var created, updated *models.Invoice
+	_, err = h.numbers.Allocate(claims.UserID, numbering.ScopeCreditNote, numberingDate(creditNote.InvoiceDate), func(number string) error {
+		creditNote.InvoiceNumber = number
+		frozen, err := snapshot.Freeze(creditNote, now)
+		if err != nil {
+			return err
+		}
+		created, err = h.store.Create(claims.UserID, creditNote, h.journal(r, models.AuditCreated))
+		if errors.Is(err, store.ErrDuplicateNumber) {
+			return numbering.ErrNumberTaken
+		}
+		if err != nil {
+			return err
+		}
+		if err := h.storeSnapshot(r, frozen, created); err != nil {
+			return err
+		}
+
+		updated, err = h.store.Update(claims.UserID, id, func(invoice *models.Invoice) error {
+			return creditnotes.Apply(invoice, created, now)
+		}, h.journal(r, models.AuditCredited))
+		if err != nil {
+			h.discard(r, created)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreditNoteResponse{CreditNote: created, Invoice: updated})
+}
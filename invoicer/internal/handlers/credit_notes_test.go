@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"testing"
+)
+
+// createIssued creates draftInvoice and issues it.
+func (s *testServer) createIssued(t *testing.T) *models.Invoice {
+	t.Helper()
+	invoice := s.createDraft(t)
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusOK), invoice)
+	return invoice
+}
+
+func TestCreateCreditNote(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createIssued(t)
+
+	var credited CreditNoteResponse
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/credit-notes", `{"lines":[{"index":1}],"notes":"Hosting cancelled"}`, http.StatusCreated), &credited)
+	creditNote := credited.CreditNote
+	if !creditNote.IsCreditNote() || creditNote.InvoiceNumber == "" || creditNote.Status != models.StatusIssued {
+		t.Errorf("expected an issued, numbered credit note, got %+v", creditNote)
+	}
+	if creditNote.OriginalInvoiceNumber != invoice.InvoiceNumber {
+		t.Errorf("expected the credit note to name %s, got %q", invoice.InvoiceNumber, creditNote.OriginalInvoiceNumber)
+	}
+	if got := creditNote.Total.String(); got != "-50.00" {
+		t.Errorf("expected a credit of -50.00, got %s", got)
+	}
+	if got := credited.Invoice.BalanceDue.String(); got != "200.00" {
+		t.Errorf("expected a balance of 200.00 after the credit, got %s", got)
+	}
+	if _, err := s.snapshots.Get("user_1", creditNote.ID); err != nil {
+		t.Errorf("expected the credit note to be frozen, got %v", err)
+	}
+
+	// The credited line cannot be credited again.
+	if code := errorCode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/credit-notes", `{"lines":[{"index":1}]}`, http.StatusBadRequest)); code != "validation_error" {
+		t.Errorf("expected validation_error for a fully credited line, got %q", code)
+	}
+}
+
+func TestCreateCreditNote_Invalid(t *testing.T) {
+	s := newTestServer()
+	draft := s.createDraft(t)
+	issued := s.createIssued(t)
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"unknown invoice", "inv_404", `{"lines":[{"index":0}]}`, http.StatusNotFound},
+		{"draft invoice", draft.ID, `{"lines":[{"index":0}]}`, http.StatusConflict},
+		{"no lines", issued.ID, `{"lines":[]}`, http.StatusBadRequest},
+		{"unknown line", issued.ID, `{"lines":[{"index":5}]}`, http.StatusBadRequest},
+		{"too much quantity", issued.ID, `{"lines":[{"index":0,"quantity":3}]}`, http.StatusBadRequest},
+		{"bad date", issued.ID, `{"lines":[{"index":0}],"date":"2026-02-30"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := s.do("POST", "/invoices/"+tt.id+"/credit-notes", tt.body)
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestCreateCreditNote_RollsBackWhenInvoiceCannotBeCredited(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createIssued(t)
+
+	s.invoices.failUpdates = true
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/credit-notes", `{"lines":[{"index":1}]}`, http.StatusInternalServerError)
+	s.invoices.failUpdates = false
+
+	// The credit note would have been inv_2.
+	s.mustDo(t, "GET", "/invoices/inv_2", "", http.StatusNotFound)
+	if _, err := s.snapshots.Get("user_1", "inv_2"); !errors.Is(err, store.ErrSnapshotNotFound) {
+		t.Errorf("expected the credit note's snapshot to be deleted, got %v", err)
+	}
+	var stored models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID, "", http.StatusOK), &stored)
+	if len(stored.CreditNotes) != 0 || stored.BalanceDue.Cmp(invoice.BalanceDue) != 0 {
+		t.Errorf("expected the invoice to be left uncredited, got %+v with balance %s", stored.CreditNotes, stored.BalanceDue)
+	}
+	var history []models.AuditEntry
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID+"/history", "", http.StatusOK), &history)
+	for _, entry := range history {
+		if entry.Action == models.AuditCredited {
+			t.Errorf("expected no credited entry for the failed credit, got %+v", entry)
+		}
+	}
+
+	// The failed attempt does not use up a credit note number.
+	var credited CreditNoteResponse
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/credit-notes", `{"lines":[{"index":1}]}`, http.StatusCreated), &credited)
+	want := numbering.DefaultScheme(numbering.ScopeCreditNote).Format(numberingDate(credited.CreditNote.InvoiceDate), 1)
+	if credited.CreditNote.InvoiceNumber != want {
+		t.Errorf("expected the first credit note number %q, got %q", want, credited.CreditNote.InvoiceNumber)
+	}
+}
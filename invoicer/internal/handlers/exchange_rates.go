@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+)
+
+// ExchangeRateHandler handles exchange rate table requests.
+type ExchangeRateHandler struct {
+	rates store.ExchangeRateStore
+}
+
+// NewExchangeRateHandler creates a new exchange rate handler.
+func NewExchangeRateHandler(rates store.ExchangeRateStore) *ExchangeRateHandler {
+	return &ExchangeRateHandler{rates: rates}
+}
+
+// ListRates handles GET /api/exchange-rates
+func (h *ExchangeRateHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.rates.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list exchange rates")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rates)
+}
+
+// PutRates handles POST /api/admin/exchange-rates. The day's rates replace
+// any already stored for the same date and base currency; invoices already
+// issued keep the rate they were stamped with.
+func (h *ExchangeRateHandler) PutRates(w http.ResponseWriter, r *http.Request) {
+	var rates models.ExchangeRates
+	if err := json.NewDecoder(r.Body).Decode(&rates); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	fx.Normalize(&rates)
+	if err := fx.Validate(&rates); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	if err := h.rates.Put(&rates); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to store exchange rates")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &rates)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/audit"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// InvoiceHistory handles GET /api/invoices/{id}/history. It returns every
+// recorded change to the invoice, oldest first, including for drafts that
+// have since been deleted.
+func (h *InvoiceHandler) InvoiceHistory(w http.ResponseWriter, r *http.Request) {
+	h.documentHistory(w, r, models.DocumentInvoice)
+}
+
+// documentHistory returns the change history of the user's document of the given kind.
+func (h *InvoiceHandler) documentHistory(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+	id := mux.Vars(r)["id"]
+
+	entries, err := h.history.List(claims.UserID, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to read history")
+		return
+	}
+
+	// Deleted documents keep their history; existing ones must be of the
+	// endpoint's kind.
+	invoice, err := h.store.Get(claims.UserID, id)
+	switch {
+	case err == nil && !ofKind(invoice, kind):
+		err = store.ErrInvoiceNotFound
+	case errors.Is(err, store.ErrInvoiceNotFound) && len(entries) > 0:
+		err = nil
+	}
+	if err != nil {
+		writeDocumentError(w, kind, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// journal returns the journal recording a change the request's user makes to
+// an invoice. The store saves its entry with the change, so the change fails
+// if its history cannot be recorded.
+func (h *InvoiceHandler) journal(r *http.Request, action string) store.Journal {
+	claims := middleware.GetClaims(r)
+	return audit.Journal(models.AuditEntry{
+		Action:     action,
+		Actor:      claims.UserID,
+		ActorEmail: claims.Email,
+		At:         time.Now().UTC(),
+	})
+}
@@ -2,18 +2,87 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"invoice-generator/invoicer/internal/auth"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/creditnotes"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/listing"
+	"invoice-generator/invoicer/internal/middleware"
 	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/payments"
 	"invoice-generator/invoicer/internal/pdf"
+	"invoice-generator/invoicer/internal/promos"
+	"invoice-generator/invoicer/internal/quotes"
+	"invoice-generator/invoicer/internal/revisions"
+	"invoice-generator/invoicer/internal/snapshot"
+	"invoice-generator/invoicer/internal/store"
+	"invoice-generator/invoicer/internal/terms"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// errManualNumber is returned when a document is saved with a number of its
+// own. Numbers only come from the user's sequences, so that every number
+// follows the pattern and none is skipped.
+var errManualNumber = errors.New("invoiceNumber is allocated by the server: invoices are numbered when issued and quotes when created")
+
+// errInvoiceLocked is returned when changing the content of a non-draft invoice.
+var errInvoiceLocked = errors.New("only draft invoices can be modified; issued invoices are immutable apart from status changes and are corrected by issuing a revision")
+
+// errCreditNoteStatus is returned when changing the status of a credit note.
+var errCreditNoteStatus = errors.New("credit notes are issued on creation and their status cannot be changed")
+
+// TotalsPolicy controls what happens to client-supplied amounts.
+type TotalsPolicy string
+
+const (
+	// TotalsOverwrite silently replaces client amounts with the computed ones.
+	TotalsOverwrite TotalsPolicy = "overwrite"
+	// TotalsReject refuses invoices whose amounts do not match the computed ones.
+	TotalsReject TotalsPolicy = "reject"
 )
 
+// ParseTotalsPolicy parses a policy name. An empty string selects TotalsOverwrite.
+func ParseTotalsPolicy(s string) (TotalsPolicy, error) {
+	switch TotalsPolicy(s) {
+	case "", TotalsOverwrite:
+		return TotalsOverwrite, nil
+	case TotalsReject:
+		return TotalsReject, nil
+	default:
+		return "", fmt.Errorf("invalid totals policy %q: must be %q or %q", s, TotalsOverwrite, TotalsReject)
+	}
+}
+
 // InvoiceHandler handles invoice-related HTTP requests
-type InvoiceHandler struct{}
+type InvoiceHandler struct {
+	store        store.InvoiceStore
+	numbers      numbering.Store
+	directory    *directory.Directory
+	promoCodes   store.PromoCodeStore
+	rates        store.ExchangeRateStore
+	history      store.AuditStore
+	snapshots    store.SnapshotStore
+	totalsPolicy TotalsPolicy
+}
 
-// NewInvoiceHandler creates a new invoice handler
-func NewInvoiceHandler() *InvoiceHandler {
-	return &InvoiceHandler{}
+// NewInvoiceHandler creates a new invoice handler backed by the given stores
+func NewInvoiceHandler(invoiceStore store.InvoiceStore, numbers numbering.Store, dir *directory.Directory, promoCodes store.PromoCodeStore, rates store.ExchangeRateStore, history store.AuditStore, snapshots store.SnapshotStore, totalsPolicy TotalsPolicy) *InvoiceHandler {
+	return &InvoiceHandler{store: invoiceStore, numbers: numbers, directory: dir, promoCodes: promoCodes, rates: rates, history: history, snapshots: snapshots, totalsPolicy: totalsPolicy}
 }
 
 // GeneratePDF handles POST /api/generate-pdf requests
@@ -32,6 +101,33 @@ func (h *InvoiceHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	invoice.Currency = currency.Normalize(invoice.Currency)
+	invoice.BaseCurrency = currency.Normalize(invoice.BaseCurrency)
+
+	// Fill client and business details from the directory
+	if err := h.directory.Apply(middleware.GetClaims(r).UserID, &invoice); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	// Take the discount from the promo code, which must still be usable
+	promo, err := h.applyPromoCode(middleware.GetClaims(r).UserID, &invoice)
+	if err == nil && promo != nil {
+		err = promos.Usable(promo, civil.Of(time.Now().UTC()))
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	// Derive the due date from the payment terms
+	terms.Apply(&invoice)
+
+	// Replace (or verify) client-supplied amounts with server-computed totals
+	if err := h.reconcileTotals(&invoice); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "totals_mismatch", err.Error())
+		return
+	}
 
 	// Validate invoice data
 	if err := validateInvoice(&invoice); err != nil {
@@ -39,6 +135,14 @@ func (h *InvoiceHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Print base-currency equivalents at today's rate unless one is supplied
+	if invoice.ShowBaseCurrency && invoice.ExchangeRate == 0 {
+		if err := fx.Stamp(&invoice, h.rates, civil.Of(time.Now().UTC())); err != nil {
+			writeInvoiceError(w, err)
+			return
+		}
+	}
+
 	// Generate PDF
 	generator := pdf.NewGenerator()
 	pdfData, err := generator.GenerateInvoice(&invoice)
@@ -57,6 +161,280 @@ func (h *InvoiceHandler) GeneratePDF(w http.ResponseWriter, r *http.Request) {
 	w.Write(pdfData)
 }
 
+// CreateInvoice handles POST /api/invoices. When invoiceNumber is omitted the
+// server allocates the next number from the user's sequence.
+func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	h.createDocument(w, r, models.DocumentInvoice)
+}
+
+// createDocument creates an invoice or quote from the request body, numbered
+// from the document type's sequence.
+func (h *InvoiceHandler) createDocument(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	var invoice models.Invoice
+	if err := json.NewDecoder(r.Body).Decode(&invoice); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if invoice.InvoiceNumber != "" {
+		writeInvoiceError(w, errManualNumber)
+		return
+	}
+	resetServerManaged(&invoice, kind)
+	invoice.Currency = currency.Normalize(invoice.Currency)
+	invoice.BaseCurrency = currency.Normalize(invoice.BaseCurrency)
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = civil.Of(time.Now().UTC())
+	}
+
+	if err := h.directory.Apply(claims.UserID, &invoice); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	promo, err := h.applyPromoCode(claims.UserID, &invoice)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	terms.Apply(&invoice)
+
+	if err := h.reconcileTotals(&invoice); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "totals_mismatch", err.Error())
+		return
+	}
+
+	if err := validateInvoiceContent(&invoice); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	if promo != nil {
+		if err := promos.Usable(promo, civil.Of(time.Now().UTC())); err != nil {
+			writeInvoiceError(w, err)
+			return
+		}
+		if kind != models.DocumentQuote {
+			invoice.RedeemedPromoCodes = []string{promo.Code}
+		}
+	}
+
+	lifecycle.Init(&invoice, time.Now().UTC())
+
+	// The store counts the promo code in the same write as the document, and
+	// a code used up in the meantime fails it.
+	var created *models.Invoice
+	create := func() error {
+		var err error
+		created, err = h.store.Create(claims.UserID, &invoice, h.journal(r, models.AuditCreated))
+		return err
+	}
+	// Invoices stay unnumbered until they are issued, so that deleting a
+	// draft leaves no gap in the sequence. Quotes are numbered now.
+	if kind == models.DocumentQuote {
+		_, err = h.numbers.Allocate(claims.UserID, numbering.ScopeQuote, numberingDate(invoice.InvoiceDate), func(number string) error {
+			invoice.InvoiceNumber = number
+			err := create()
+			if errors.Is(err, store.ErrDuplicateNumber) {
+				return numbering.ErrNumberTaken
+			}
+			return err
+		})
+	} else {
+		err = create()
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListInvoices handles GET /api/invoices. Invoices and credit notes are
+// listed; quotes are listed at /api/quotes. Query parameters filter, sort and
+// page the list (see listing.ParseQuery).
+func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	h.listDocuments(w, r, models.DocumentInvoice)
+}
+
+// listDocuments lists one page of the user's documents of the given kind. The
+// number of matching documents is returned in X-Total-Count and the cursor of
+// the next page, if any, in X-Next-Cursor.
+func (h *InvoiceHandler) listDocuments(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	query, err := listing.ParseQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	all, err := h.store.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list invoices")
+		return
+	}
+
+	invoices := make([]*models.Invoice, 0, len(all))
+	for _, invoice := range all {
+		if ofKind(invoice, kind) {
+			invoices = append(invoices, invoice)
+		}
+	}
+
+	page, err := listing.Apply(invoices, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	writeJSON(w, http.StatusOK, page.Invoices)
+}
+
+// GetInvoice handles GET /api/invoices/{id}
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	h.getDocument(w, r, models.DocumentInvoice)
+}
+
+// getDocument returns the user's document of the given kind.
+func (h *InvoiceHandler) getDocument(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	invoice, err := h.store.Get(claims.UserID, mux.Vars(r)["id"])
+	if err == nil && !ofKind(invoice, kind) {
+		err = store.ErrInvoiceNotFound
+	}
+	if err != nil {
+		writeDocumentError(w, kind, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, invoice)
+}
+
+// UpdateInvoice handles PUT /api/invoices/{id}. The request body replaces the
+// stored invoice content; server-managed fields are preserved, as is the
+// invoice number when the body omits it.
+func (h *InvoiceHandler) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
+	h.updateDocument(w, r, models.DocumentInvoice)
+}
+
+// updateDocument replaces the content of the user's draft document of the given kind.
+func (h *InvoiceHandler) updateDocument(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	var invoice models.Invoice
+	if err := json.NewDecoder(r.Body).Decode(&invoice); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	resetServerManaged(&invoice, kind)
+	invoice.Currency = currency.Normalize(invoice.Currency)
+	invoice.BaseCurrency = currency.Normalize(invoice.BaseCurrency)
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = civil.Of(time.Now().UTC())
+	}
+
+	if err := h.directory.Apply(claims.UserID, &invoice); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	promo, err := h.applyPromoCode(claims.UserID, &invoice)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	terms.Apply(&invoice)
+
+	if err := h.reconcileTotals(&invoice); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "totals_mismatch", err.Error())
+		return
+	}
+
+	if err := validateInvoiceContent(&invoice); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	updated, err := h.store.Update(claims.UserID, mux.Vars(r)["id"], func(existing *models.Invoice) error {
+		if !ofKind(existing, kind) {
+			return store.ErrInvoiceNotFound
+		}
+		if !lifecycle.IsEditable(existing) {
+			return errInvoiceLocked
+		}
+		if invoice.InvoiceNumber != "" && invoice.InvoiceNumber != existing.InvoiceNumber {
+			return errManualNumber
+		}
+
+		// A promo code is used once per document, when it is first applied;
+		// switching away from it and back neither checks nor uses it again.
+		// The store counts a newly used code as it saves the document.
+		usedCodes := existing.RedeemedPromoCodes
+		if promo != nil && existing.PromoCode != promo.Code && !slices.Contains(usedCodes, promo.Code) {
+			if err := promos.Usable(promo, civil.Of(time.Now().UTC())); err != nil {
+				return err
+			}
+		}
+		if promo != nil && kind != models.DocumentQuote && !slices.Contains(usedCodes, promo.Code) {
+			usedCodes = append(slices.Clone(usedCodes), promo.Code)
+		}
+
+		number, status, history := existing.InvoiceNumber, existing.Status, existing.StatusHistory
+		recurringID, period := existing.RecurringID, existing.RecurringPeriod
+		quoteID, quoteNumber := existing.QuoteID, existing.QuoteNumber
+		*existing = *invoice.Clone()
+		existing.Status, existing.StatusHistory = status, history
+		existing.RecurringID, existing.RecurringPeriod = recurringID, period
+		existing.QuoteID, existing.QuoteNumber = quoteID, quoteNumber
+		existing.RedeemedPromoCodes = usedCodes
+		existing.InvoiceNumber = number
+		return nil
+	}, h.journal(r, models.AuditUpdated))
+	if err != nil {
+		writeDocumentError(w, kind, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteInvoice handles DELETE /api/invoices/{id}. Only drafts can be deleted;
+// issued invoices must be voided instead.
+func (h *InvoiceHandler) DeleteInvoice(w http.ResponseWriter, r *http.Request) {
+	h.deleteDocument(w, r, models.DocumentInvoice)
+}
+
+// deleteDocument deletes the user's draft document of the given kind.
+func (h *InvoiceHandler) deleteDocument(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	err := h.store.Delete(claims.UserID, mux.Vars(r)["id"], func(existing *models.Invoice) error {
+		if !ofKind(existing, kind) {
+			return store.ErrInvoiceNotFound
+		}
+		if !lifecycle.IsEditable(existing) {
+			return errInvoiceLocked
+		}
+		return nil
+	}, h.journal(r, models.AuditDeleted))
+	if err != nil {
+		writeDocumentError(w, kind, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // HealthCheck handles GET /health requests
 func (h *InvoiceHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -67,11 +445,55 @@ func (h *InvoiceHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// reconcileTotals makes the server-computed totals authoritative. Under
+// TotalsReject it first fails if the client's amounts disagree with them.
+func (h *InvoiceHandler) reconcileTotals(invoice *models.Invoice) error {
+	if h.totalsPolicy == TotalsReject {
+		if err := calc.Verify(invoice); err != nil {
+			return err
+		}
+	}
+	calc.Apply(invoice)
+	return nil
+}
+
+// applyPromoCode looks up the invoice's promo code and replaces the invoice's
+// discount with the code's. It returns the promo code, or nil if the invoice
+// has none.
+func (h *InvoiceHandler) applyPromoCode(userID string, invoice *models.Invoice) (*models.PromoCode, error) {
+	invoice.PromoCode = promos.Normalize(invoice.PromoCode)
+	if invoice.PromoCode == "" {
+		return nil, nil
+	}
+	promo, err := h.promoCodes.GetByCode(userID, invoice.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := promos.Apply(invoice, promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// Line quantities and unit rates are bounded so that a single line always
+// fits in the range of Money; totals of many lines are still checked for
+// overflow.
+const (
+	maxQuantity = 1e6
+	maxRate     = 1e8 // in major units
+)
+
 // validateInvoice performs basic validation on invoice data
 func validateInvoice(invoice *models.Invoice) error {
 	if invoice.InvoiceNumber == "" {
 		return fmt.Errorf("invoice number is required")
 	}
+	return validateInvoiceContent(invoice)
+}
+
+// validateInvoiceContent validates everything except the invoice number, which
+// the server may still allocate.
+func validateInvoiceContent(invoice *models.Invoice) error {
 	if invoice.BusinessName == "" {
 		return fmt.Errorf("business name is required")
 	}
@@ -81,8 +503,297 @@ func validateInvoice(invoice *models.Invoice) error {
 	if len(invoice.Items) == 0 {
 		return fmt.Errorf("at least one item is required")
 	}
-	if invoice.Total <= 0 {
+	if err := validateCurrency(invoice.Currency, true); err != nil {
+		return err
+	}
+	if err := validateCurrency(invoice.BaseCurrency, false); err != nil {
+		return fmt.Errorf("baseCurrency: %v", err)
+	}
+	if invoice.ExchangeRate < 0 {
+		return fmt.Errorf("exchangeRate must not be negative")
+	}
+	if invoice.ShowBaseCurrency && invoice.BaseCurrency == "" {
+		return fmt.Errorf("showBaseCurrency requires a baseCurrency")
+	}
+	if err := validateTaxes("", invoice.Taxes, invoice.TaxRate); err != nil {
+		return err
+	}
+	if err := validateDiscount("", invoice.Discount, invoice.DiscountRate); err != nil {
+		return err
+	}
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+		prefix := fmt.Sprintf("items[%d].", i)
+		if math.Abs(item.Quantity) > maxQuantity {
+			return fmt.Errorf("%squantity must be at most %.0f in absolute value", prefix, float64(maxQuantity))
+		}
+		if item.Rate.Abs().Cmp(money.New(maxRate*100, "")) > 0 {
+			return fmt.Errorf("%srate must be at most %.0f in absolute value", prefix, float64(maxRate))
+		}
+		if err := validateTaxes(prefix, item.Taxes, item.TaxRate); err != nil {
+			return err
+		}
+		if err := validateDiscount(prefix, item.Discount, item.DiscountRate); err != nil {
+			return err
+		}
+		if len(item.DiscountTiers) > 0 && (item.Discount != nil || item.DiscountRate != 0) {
+			return fmt.Errorf("%sdiscountTiers cannot be combined with another discount", prefix)
+		}
+		if err := validateTiers(prefix, item.DiscountTiers); err != nil {
+			return err
+		}
+	}
+	if invoice.PaymentTerms != "" {
+		if _, err := terms.Parse(invoice.PaymentTerms); err != nil {
+			return err
+		}
+	}
+	if !invoice.DueDate.IsZero() && invoice.DueDate.Before(invoice.InvoiceDate) {
+		return fmt.Errorf("dueDate must not be before invoiceDate")
+	}
+	if invoice.IsQuote() {
+		if err := quotes.Validate(invoice); err != nil {
+			return err
+		}
+	}
+	if invoice.Total.Overflowed() || invoice.BalanceDue.Overflowed() {
+		return fmt.Errorf("amounts are too large to be invoiced")
+	}
+	if invoice.IsCreditNote() {
+		if !invoice.Total.IsNegative() {
+			return fmt.Errorf("credit note total must be less than zero")
+		}
+	} else if !invoice.Total.IsPositive() {
 		return fmt.Errorf("total must be greater than zero")
 	}
 	return nil
 }
+
+// validateCurrency checks that code is a known ISO 4217 currency code. An
+// empty code is accepted unless required is set.
+func validateCurrency(code string, required bool) error {
+	if code == "" {
+		if required {
+			return fmt.Errorf("currency is required")
+		}
+		return nil
+	}
+	if !currency.Valid(code) {
+		return fmt.Errorf("currency %q is not an ISO 4217 currency code", code)
+	}
+	return nil
+}
+
+// validateTaxes checks a tax rate and list of named taxes; prefix locates
+// them in the request, e.g. "items[0].".
+func validateTaxes(prefix string, taxes []models.Tax, rate float64) error {
+	if rate < 0 || rate > 100 {
+		return fmt.Errorf("%staxRate must be between 0 and 100", prefix)
+	}
+	if len(taxes) > 0 && rate != 0 {
+		return fmt.Errorf("%staxRate and %staxes cannot both be set", prefix, prefix)
+	}
+
+	seen := make(map[string]bool, len(taxes))
+	for i, tax := range taxes {
+		name := strings.ToLower(strings.TrimSpace(tax.Name))
+		if name == "" {
+			return fmt.Errorf("%staxes[%d].name is required", prefix, i)
+		}
+		if tax.Rate < 0 || tax.Rate > 100 {
+			return fmt.Errorf("%staxes[%d].rate must be between 0 and 100", prefix, i)
+		}
+		if seen[name] {
+			return fmt.Errorf("%staxes[%d]: %q is listed more than once", prefix, i, tax.Name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateDiscount checks a discount rate and discount; prefix locates them
+// in the request, e.g. "items[0].".
+func validateDiscount(prefix string, d *models.Discount, rate float64) error {
+	if rate < 0 || rate > 100 {
+		return fmt.Errorf("%sdiscountRate must be between 0 and 100", prefix)
+	}
+	if d == nil {
+		return nil
+	}
+	if rate != 0 {
+		return fmt.Errorf("%sdiscountRate and %sdiscount cannot both be set", prefix, prefix)
+	}
+	return validateDiscountTerms(prefix+"discount.", d)
+}
+
+// validateDiscountTerms checks a discount's kind and the rate or amount it uses.
+func validateDiscountTerms(prefix string, d *models.Discount) error {
+	switch d.Kind {
+	case models.DiscountPercent:
+		if d.Rate <= 0 || d.Rate > 100 {
+			return fmt.Errorf("%srate must be greater than 0 and at most 100", prefix)
+		}
+		if !d.Amount.IsZero() {
+			return fmt.Errorf("%samount is only used by %q discounts", prefix, models.DiscountFixed)
+		}
+	case models.DiscountFixed:
+		if !d.Amount.IsPositive() {
+			return fmt.Errorf("%samount must be greater than zero", prefix)
+		}
+		if d.Rate != 0 {
+			return fmt.Errorf("%srate is only used by %q discounts", prefix, models.DiscountPercent)
+		}
+	default:
+		return fmt.Errorf("%skind must be %q or %q", prefix, models.DiscountPercent, models.DiscountFixed)
+	}
+	return nil
+}
+
+// validateTiers checks volume discount tiers; prefix locates them in the
+// request, e.g. "items[0].".
+func validateTiers(prefix string, tiers []models.DiscountTier) error {
+	seen := make(map[float64]bool, len(tiers))
+	for i, tier := range tiers {
+		if tier.MinQuantity <= 0 {
+			return fmt.Errorf("%sdiscountTiers[%d].minQuantity must be greater than zero", prefix, i)
+		}
+		if tier.Rate <= 0 || tier.Rate > 100 {
+			return fmt.Errorf("%sdiscountTiers[%d].rate must be greater than 0 and at most 100", prefix, i)
+		}
+		if seen[tier.MinQuantity] {
+			return fmt.Errorf("%sdiscountTiers[%d]: minQuantity %g is listed more than once", prefix, i, tier.MinQuantity)
+		}
+		seen[tier.MinQuantity] = true
+	}
+	return nil
+}
+
+// resetServerManaged clears fields that only the server may set on stored
+// invoices and quotes: payments and credit notes are recorded through their
+// own endpoints, credit notes are only created from an existing invoice,
+// quote links are set on conversion, recurring links are set by the
+// scheduler, late fees are added by the overdue job, the exchange rate is
+// stamped and the hashes are recorded on issue, and revision links are set
+// when an invoice is revised. The document type is set from the endpoint.
+func resetServerManaged(invoice *models.Invoice, kind models.DocumentType) {
+	invoice.DocumentType = kind
+	if kind != models.DocumentQuote {
+		invoice.ValidUntil = civil.Date{}
+	}
+	invoice.ConvertedInvoiceID = ""
+	invoice.QuoteID = ""
+	invoice.QuoteNumber = ""
+	invoice.OriginalInvoiceID = ""
+	invoice.OriginalInvoiceNumber = ""
+	invoice.CreditedLines = nil
+	invoice.RedeemedPromoCodes = nil
+	invoice.RecurringID = ""
+	invoice.RecurringPeriod = ""
+	invoice.Payments = nil
+	invoice.CreditNotes = nil
+	invoice.LateFees = nil
+	invoice.ExchangeRate = 0
+	invoice.ExchangeRateDate = civil.Date{}
+	invoice.DocumentSHA256 = ""
+	invoice.PDFSHA256 = ""
+	invoice.Revision = 0
+	invoice.PreviousRevisionID = ""
+	invoice.PreviousRevisionNumber = ""
+	invoice.SupersededByID = ""
+	invoice.SupersededByNumber = ""
+}
+
+// writeInvoiceError maps store, lifecycle, payment, credit note, revision,
+// snapshot and exchange rate errors to HTTP responses.
+func writeInvoiceError(w http.ResponseWriter, err error) {
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.Is(err, store.ErrInvoiceNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Invoice not found")
+	case errors.Is(err, store.ErrClientNotFound):
+		writeError(w, http.StatusBadRequest, "validation_error", "clientId does not match a saved client")
+	case errors.Is(err, store.ErrBusinessProfileNotFound):
+		writeError(w, http.StatusBadRequest, "validation_error", "businessProfileId does not match a saved business profile")
+	case errors.Is(err, store.ErrCatalogItemNotFound), errors.Is(err, directory.ErrCurrencyMismatch):
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, store.ErrPromoCodeNotFound):
+		writeError(w, http.StatusBadRequest, "validation_error", "promoCode does not match a saved promo code")
+	case errors.Is(err, promos.ErrExpired), errors.Is(err, promos.ErrUsedUp), errors.Is(err, promos.ErrCurrencyMismatch):
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, errManualNumber):
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, store.ErrDuplicateNumber):
+		writeError(w, http.StatusConflict, "conflict", "Invoice number already in use")
+	case errors.Is(err, errInvoiceLocked):
+		writeError(w, http.StatusConflict, "invoice_locked", err.Error())
+	case errors.As(err, &transitionErr):
+		writeError(w, http.StatusConflict, "invalid_transition", err.Error())
+	case errors.Is(err, payments.ErrNotPayable):
+		writeError(w, http.StatusConflict, "not_payable", err.Error())
+	case errors.Is(err, payments.ErrOverpayment):
+		writeError(w, http.StatusUnprocessableEntity, "overpayment", err.Error())
+	case errors.Is(err, payments.ErrInvalidPayment):
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, creditnotes.ErrNotCreditable):
+		writeError(w, http.StatusConflict, "not_creditable", err.Error())
+	case errors.Is(err, creditnotes.ErrExceedsBalance):
+		writeError(w, http.StatusUnprocessableEntity, "exceeds_balance", err.Error())
+	case errors.Is(err, creditnotes.ErrInvalidCredit):
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, errCreditNoteStatus):
+		writeError(w, http.StatusConflict, "invalid_transition", err.Error())
+	case errors.Is(err, quotes.ErrExpired):
+		writeError(w, http.StatusConflict, "quote_expired", err.Error())
+	case errors.Is(err, quotes.ErrNotAccepted), errors.Is(err, quotes.ErrAlreadyConverted):
+		writeError(w, http.StatusConflict, "not_convertible", err.Error())
+	case errors.Is(err, fx.ErrNoRate):
+		writeError(w, http.StatusUnprocessableEntity, "no_exchange_rate", err.Error())
+	case errors.Is(err, revisions.ErrNotRevisable):
+		writeError(w, http.StatusConflict, "not_revisable", err.Error())
+	case errors.Is(err, store.ErrSnapshotNotFound):
+		writeError(w, http.StatusNotFound, "not_frozen", "Invoice has not been issued")
+	case errors.Is(err, snapshot.ErrTampered):
+		writeError(w, http.StatusInternalServerError, "integrity_error", "Frozen invoice does not match its hash")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access invoice")
+	}
+}
+
+// writeDocumentError is writeInvoiceError for endpoints shared by invoices and
+// quotes, naming the document kind when it is not found.
+func writeDocumentError(w http.ResponseWriter, kind models.DocumentType, err error) {
+	if kind == models.DocumentQuote && errors.Is(err, store.ErrInvoiceNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "Quote not found")
+		return
+	}
+	writeInvoiceError(w, err)
+}
+
+// ofKind reports whether a stored document is served by the endpoints for
+// kind: quotes under /api/quotes, invoices and credit notes under /api/invoices.
+func ofKind(invoice *models.Invoice, kind models.DocumentType) bool {
+	return invoice.IsQuote() == (kind == models.DocumentQuote)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, auth.ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// writeDecodeError reports a request body that could not be decoded. Malformed
+// dates are validation errors; anything else is invalid JSON.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, civil.ErrInvalid) {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"invoice-generator/invoicer/internal/auth"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// testServer routes the invoice, quote and promo code endpoints to handlers
+// on memory stores.
+type testServer struct {
+	router     *mux.Router
+	handler    *InvoiceHandler
+	invoices   *failingInvoiceStore
+	snapshots  store.SnapshotStore
+	promoCodes store.PromoCodeStore
+}
+
+// failingInvoiceStore is an InvoiceStore whose updates can be made to fail
+// after the callback has run, as if the write itself was lost.
+type failingInvoiceStore struct {
+	store.InvoiceStore
+	failUpdates bool
+}
+
+func (s *failingInvoiceStore) Update(userID, id string, fn func(invoice *models.Invoice) error, journal store.Journal) (*models.Invoice, error) {
+	return s.InvoiceStore.Update(userID, id, func(invoice *models.Invoice) error {
+		if err := fn(invoice); err != nil {
+			return err
+		}
+		if s.failUpdates {
+			return errors.New("store unavailable")
+		}
+		return nil
+	}, journal)
+}
+
+func newTestServer() *testServer {
+	dir := directory.New(store.NewMemoryClientStore(), store.NewMemoryBusinessProfileStore(), store.NewMemoryCatalogStore())
+	history := store.NewMemoryAuditStore()
+	promoCodes := store.NewMemoryPromoCodeStore()
+	invoices := &failingInvoiceStore{InvoiceStore: store.NewMemoryInvoiceStore(history, promoCodes)}
+	snapshots := store.NewMemorySnapshotStore()
+	h := NewInvoiceHandler(invoices, numbering.NewMemoryStore(), dir, promoCodes, store.NewMemoryExchangeRateStore(), history, snapshots, TotalsOverwrite)
+	pc := NewPromoCodeHandler(promoCodes)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/generate-pdf", h.GeneratePDF).Methods("POST")
+	r.HandleFunc("/invoices", h.ListInvoices).Methods("GET")
+	r.HandleFunc("/invoices", h.CreateInvoice).Methods("POST")
+	r.HandleFunc("/invoices/{id}", h.GetInvoice).Methods("GET")
+	r.HandleFunc("/invoices/{id}", h.UpdateInvoice).Methods("PUT")
+	r.HandleFunc("/invoices/{id}", h.DeleteInvoice).Methods("DELETE")
+	r.HandleFunc("/invoices/{id}/status", h.ChangeInvoiceStatus).Methods("POST")
+	r.HandleFunc("/invoices/{id}/history", h.InvoiceHistory).Methods("GET")
+	r.HandleFunc("/invoices/{id}/pdf", h.InvoicePDF).Methods("GET")
+	r.HandleFunc("/invoices/{id}/revisions", h.ReviseInvoice).Methods("POST")
+	r.HandleFunc("/invoices/{id}/credit-notes", h.CreateCreditNote).Methods("POST")
+	r.HandleFunc("/quotes", h.CreateQuote).Methods("POST")
+	r.HandleFunc("/quotes/{id}", h.GetQuote).Methods("GET")
+	r.HandleFunc("/quotes/{id}/status", h.ChangeQuoteStatus).Methods("POST")
+	r.HandleFunc("/quotes/{id}/convert", h.ConvertQuote).Methods("POST")
+	r.HandleFunc("/promo-codes", pc.CreateCode).Methods("POST")
+	r.HandleFunc("/promo-codes/{id}", pc.GetCode).Methods("GET")
+
+	return &testServer{router: r, handler: h, invoices: invoices, snapshots: snapshots, promoCodes: promoCodes}
+}
+
+// do sends a request as user_1 and returns the recorded response.
+func (s *testServer) do(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserClaimsKey, &auth.Claims{UserID: "user_1", Email: "user@example.com"}))
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	return rr
+}
+
+// mustDo is do for requests that are expected to return the given status.
+func (s *testServer) mustDo(t *testing.T, method, path, body string, status int) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := s.do(method, path, body)
+	if rr.Code != status {
+		t.Fatalf("%s %s: expected %d, got %d: %s", method, path, status, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+// decode unmarshals a response body into v.
+func decode(t *testing.T, rr *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rr.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rr.Body.String(), err)
+	}
+}
+
+// errorCode returns the error code of an error response.
+func errorCode(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error string `json:"error"`
+	}
+	decode(t, rr, &body)
+	return body.Error
+}
+
+const draftInvoice = `{"businessName":"Acme","clientName":"Globex","currency":"USD","dueDate":"2026-12-01",
+	"items":[{"description":"Design","quantity":2,"rate":100,"amount":200},{"description":"Hosting","quantity":1,"rate":50,"amount":50}],
+	"subtotal":250,"total":250}`
+
+// createDraft creates draftInvoice and returns it.
+func (s *testServer) createDraft(t *testing.T) *models.Invoice {
+	t.Helper()
+	var invoice models.Invoice
+	decode(t, s.mustDo(t, "POST", "/invoices", draftInvoice, http.StatusCreated), &invoice)
+	return &invoice
+}
+
+func TestCreateInvoice(t *testing.T) {
+	s := newTestServer()
+
+	// Client-sent amounts are replaced by the computed ones.
+	body := strings.Replace(draftInvoice, `"subtotal":250,"total":250`, `"subtotal":1,"total":1`, 1)
+	var invoice models.Invoice
+	decode(t, s.mustDo(t, "POST", "/invoices", body, http.StatusCreated), &invoice)
+
+	if invoice.ID == "" || invoice.UserID != "user_1" {
+		t.Errorf("expected an ID and the owner, got %q and %q", invoice.ID, invoice.UserID)
+	}
+	if invoice.Subtotal.String() != "250.00" || invoice.Total.String() != "250.00" {
+		t.Errorf("expected the computed subtotal and total 250.00, got %s and %s", invoice.Subtotal, invoice.Total)
+	}
+	var stored models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID, "", http.StatusOK), &stored)
+	if stored.InvoiceNumber != invoice.InvoiceNumber || len(stored.Items) != 2 {
+		t.Errorf("expected the invoice to be stored, got %+v", stored)
+	}
+
+	var history []models.AuditEntry
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID+"/history", "", http.StatusOK), &history)
+	if len(history) != 1 || history[0].Action != models.AuditCreated || history[0].Actor != "user_1" {
+		t.Errorf("expected one created entry by user_1, got %+v", history)
+	}
+}
+
+func TestIssueInvoice_AllocatesNumbers(t *testing.T) {
+	s := newTestServer()
+
+	// Drafts are unnumbered, so deleting one leaves no gap.
+	deleted := s.createDraft(t)
+	if deleted.InvoiceNumber != "" {
+		t.Errorf("expected an unnumbered draft, got %q", deleted.InvoiceNumber)
+	}
+	s.mustDo(t, "DELETE", "/invoices/"+deleted.ID, "", http.StatusNoContent)
+
+	first, second := s.createIssued(t), s.createIssued(t)
+	scheme := numbering.DefaultScheme(numbering.ScopeInvoice)
+	date := numberingDate(first.InvoiceDate)
+	if first.InvoiceNumber != scheme.Format(date, 1) || second.InvoiceNumber != scheme.Format(date, 2) {
+		t.Errorf("expected consecutive numbers from the default scheme, got %q and %q", first.InvoiceNumber, second.InvoiceNumber)
+	}
+}
+
+func TestCreateInvoice_RejectsManualNumbers(t *testing.T) {
+	s := newTestServer()
+
+	manual := strings.Replace(draftInvoice, `{`, `{"invoiceNumber":"MANUAL-1",`, 1)
+	if code := errorCode(t, s.mustDo(t, "POST", "/invoices", manual, http.StatusBadRequest)); code != "validation_error" {
+		t.Errorf("expected validation_error for a manual number, got %q", code)
+	}
+
+	// Nor can a number be set or changed afterwards.
+	invoice := s.createDraft(t)
+	s.mustDo(t, "PUT", "/invoices/"+invoice.ID, manual, http.StatusBadRequest)
+	invoice = s.createIssued(t)
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/revisions", manual, http.StatusBadRequest)
+}
+
+func TestCreateInvoice_Invalid(t *testing.T) {
+	s := newTestServer()
+	s.mustDo(t, "POST", "/promo-codes", `{"code":"EUROFF","discount":{"kind":"fixed","amount":10},"currency":"EUR"}`, http.StatusCreated)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"malformed JSON", `{"items":`, http.StatusBadRequest},
+		{"no items", `{"businessName":"Acme","clientName":"Globex","currency":"USD","items":[],"total":1}`, http.StatusBadRequest},
+		{"unknown currency", strings.Replace(draftInvoice, `"USD"`, `"XYZ"`, 1), http.StatusBadRequest},
+		{"no client", strings.Replace(draftInvoice, `"Globex"`, `""`, 1), http.StatusBadRequest},
+		{"bad due date", strings.Replace(draftInvoice, `"2026-12-01"`, `"2026-13-01"`, 1), http.StatusBadRequest},
+		{"unknown promo code", strings.Replace(draftInvoice, `"currency":"USD"`, `"currency":"USD","promoCode":"NOPE"`, 1), http.StatusBadRequest},
+		{"promo code in another currency", strings.Replace(draftInvoice, `"currency":"USD"`, `"currency":"USD","promoCode":"EUROFF"`, 1), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := s.do("POST", "/invoices", tt.body)
+		if rr.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.code, rr.Code, rr.Body.String())
+		}
+	}
+
+	var invoices []models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices", "", http.StatusOK), &invoices)
+	if len(invoices) != 0 {
+		t.Errorf("expected no invoices to be saved, got %d", len(invoices))
+	}
+	code, _ := s.promoCodes.GetByCode("user_1", "EUROFF")
+	if code.Uses != 0 {
+		t.Errorf("expected a rejected promo code not to be counted, got %d uses", code.Uses)
+	}
+}
+
+func TestCreateInvoice_RejectsMismatchedTotals(t *testing.T) {
+	s := newTestServer()
+	s.handler.totalsPolicy = TotalsReject
+
+	body := strings.Replace(draftInvoice, `"total":250`, `"total":1`, 1)
+	if code := errorCode(t, s.mustDo(t, "POST", "/invoices", body, http.StatusUnprocessableEntity)); code != "totals_mismatch" {
+		t.Errorf("expected totals_mismatch, got %q", code)
+	}
+	s.mustDo(t, "POST", "/invoices", draftInvoice, http.StatusCreated)
+}
+
+func TestCreateInvoice_CountsPromoCodeOnce(t *testing.T) {
+	s := newTestServer()
+	s.mustDo(t, "POST", "/promo-codes", `{"code":"TENPC","discount":{"kind":"percent","rate":10}}`, http.StatusCreated)
+
+	withCode := strings.Replace(draftInvoice, `"currency":"USD"`, `"currency":"USD","promoCode":"TENPC"`, 1)
+	var invoice models.Invoice
+	decode(t, s.mustDo(t, "POST", "/invoices", withCode, http.StatusCreated), &invoice)
+	if got := invoice.Total.String(); got != "225.00" {
+		t.Errorf("expected the discounted total 225.00, got %s", got)
+	}
+
+	// Saving the invoice again with the same code, or switching to another
+	// code and back, does not count it again.
+	s.mustDo(t, "POST", "/promo-codes", `{"code":"FIVEPC","discount":{"kind":"percent","rate":5}}`, http.StatusCreated)
+	s.mustDo(t, "PUT", "/invoices/"+invoice.ID, withCode, http.StatusOK)
+	s.mustDo(t, "PUT", "/invoices/"+invoice.ID, strings.Replace(withCode, "TENPC", "FIVEPC", 1), http.StatusOK)
+	s.mustDo(t, "PUT", "/invoices/"+invoice.ID, withCode, http.StatusOK)
+
+	code, _ := s.promoCodes.GetByCode("user_1", "TENPC")
+	if code.Uses != 1 {
+		t.Errorf("expected the code to be counted once, got %d uses", code.Uses)
+	}
+}
+
+func TestGeneratePDF(t *testing.T) {
+	s := newTestServer()
+	body := strings.Replace(draftInvoice, `"total":250`, `"invoiceNumber":"INV-001","total":1`, 1)
+
+	rr := s.mustDo(t, "POST", "/generate-pdf", body, http.StatusOK)
+	if ct := rr.Header().Get("Content-Type"); ct != "application/pdf" || !strings.HasPrefix(rr.Body.String(), "%PDF") {
+		t.Errorf("expected a PDF, got %q", ct)
+	}
+
+	s.handler.totalsPolicy = TotalsReject
+	s.mustDo(t, "POST", "/generate-pdf", body, http.StatusUnprocessableEntity)
+}
+
+func TestUpdateInvoice(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createDraft(t)
+
+	var updated models.Invoice
+	body := strings.Replace(draftInvoice, `"Globex"`, `"Initech"`, 1)
+	decode(t, s.mustDo(t, "PUT", "/invoices/"+invoice.ID, body, http.StatusOK), &updated)
+	if updated.ClientName != "Initech" || updated.ID != invoice.ID {
+		t.Errorf("expected the client to change on %s, got %q on %s", invoice.ID, updated.ClientName, updated.ID)
+	}
+
+	s.mustDo(t, "PUT", "/invoices/inv_404", body, http.StatusNotFound)
+	if code := errorCode(t, s.mustDo(t, "PUT", "/invoices/"+invoice.ID, `{"items":[]}`, http.StatusBadRequest)); code != "validation_error" {
+		t.Errorf("expected validation_error, got %q", code)
+	}
+
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusOK)
+	if code := errorCode(t, s.mustDo(t, "PUT", "/invoices/"+invoice.ID, body, http.StatusConflict)); code != "invoice_locked" {
+		t.Errorf("expected invoice_locked after issuing, got %q", code)
+	}
+}
+
+func TestDeleteInvoice(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createDraft(t)
+
+	s.mustDo(t, "DELETE", "/invoices/"+invoice.ID, "", http.StatusNoContent)
+	s.mustDo(t, "GET", "/invoices/"+invoice.ID, "", http.StatusNotFound)
+	s.mustDo(t, "DELETE", "/invoices/"+invoice.ID, "", http.StatusNotFound)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/numbering"
+	"net/http"
+	"time"
+)
+
+// NextNumberResponse is returned by GET /api/invoices/next-number.
+type NextNumberResponse struct {
+	InvoiceNumber string           `json:"invoiceNumber"`
+	Scheme        numbering.Scheme `json:"scheme"`
+}
+
+// NextInvoiceNumber handles GET /api/invoices/next-number. It previews the number
+// the next created invoice would receive; the optional ?date=YYYY-MM-DD query
+// selects the period for patterns that reset yearly or monthly, and ?scope=
+// selects the sequence (invoice by default).
+func (h *InvoiceHandler) NextInvoiceNumber(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	scope, ok := numberingScope(w, r)
+	if !ok {
+		return
+	}
+
+	scheme, err := h.numbers.Scheme(claims.UserID, scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to load numbering scheme")
+		return
+	}
+
+	var date civil.Date
+	if s := r.URL.Query().Get("date"); s != "" {
+		if date, err = civil.Parse(s); err != nil {
+			writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+			return
+		}
+	}
+
+	number, err := h.numbers.Peek(claims.UserID, scope, numberingDate(date))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to preview invoice number")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NextNumberResponse{InvoiceNumber: number, Scheme: scheme})
+}
+
+// GetNumberingScheme handles GET /api/settings/numbering
+func (h *InvoiceHandler) GetNumberingScheme(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	scope, ok := numberingScope(w, r)
+	if !ok {
+		return
+	}
+
+	scheme, err := h.numbers.Scheme(claims.UserID, scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to load numbering scheme")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, scheme)
+}
+
+// UpdateNumberingScheme handles PUT /api/settings/numbering
+func (h *InvoiceHandler) UpdateNumberingScheme(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	scope, ok := numberingScope(w, r)
+	if !ok {
+		return
+	}
+
+	var scheme numbering.Scheme
+	if err := json.NewDecoder(r.Body).Decode(&scheme); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	if scheme.Reset == "" {
+		scheme.Reset = numbering.ResetYearly
+	}
+
+	if err := h.numbers.SetScheme(claims.UserID, scope, scheme); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, scheme)
+}
+
+// numberingScope reads the optional ?scope= query parameter, writing a 400
+// response and returning false if it names an unknown sequence.
+func numberingScope(w http.ResponseWriter, r *http.Request) (string, bool) {
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		return numbering.ScopeInvoice, true
+	}
+	if !numbering.IsKnownScope(scope) {
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Unknown numbering scope %q", scope))
+		return "", false
+	}
+	return scope, true
+}
+
+// numberingDate returns the date used for sequence allocation, falling back
+// to today when the document has no date.
+func numberingDate(d civil.Date) time.Time {
+	if d.IsZero() {
+		return time.Now().UTC()
+	}
+	return d.Time()
+}
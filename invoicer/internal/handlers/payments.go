@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/payments"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// PaymentRequest is the body for POST /api/invoices/{id}/payments.
+type PaymentRequest struct {
+	Amount    money.Money `json:"amount"`
+	Date      civil.Date  `json:"date"` // defaults to today
+	Method    string      `json:"method"`
+	Reference string      `json:"reference"`
+}
+
+// PaymentResponse is returned after recording a payment.
+type PaymentResponse struct {
+	Payment models.Payment  `json:"payment"`
+	Invoice *models.Invoice `json:"invoice"`
+}
+
+// RecordPayment handles POST /api/invoices/{id}/payments. Partial payments are
+// allowed; a payment that clears the balance marks the invoice as paid.
+func (h *InvoiceHandler) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	var recorded models.Payment
+	updated, err := h.store.Update(claims.UserID, mux.Vars(r)["id"], func(invoice *models.Invoice) error {
+		var err error
+		recorded, err = payments.Record(invoice, models.Payment{
+			Amount:    req.Amount,
+			Date:      req.Date,
+			Method:    req.Method,
+			Reference: req.Reference,
+		}, time.Now().UTC())
+		return err
+	}, h.journal(r, models.AuditPaymentRecorded))
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, PaymentResponse{Payment: recorded, Invoice: updated})
+}
+
+// ListPayments handles GET /api/invoices/{id}/payments
+func (h *InvoiceHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	invoice, err := h.store.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	list := invoice.Payments
+	if list == nil {
+		list = []models.Payment{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
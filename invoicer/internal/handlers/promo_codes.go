@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/promos"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// PromoCodeHandler handles promo code requests.
+type PromoCodeHandler struct {
+	codes store.PromoCodeStore
+}
+
+// NewPromoCodeHandler creates a new promo code handler.
+func NewPromoCodeHandler(codes store.PromoCodeStore) *PromoCodeHandler {
+	return &PromoCodeHandler{codes: codes}
+}
+
+// CreateCode handles POST /api/promo-codes
+func (h *PromoCodeHandler) CreateCode(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	code, ok := decodePromoCode(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.codes.Create(claims.UserID, code)
+	if err != nil {
+		writePromoCodeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListCodes handles GET /api/promo-codes
+func (h *PromoCodeHandler) ListCodes(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	codes, err := h.codes.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list promo codes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, codes)
+}
+
+// GetCode handles GET /api/promo-codes/{id}
+func (h *PromoCodeHandler) GetCode(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	code, err := h.codes.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writePromoCodeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, code)
+}
+
+// UpdateCode handles PUT /api/promo-codes/{id}. The use count is kept, and
+// invoices already saved with the code keep the discount they copied.
+func (h *PromoCodeHandler) UpdateCode(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	code, ok := decodePromoCode(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.codes.Update(claims.UserID, mux.Vars(r)["id"], code)
+	if err != nil {
+		writePromoCodeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteCode handles DELETE /api/promo-codes/{id}
+func (h *PromoCodeHandler) DeleteCode(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	if err := h.codes.Delete(claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writePromoCodeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodePromoCode reads and validates a promo code from the request body,
+// writing an error response and returning false if it is invalid.
+func decodePromoCode(w http.ResponseWriter, r *http.Request) (*models.PromoCode, bool) {
+	var code models.PromoCode
+	if err := json.NewDecoder(r.Body).Decode(&code); err != nil {
+		writeDecodeError(w, err)
+		return nil, false
+	}
+	defer r.Body.Close()
+
+	code.Code = promos.Normalize(code.Code)
+	code.Currency = currency.Normalize(code.Currency)
+	code.Discount.Label = strings.TrimSpace(code.Discount.Label)
+
+	if err := validatePromoCode(&code); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return nil, false
+	}
+	return &code, true
+}
+
+// validatePromoCode checks the code's format, discount, currency and usage
+// limit. Fixed discounts need a currency their amount fits in; percentage
+// discounts apply in any currency and take none.
+func validatePromoCode(code *models.PromoCode) error {
+	if err := promos.ValidateCode(code.Code); err != nil {
+		return err
+	}
+	if err := validateDiscountTerms("discount.", &code.Discount); err != nil {
+		return err
+	}
+	if code.Discount.Kind == models.DiscountFixed {
+		if err := validateCurrency(code.Currency, true); err != nil {
+			return err
+		}
+		if !code.Discount.Amount.FitsIn(code.Currency) {
+			return fmt.Errorf("discount.amount has more decimal places than %s allows", code.Currency)
+		}
+	} else if code.Currency != "" {
+		return fmt.Errorf("currency is only used by %q discounts", models.DiscountFixed)
+	}
+	if code.MaxUses < 0 {
+		return fmt.Errorf("maxUses must not be negative")
+	}
+	return nil
+}
+
+// writePromoCodeError maps promo code store errors to HTTP responses.
+func writePromoCodeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrPromoCodeNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Promo code not found")
+	case errors.Is(err, store.ErrDuplicatePromoCode):
+		writeError(w, http.StatusConflict, "conflict", "Promo code already in use")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access promo code")
+	}
+}
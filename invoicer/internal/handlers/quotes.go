@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/quotes"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// QuoteConversionResponse is returned after converting a quote to an invoice.
+type QuoteConversionResponse struct {
+	Invoice *models.Invoice `json:"invoice"`
+	Quote   *models.Invoice `json:"quote"`
+}
+
+// CreateQuote handles POST /api/quotes. The body has the same shape as an
+// invoice plus validUntil; when invoiceNumber is omitted the server allocates
+// the next number from the quote sequence.
+func (h *InvoiceHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	h.createDocument(w, r, models.DocumentQuote)
+}
+
+// ListQuotes handles GET /api/quotes, with the same query parameters as
+// GET /api/invoices.
+func (h *InvoiceHandler) ListQuotes(w http.ResponseWriter, r *http.Request) {
+	h.listDocuments(w, r, models.DocumentQuote)
+}
+
+// GetQuote handles GET /api/quotes/{id}
+func (h *InvoiceHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
+	h.getDocument(w, r, models.DocumentQuote)
+}
+
+// UpdateQuote handles PUT /api/quotes/{id}. Only draft quotes can be changed.
+func (h *InvoiceHandler) UpdateQuote(w http.ResponseWriter, r *http.Request) {
+	h.updateDocument(w, r, models.DocumentQuote)
+}
+
+// DeleteQuote handles DELETE /api/quotes/{id}. Only draft quotes can be deleted.
+func (h *InvoiceHandler) DeleteQuote(w http.ResponseWriter, r *http.Request) {
+	h.deleteDocument(w, r, models.DocumentQuote)
+}
+
+// ChangeQuoteStatus handles POST /api/quotes/{id}/status. Quotes move from
+// draft to sent (and optionally viewed), then to accepted or declined.
+// Accepting a quote after its validUntil date fails.
+func (h *InvoiceHandler) ChangeQuoteStatus(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.DocumentQuote)
+}
+
+// QuoteHistory handles GET /api/quotes/{id}/history
+func (h *InvoiceHandler) QuoteHistory(w http.ResponseWriter, r *http.Request) {
+	h.documentHistory(w, r, models.DocumentQuote)
+}
+
+// ConvertQuote handles POST /api/quotes/{id}/convert. It creates a draft
+// invoice from an accepted quote, linked back to the quote, and records the
+// invoice on the quote. Each quote converts once.
+func (h *InvoiceHandler) ConvertQuote(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+	id := mux.Vars(r)["id"]
+
+	quote, err := h.store.Get(claims.UserID, id)
+	if err == nil && !quote.IsQuote() {
+		err = store.ErrInvoiceNotFound
+	}
+	if err != nil {
+		writeDocumentError(w, models.DocumentQuote, err)
+		return
+	}
+
+	invoice, err := quotes.Convert(quote, time.Now().UTC())
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	// The draft invoice is numbered when it is issued. If the quote cannot be
+	// linked to it, it is deleted again so the quote can be converted later.
+	created, err := h.store.Create(claims.UserID, invoice, h.journal(r, models.AuditCreated))
+	if err != nil {
+		writeDocumentError(w, models.DocumentQuote, err)
+		return
+	}
+	updated, err := h.store.Update(claims.UserID, id, func(existing *models.Invoice) error {
+		return quotes.MarkConverted(existing, created)
+	}, h.journal(r, models.AuditConverted))
+	if err != nil {
+		h.store.Delete(claims.UserID, created.ID, nil, h.journal(r, models.AuditDeleted))
+		writeDocumentError(w, models.DocumentQuote, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, QuoteConversionResponse{Invoice: created, Quote: updated})
+}
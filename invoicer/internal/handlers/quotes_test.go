@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"invoice-generator/invoicer/internal/models"
+	"net/http"
+	"testing"
+)
+
+// createQuote creates a quote from draftInvoice and moves it through the
+// given statuses.
+func (s *testServer) createQuote(t *testing.T, statuses ...models.InvoiceStatus) *models.Invoice {
+	t.Helper()
+	var quote models.Invoice
+	decode(t, s.mustDo(t, "POST", "/quotes", draftInvoice, http.StatusCreated), &quote)
+	for _, status := range statuses {
+		decode(t, s.mustDo(t, "POST", "/quotes/"+quote.ID+"/status", `{"status":"`+string(status)+`"}`, http.StatusOK), &quote)
+	}
+	return &quote
+}
+
+func TestConvertQuote(t *testing.T) {
+	s := newTestServer()
+	quote := s.createQuote(t, models.StatusSent, models.StatusAccepted)
+
+	var converted QuoteConversionResponse
+	decode(t, s.mustDo(t, "POST", "/quotes/"+quote.ID+"/convert", "", http.StatusCreated), &converted)
+	invoice := converted.Invoice
+	if invoice.IsQuote() || invoice.QuoteID != quote.ID || invoice.Status != models.StatusDraft {
+		t.Errorf("expected a draft invoice linked to the quote, got %+v", invoice)
+	}
+	if invoice.Total.Cmp(quote.Total) != 0 {
+		t.Errorf("expected the quote's total %s, got %s", quote.Total, invoice.Total)
+	}
+	if converted.Quote.ConvertedInvoiceID != invoice.ID {
+		t.Errorf("expected the quote to link to %s, got %q", invoice.ID, converted.Quote.ConvertedInvoiceID)
+	}
+
+	if code := errorCode(t, s.mustDo(t, "POST", "/quotes/"+quote.ID+"/convert", "", http.StatusConflict)); code != "not_convertible" {
+		t.Errorf("expected not_convertible when converting twice, got %q", code)
+	}
+}
+
+func TestConvertQuote_Invalid(t *testing.T) {
+	s := newTestServer()
+	sent := s.createQuote(t, models.StatusSent)
+	declined := s.createQuote(t, models.StatusSent, models.StatusDeclined)
+	invoice := s.createDraft(t)
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"unknown quote", "inv_404", http.StatusNotFound},
+		{"invoice", invoice.ID, http.StatusNotFound},
+		{"quote not accepted", sent.ID, http.StatusConflict},
+		{"declined quote", declined.ID, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		rr := s.do("POST", "/quotes/"+tt.id+"/convert", "")
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestConvertQuote_RollsBackWhenQuoteCannotBeLinked(t *testing.T) {
+	s := newTestServer()
+	quote := s.createQuote(t, models.StatusSent, models.StatusAccepted)
+
+	s.invoices.failUpdates = true
+	s.mustDo(t, "POST", "/quotes/"+quote.ID+"/convert", "", http.StatusInternalServerError)
+	s.invoices.failUpdates = false
+
+	var invoices []models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices", "", http.StatusOK), &invoices)
+	if len(invoices) != 0 {
+		t.Errorf("expected the invoice to be deleted again, got %d invoices", len(invoices))
+	}
+	var stored models.Invoice
+	decode(t, s.mustDo(t, "GET", "/quotes/"+quote.ID, "", http.StatusOK), &stored)
+	if stored.ConvertedInvoiceID != "" {
+		t.Errorf("expected the quote to stay unconverted, got %q", stored.ConvertedInvoiceID)
+	}
+
+	s.mustDo(t, "POST", "/quotes/"+quote.ID+"/convert", "", http.StatusCreated)
+}
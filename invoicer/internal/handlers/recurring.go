@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/recurring"
+	"invoice-generator/invoicer/internal/store"
+	"invoice-generator/invoicer/internal/terms"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// errScheduleStarted is returned when changing the timing of a schedule that has
+// already generated or skipped invoices.
+var errScheduleStarted = errors.New("cadence, cron and startDate cannot change once a schedule has generated or skipped invoices; create a new schedule instead")
+
+// RecurringHandler handles recurring invoice schedule requests.
+type RecurringHandler struct {
+	schedules store.RecurringStore
+	directory *directory.Directory
+}
+
+// NewRecurringHandler creates a new recurring schedule handler.
+func NewRecurringHandler(schedules store.RecurringStore, dir *directory.Directory) *RecurringHandler {
+	return &RecurringHandler{schedules: schedules, directory: dir}
+}
+
+// CreateSchedule handles POST /api/recurring
+func (h *RecurringHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	var schedule models.RecurringSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.prepareSchedule(claims.UserID, &schedule); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	schedule.Occurrences = 0
+	schedule.Skipped = 0
+	schedule.LastRun = civil.Date{}
+	schedule.NextRun = recurring.NextRun(&schedule)
+
+	created, err := h.schedules.Create(claims.UserID, &schedule)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// ListSchedules handles GET /api/recurring
+func (h *RecurringHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	schedules, err := h.schedules.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list recurring schedules")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schedules)
+}
+
+// GetSchedule handles GET /api/recurring/{id}
+func (h *RecurringHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	schedule, err := h.schedules.Get(claims.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+// UpdateSchedule handles PUT /api/recurring/{id}. The body replaces the
+// schedule; progress is preserved, and the timing is fixed once invoices have
+// been generated so that past periods keep their dates. Unpausing a schedule
+// skips the periods that fell due while it was paused.
+func (h *RecurringHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	var schedule models.RecurringSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.prepareSchedule(claims.UserID, &schedule); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	updated, err := h.schedules.Update(claims.UserID, mux.Vars(r)["id"], func(existing *models.RecurringSchedule) error {
+		if existing.Occurrences+existing.Skipped > 0 && (schedule.Cadence != existing.Cadence ||
+			schedule.Cron != existing.Cron || schedule.StartDate != existing.StartDate) {
+			return errScheduleStarted
+		}
+
+		occurrences, skipped, lastRun, paused := existing.Occurrences, existing.Skipped, existing.LastRun, existing.Paused
+		*existing = *schedule.Clone()
+		existing.Occurrences, existing.Skipped, existing.LastRun = occurrences, skipped, lastRun
+		existing.NextRun = recurring.NextRun(existing)
+		if paused && !existing.Paused {
+			recurring.Resume(existing, civil.Of(time.Now().UTC()))
+		}
+		return nil
+	})
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteSchedule handles DELETE /api/recurring/{id}. Invoices already generated
+// by the schedule are kept.
+func (h *RecurringHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	if err := h.schedules.Delete(claims.UserID, mux.Vars(r)["id"]); err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// prepareSchedule validates the schedule's timing and base invoice, filling
+// client and business details from the directory and computing totals on the server.
+func (h *RecurringHandler) prepareSchedule(userID string, schedule *models.RecurringSchedule) error {
+	if err := recurring.Validate(schedule); err != nil {
+		return err
+	}
+
+	template := &schedule.Template
+	resetServerManaged(template, models.DocumentInvoice)
+	template.Currency = currency.Normalize(template.Currency)
+	template.BaseCurrency = currency.Normalize(template.BaseCurrency)
+	template.InvoiceNumber = ""
+	template.Status = ""
+	template.StatusHistory = nil
+	if template.PromoCode != "" {
+		return fmt.Errorf("template: promo codes cannot be used on recurring schedules")
+	}
+
+	if err := h.directory.Apply(userID, template); err != nil {
+		return fmt.Errorf("template: %v", err)
+	}
+	terms.Apply(template)
+	calc.Apply(template)
+
+	return validateInvoiceContent(template)
+}
+
+// writeScheduleError maps schedule store errors to HTTP responses.
+func writeScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrScheduleNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "Recurring schedule not found")
+	case errors.Is(err, errScheduleStarted):
+		writeError(w, http.StatusConflict, "conflict", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to access recurring schedule")
+	}
+}
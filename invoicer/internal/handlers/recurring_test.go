@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// newRecurringTestServer routes the recurring schedule endpoints to a handler
+// on memory stores.
+func newRecurringTestServer() *testServer {
+	dir := directory.New(store.NewMemoryClientStore(), store.NewMemoryBusinessProfileStore(), store.NewMemoryCatalogStore())
+	h := NewRecurringHandler(store.NewMemoryRecurringStore(), dir)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/recurring", h.CreateSchedule).Methods("POST")
+	r.HandleFunc("/recurring/{id}", h.UpdateSchedule).Methods("PUT")
+	return &testServer{router: r}
+}
+
+const weeklySchedule = `{"name":"Retainer","cadence":"weekly","startDate":"2020-01-06","paused":%t,
+	"template":{"businessName":"Acme","clientName":"Globex","currency":"USD",
+	"items":[{"description":"Retainer","quantity":1,"rate":100}]}}`
+
+func TestUpdateSchedule_UnpauseSkipsPausedPeriods(t *testing.T) {
+	s := newRecurringTestServer()
+	today := civil.Of(time.Now().UTC())
+
+	var schedule models.RecurringSchedule
+	decode(t, s.mustDo(t, "POST", "/recurring", fmt.Sprintf(weeklySchedule, true), http.StatusCreated), &schedule)
+
+	// Staying paused keeps the schedule where it was.
+	decode(t, s.mustDo(t, "PUT", "/recurring/"+schedule.ID, fmt.Sprintf(weeklySchedule, true), http.StatusOK), &schedule)
+	if schedule.Skipped != 0 || schedule.NextRun.String() != "2020-01-06" {
+		t.Fatalf("expected a paused schedule to keep its next run, got %d skipped and next %s", schedule.Skipped, schedule.NextRun)
+	}
+
+	decode(t, s.mustDo(t, "PUT", "/recurring/"+schedule.ID, fmt.Sprintf(weeklySchedule, false), http.StatusOK), &schedule)
+	if schedule.Skipped == 0 || schedule.Occurrences != 0 {
+		t.Errorf("expected the paused weeks to be skipped, got %d skipped and %d occurrences", schedule.Skipped, schedule.Occurrences)
+	}
+	if schedule.NextRun.Before(today) || schedule.NextRun.After(today.AddDays(6)) {
+		t.Errorf("expected the next run within a week from today (%s), got %s", today, schedule.NextRun)
+	}
+
+	// The timing is fixed once periods have been skipped.
+	body := strings.Replace(fmt.Sprintf(weeklySchedule, false), `"weekly"`, `"monthly"`, 1)
+	if rr := s.do("PUT", "/recurring/"+schedule.ID, body); rr.Code != http.StatusConflict {
+		t.Errorf("expected 409 for changing the cadence, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/reports"
+	"invoice-generator/invoicer/internal/store"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ReportHandler handles finance report requests.
+type ReportHandler struct {
+	invoices store.InvoiceStore
+}
+
+// NewReportHandler creates a new report handler.
+func NewReportHandler(invoices store.InvoiceStore) *ReportHandler {
+	return &ReportHandler{invoices: invoices}
+}
+
+// Revenue handles GET /api/reports/revenue. from and to (YYYY-MM-DD) limit
+// the invoice dates included; groupBy lists month and/or client (both by
+// default). Rows are always split by currency.
+func (h *ReportHandler) Revenue(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+	query := r.URL.Query()
+
+	opts := reports.RevenueOptions{ByMonth: true, ByClient: true}
+	var err error
+	if opts.From, opts.To, err = dateRange(query); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	if query.Has("groupBy") {
+		opts.ByMonth, opts.ByClient = false, false
+		for _, g := range strings.Split(query.Get("groupBy"), ",") {
+			switch strings.TrimSpace(g) {
+			case "month":
+				opts.ByMonth = true
+			case "client":
+				opts.ByClient = true
+			case "", "currency":
+			default:
+				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("cannot group by %q: use month, client or both", g))
+				return
+			}
+		}
+	}
+
+	invoices, err := h.invoices.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list invoices")
+		return
+	}
+
+	report := reports.Revenue(invoices, opts)
+	writeReport(w, r, "revenue", report, report.WriteCSV)
+}
+
+// Tax handles GET /api/reports/tax. from and to (YYYY-MM-DD) limit the
+// invoice dates included; period is month (the default), quarter or year.
+func (h *ReportHandler) Tax(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+	query := r.URL.Query()
+
+	opts := reports.TaxOptions{Period: reports.PeriodMonth}
+	var err error
+	if opts.From, opts.To, err = dateRange(query); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	switch period := query.Get("period"); period {
+	case "":
+	case reports.PeriodMonth, reports.PeriodQuarter, reports.PeriodYear:
+		opts.Period = period
+	default:
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("unknown period %q: use month, quarter or year", period))
+		return
+	}
+
+	invoices, err := h.invoices.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list invoices")
+		return
+	}
+
+	report := reports.Tax(invoices, opts)
+	writeReport(w, r, "tax", report, report.WriteCSV)
+}
+
+// Aging handles GET /api/reports/aging. asOf (YYYY-MM-DD) defaults to today.
+func (h *ReportHandler) Aging(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	asOf, err := dateParam(r.URL.Query().Get("asOf"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	if asOf.IsZero() {
+		asOf = civil.Of(time.Now().UTC())
+	}
+
+	invoices, err := h.invoices.List(claims.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to list invoices")
+		return
+	}
+
+	report := reports.Aging(invoices, asOf)
+	writeReport(w, r, "aging", report, report.WriteCSV)
+}
+
+// dateParam parses an optional YYYY-MM-DD query parameter.
+func dateParam(s string) (civil.Date, error) {
+	if s == "" {
+		return civil.Date{}, nil
+	}
+	return civil.Parse(s)
+}
+
+// dateRange parses the optional from and to query parameters.
+func dateRange(query url.Values) (from, to civil.Date, err error) {
+	if from, err = dateParam(query.Get("from")); err != nil {
+		return
+	}
+	if to, err = dateParam(query.Get("to")); err != nil {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		err = errors.New("to must not be before from")
+	}
+	return
+}
+
+// writeReport writes a report as CSV when the request asks for it with
+// format=csv or an Accept header of text/csv, and as JSON otherwise.
+func writeReport(w http.ResponseWriter, r *http.Request, name string, report interface{}, writeCSV func(io.Writer) error) {
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		format = "csv"
+	}
+
+	switch format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, report)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", name))
+		w.WriteHeader(http.StatusOK)
+		writeCSV(w)
+	default:
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("unknown format %q: use json or csv", format))
+	}
+}
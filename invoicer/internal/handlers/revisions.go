@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/promos"
+	"invoice-generator/invoicer/internal/revisions"
+	"invoice-generator/invoicer/internal/snapshot"
+	"invoice-generator/invoicer/internal/store"
+	"invoice-generator/invoicer/internal/terms"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RevisionResponse is returned after revising an invoice.
+type RevisionResponse struct {
+	Revision *models.Invoice `json:"revision"`
+	Previous *models.Invoice `json:"previous"`
+}
+
+// ReviseInvoice handles POST /api/invoices/{id}/revisions. The body holds the
+// corrected invoice, which is issued as the next revision of the invoice under
+// its number with a -R<n> suffix and frozen. The revised invoice is voided and
+// linked to the revision; its own frozen copy is kept unchanged.
+func (h *InvoiceHandler) ReviseInvoice(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+	id := mux.Vars(r)["id"]
+
+	var invoice models.Invoice
+	if err := json.NewDecoder(r.Body).Decode(&invoice); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	previous, err := h.store.Get(claims.UserID, id)
+	if err == nil && !ofKind(previous, models.DocumentInvoice) {
+		err = store.ErrInvoiceNotFound
+	}
+	if err == nil {
+		err = revisions.Check(previous)
+	}
+	// The body may carry the revised invoice's number; the revision gets
+	// its own from it.
+	if err == nil && invoice.InvoiceNumber != "" && invoice.InvoiceNumber != previous.InvoiceNumber {
+		err = errManualNumber
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	resetServerManaged(&invoice, models.DocumentInvoice)
+	invoice.Currency = currency.Normalize(invoice.Currency)
+	invoice.BaseCurrency = currency.Normalize(invoice.BaseCurrency)
+	if invoice.InvoiceDate.IsZero() {
+		invoice.InvoiceDate = civil.Of(time.Now().UTC())
+	}
+
+	if err := h.directory.Apply(claims.UserID, &invoice); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	promo, err := h.applyPromoCode(claims.UserID, &invoice)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	terms.Apply(&invoice)
+
+	if err := h.reconcileTotals(&invoice); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "totals_mismatch", err.Error())
+		return
+	}
+
+	if err := validateInvoiceContent(&invoice); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	// A promo code already counted for the revised invoice is not counted
+	// again; the store counts a new one as it saves the revision.
+	invoice.RedeemedPromoCodes = previous.RedeemedPromoCodes
+	if previous.PromoCode != "" && !slices.Contains(invoice.RedeemedPromoCodes, previous.PromoCode) {
+		invoice.RedeemedPromoCodes = append(invoice.RedeemedPromoCodes, previous.PromoCode)
+	}
+	redeem := promo != nil && !slices.Contains(invoice.RedeemedPromoCodes, promo.Code)
+	if redeem {
+		if err := promos.Usable(promo, civil.Of(time.Now().UTC())); err != nil {
+			writeInvoiceError(w, err)
+			return
+		}
+		invoice.RedeemedPromoCodes = append(slices.Clone(invoice.RedeemedPromoCodes), promo.Code)
+	}
+
+	now := time.Now().UTC()
+	if err := revisions.Build(previous, &invoice, now); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	if err := fx.Stamp(&invoice, h.rates, civil.Of(now)); err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+	frozen, err := snapshot.Freeze(&invoice, now)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "Failed to generate PDF")
+		return
+	}
+
+	created, err := h.store.Create(claims.UserID, &invoice, h.journal(r, models.AuditCreated))
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	var updated *models.Invoice
+	err = h.storeSnapshot(r, frozen, created)
+	if err == nil {
+		updated, err = h.store.Update(claims.UserID, id, func(p *models.Invoice) error {
+			return revisions.Supersede(p, created, now)
+		}, h.journal(r, models.AuditRevised))
+	}
+	if err != nil {
+		h.discard(r, created)
+		if redeem {
+			h.promoCodes.Release(claims.UserID, promo.Code)
+		}
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, RevisionResponse{Revision: created, Previous: updated})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReviseInvoice(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createIssued(t)
+
+	corrected := strings.Replace(draftInvoice, `"quantity":2`, `"quantity":3`, 1)
+	var revised RevisionResponse
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/revisions", corrected, http.StatusCreated), &revised)
+	revision := revised.Revision
+	if revision.InvoiceNumber != invoice.InvoiceNumber+"-R1" || revision.Status != models.StatusIssued {
+		t.Errorf("expected issued revision %s-R1, got %q (%s)", invoice.InvoiceNumber, revision.InvoiceNumber, revision.Status)
+	}
+	if got := revision.Total.String(); got != "350.00" {
+		t.Errorf("expected the corrected total 350.00, got %s", got)
+	}
+	if revised.Previous.Status != models.StatusVoid || revised.Previous.SupersededByID != revision.ID {
+		t.Errorf("expected the invoice to be voided and linked to %s, got %s and %q", revision.ID, revised.Previous.Status, revised.Previous.SupersededByID)
+	}
+	if _, err := s.snapshots.Get("user_1", revision.ID); err != nil {
+		t.Errorf("expected the revision to be frozen, got %v", err)
+	}
+
+	if code := errorCode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/revisions", corrected, http.StatusConflict)); code != "not_revisable" {
+		t.Errorf("expected not_revisable for a superseded invoice, got %q", code)
+	}
+}
+
+func TestReviseInvoice_Invalid(t *testing.T) {
+	s := newTestServer()
+	draft := s.createDraft(t)
+	issued := s.createIssued(t)
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"unknown invoice", "inv_404", draftInvoice, http.StatusNotFound},
+		{"draft invoice", draft.ID, draftInvoice, http.StatusConflict},
+		{"no items", issued.ID, `{"businessName":"Acme","clientName":"Globex","currency":"USD","items":[]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := s.do("POST", "/invoices/"+tt.id+"/revisions", tt.body)
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestReviseInvoice_RollsBackWhenInvoiceCannotBeSuperseded(t *testing.T) {
+	s := newTestServer()
+	s.mustDo(t, "POST", "/promo-codes", `{"code":"TENPC","discount":{"kind":"percent","rate":10}}`, http.StatusCreated)
+	invoice := s.createIssued(t)
+
+	withCode := strings.Replace(draftInvoice, `"currency":"USD"`, `"currency":"USD","promoCode":"TENPC"`, 1)
+	s.invoices.failUpdates = true
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/revisions", withCode, http.StatusInternalServerError)
+	s.invoices.failUpdates = false
+
+	// The revision would have been inv_2.
+	s.mustDo(t, "GET", "/invoices/inv_2", "", http.StatusNotFound)
+	if _, err := s.snapshots.Get("user_1", "inv_2"); !errors.Is(err, store.ErrSnapshotNotFound) {
+		t.Errorf("expected the revision's snapshot to be deleted, got %v", err)
+	}
+	var stored models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID, "", http.StatusOK), &stored)
+	if stored.Status != models.StatusIssued || stored.SupersededByID != "" {
+		t.Errorf("expected the invoice to stay issued, got %s superseded by %q", stored.Status, stored.SupersededByID)
+	}
+	code, _ := s.promoCodes.GetByCode("user_1", "TENPC")
+	if code.Uses != 0 {
+		t.Errorf("expected the promo code use to be released, got %d uses", code.Uses)
+	}
+
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/revisions", withCode, http.StatusCreated)
+}
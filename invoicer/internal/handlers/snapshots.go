@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/pdf"
+	"invoice-generator/invoicer/internal/snapshot"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// InvoicePDF handles GET /api/invoices/{id}/pdf. Issued invoices and credit
+// notes return the PDF frozen when they were issued, byte for byte, with its
+// SHA-256 hash as the ETag. Once late fees, payments or credits have been
+// recorded, the frozen content is rendered again with them and the balance
+// they leave, after checking it against its hash; ?frozen=true still returns
+// the PDF as issued. Drafts are rendered from their current content.
+func (h *InvoiceHandler) InvoicePDF(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	invoice, err := h.store.Get(claims.UserID, mux.Vars(r)["id"])
+	if err == nil && !ofKind(invoice, models.DocumentInvoice) {
+		err = store.ErrInvoiceNotFound
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	var pdfData []byte
+	frozen, err := h.frozenSnapshot(invoice)
+	switch {
+	case err == nil && (!snapshot.HasLedger(invoice) || r.URL.Query().Get("frozen") == "true"):
+		pdfData = frozen.PDF
+		w.Header().Set("ETag", fmt.Sprintf("%q", frozen.PDFSHA256))
+	case err == nil, errors.Is(err, store.ErrSnapshotNotFound):
+		pdfData, err = pdf.NewGenerator().GenerateInvoice(invoice)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "Failed to generate PDF")
+			return
+		}
+	default:
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	name := invoice.InvoiceNumber
+	if name == "" {
+		name = "draft-" + invoice.ID
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=invoice-%s.pdf", name))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfData)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfData)
+}
+
+// InvoiceSnapshot handles GET /api/invoices/{id}/snapshot. It returns the
+// canonical JSON frozen when the invoice was issued, whose SHA-256 hash is
+// the invoice's documentSha256. Drafts have no snapshot.
+func (h *InvoiceHandler) InvoiceSnapshot(w http.ResponseWriter, r *http.Request) {
+	claims := middleware.GetClaims(r)
+
+	invoice, err := h.store.Get(claims.UserID, mux.Vars(r)["id"])
+	if err == nil && !ofKind(invoice, models.DocumentInvoice) {
+		err = store.ErrInvoiceNotFound
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	frozen, err := h.frozenSnapshot(invoice)
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", fmt.Sprintf("%q", frozen.DocumentSHA256))
+	w.WriteHeader(http.StatusOK)
+	w.Write(frozen.Document)
+}
+
+// frozenSnapshot returns the invoice's snapshot after checking it against
+// its hashes and the stored invoice against the frozen content.
+func (h *InvoiceHandler) frozenSnapshot(invoice *models.Invoice) (*models.InvoiceSnapshot, error) {
+	frozen, err := h.snapshots.Get(invoice.UserID, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.Check(invoice, frozen); err != nil {
+		return nil, err
+	}
+	return frozen, nil
+}
+
+// freeze freezes a stored invoice that is being issued and saves its snapshot.
+func (h *InvoiceHandler) freeze(invoice *models.Invoice, at time.Time) error {
+	frozen, err := snapshot.Freeze(invoice, at)
+	if err != nil {
+		return err
+	}
+	return h.snapshots.Create(frozen)
+}
+
+// storeSnapshot saves the snapshot of a document frozen before it was
+// created. If the snapshot cannot be saved, the document is deleted again so
+// that no issued document is left without one.
+func (h *InvoiceHandler) storeSnapshot(r *http.Request, frozen *models.InvoiceSnapshot, created *models.Invoice) error {
+	frozen.InvoiceID = created.ID
+	frozen.UserID = created.UserID
+	if err := h.snapshots.Create(frozen); err != nil {
+		h.store.Delete(created.UserID, created.ID, nil, h.journal(r, models.AuditDeleted))
+		return err
+	}
+	return nil
+}
+
+// discard rolls back a document created with its snapshot when a later step
+// fails. The snapshot goes first so that none is left behind for an ID the
+// document no longer holds.
+func (h *InvoiceHandler) discard(r *http.Request, created *models.Invoice) {
+	h.snapshots.Delete(created.UserID, created.ID)
+	h.store.Delete(created.UserID, created.ID, nil, h.journal(r, models.AuditDeleted))
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/quotes"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// StatusChangeRequest is the body for POST /api/invoices/{id}/status.
+type StatusChangeRequest struct {
+	Status models.InvoiceStatus `json:"status"`
+}
+
+// ChangeInvoiceStatus handles POST /api/invoices/{id}/status. It moves the
+// invoice to a new lifecycle status if the transition is allowed and records
+// the time of the change. Issuing an invoice gives it the next number from the
+// invoice sequence, stamps it with the exchange rate to its base currency and
+// freezes its content and PDF.
+func (h *InvoiceHandler) ChangeInvoiceStatus(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.DocumentInvoice)
+}
+
+// changeStatus moves the user's document of the given kind to the requested status.
+func (h *InvoiceHandler) changeStatus(w http.ResponseWriter, r *http.Request, kind models.DocumentType) {
+	claims := middleware.GetClaims(r)
+
+	var req StatusChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	if !lifecycle.IsValid(req.Status) {
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Unknown status %q", req.Status))
+		return
+	}
+	if req.Status == models.StatusPaid || req.Status == models.StatusPartiallyPaid {
+		writeError(w, http.StatusConflict, "invalid_transition", "Payment statuses are set by recording payments at /api/invoices/{id}/payments")
+		return
+	}
+
+	// A snapshot stored before the update fails is removed again, so that
+	// the invoice can still be issued.
+	id := mux.Vars(r)["id"]
+	var updated *models.Invoice
+	save := func(number string) error {
+		var frozen bool
+		var err error
+		updated, err = h.store.Update(claims.UserID, id, func(invoice *models.Invoice) error {
+			if !ofKind(invoice, kind) {
+				return store.ErrInvoiceNotFound
+			}
+			if invoice.IsCreditNote() {
+				return errCreditNoteStatus
+			}
+			now := time.Now().UTC()
+			if req.Status == models.StatusAccepted && quotes.IsExpired(invoice, now) {
+				return quotes.ErrExpired
+			}
+			if err := lifecycle.Transition(invoice, req.Status, now); err != nil {
+				return err
+			}
+			if req.Status == models.StatusIssued {
+				if number != "" {
+					invoice.InvoiceNumber = number
+				}
+				if err := fx.Stamp(invoice, h.rates, civil.Of(now)); err != nil {
+					return err
+				}
+				if err := h.freeze(invoice, now); err != nil {
+					return err
+				}
+				frozen = true
+			}
+			return nil
+		}, h.journal(r, models.AuditStatusChanged))
+		if err != nil && frozen {
+			h.snapshots.Delete(claims.UserID, id)
+		}
+		return err
+	}
+
+	var err error
+	if kind == models.DocumentInvoice && req.Status == models.StatusIssued {
+		err = h.numberOnIssue(claims.UserID, id, save)
+	} else {
+		err = save("")
+	}
+	if err != nil {
+		writeDocumentError(w, kind, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// numberOnIssue runs save with the next invoice number when the invoice being
+// issued has none yet. The save runs inside the allocation, so the number is
+// only consumed once the issued invoice is stored. Invoices that already hold
+// a number, including those numbered at creation before drafts were left
+// unnumbered, are saved with an empty number and keep theirs.
+func (h *InvoiceHandler) numberOnIssue(userID, id string, save func(number string) error) error {
+	existing, err := h.store.Get(userID, id)
+	if err != nil {
+		return err
+	}
+	if existing.InvoiceNumber != "" {
+		return save("")
+	}
+
+	_, err = h.numbers.Allocate(userID, numbering.ScopeInvoice, numberingDate(existing.InvoiceDate), func(number string) error {
+		err := save(number)
+		if errors.Is(err, store.ErrDuplicateNumber) {
+			return numbering.ErrNumberTaken
+		}
+		return err
+	})
+	return err
+}
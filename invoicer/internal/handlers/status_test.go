@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/store"
+	"net/http"
+	"testing"
+)
+
+func TestIssueInvoice_FreezesSnapshot(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createDraft(t)
+
+	var issued models.Invoice
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusOK), &issued)
+	if issued.Status != models.StatusIssued {
+		t.Errorf("expected the invoice to be issued, got %q", issued.Status)
+	}
+	if n := len(issued.StatusHistory); n == 0 || issued.StatusHistory[n-1].Status != models.StatusIssued {
+		t.Errorf("expected the change to be recorded, got %+v", issued.StatusHistory)
+	}
+
+	frozen, err := s.snapshots.Get("user_1", invoice.ID)
+	if err != nil {
+		t.Fatalf("expected a snapshot, got %v", err)
+	}
+	rr := s.mustDo(t, "GET", "/invoices/"+invoice.ID+"/pdf", "", http.StatusOK)
+	if !bytes.Equal(rr.Body.Bytes(), frozen.PDF) || !bytes.HasPrefix(frozen.PDF, []byte("%PDF")) {
+		t.Error("expected the frozen PDF to be served")
+	}
+	if etag := rr.Header().Get("ETag"); etag != `"`+frozen.PDFSHA256+`"` {
+		t.Errorf("expected the PDF hash as ETag, got %s", etag)
+	}
+
+	if code := errorCode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusConflict)); code != "invalid_transition" {
+		t.Errorf("expected invalid_transition when issuing twice, got %q", code)
+	}
+}
+
+func TestIssueInvoice_Invalid(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createDraft(t)
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"unknown invoice", "inv_404", `{"status":"issued"}`, http.StatusNotFound},
+		{"unknown status", invoice.ID, `{"status":"shipped"}`, http.StatusBadRequest},
+		{"payment status", invoice.ID, `{"status":"paid"}`, http.StatusConflict},
+		{"skipped transition", invoice.ID, `{"status":"viewed"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		rr := s.do("POST", "/invoices/"+tt.id+"/status", tt.body)
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rr.Code, rr.Body.String())
+		}
+	}
+	if _, err := s.snapshots.Get("user_1", invoice.ID); !errors.Is(err, store.ErrSnapshotNotFound) {
+		t.Errorf("expected no snapshot for a draft, got %v", err)
+	}
+}
+
+func TestIssueInvoice_RemovesSnapshotWhenSaveFails(t *testing.T) {
+	s := newTestServer()
+	invoice := s.createDraft(t)
+
+	s.invoices.failUpdates = true
+	s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusInternalServerError)
+	s.invoices.failUpdates = false
+
+	var stored models.Invoice
+	decode(t, s.mustDo(t, "GET", "/invoices/"+invoice.ID, "", http.StatusOK), &stored)
+	if stored.Status != models.StatusDraft {
+		t.Errorf("expected the invoice to stay a draft, got %q", stored.Status)
+	}
+	if _, err := s.snapshots.Get("user_1", invoice.ID); !errors.Is(err, store.ErrSnapshotNotFound) {
+		t.Errorf("expected the snapshot to be removed, got %v", err)
+	}
+
+	// The number the failed issue was given is used again.
+	var issued models.Invoice
+	decode(t, s.mustDo(t, "POST", "/invoices/"+invoice.ID+"/status", `{"status":"issued"}`, http.StatusOK), &issued)
+	if first := numbering.DefaultScheme(numbering.ScopeInvoice).Format(numberingDate(issued.InvoiceDate), 1); issued.InvoiceNumber != first {
+		t.Errorf("expected the first number %q, got %q", first, issued.InvoiceNumber)
+	}
+}
@@ -0,0 +1,137 @@
+package lifecycle
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"time"
+)
+
+// transitions lists, for each status, the statuses it may move to.
+// Paid and void are terminal.
+var transitions = map[models.InvoiceStatus][]models.InvoiceStatus{
+	models.StatusDraft: {
+		models.StatusIssued, models.StatusVoid,
+	},
+	models.StatusIssued: {
+		models.StatusSent, models.StatusPartiallyPaid, models.StatusPaid, models.StatusOverdue, models.StatusVoid,
+	},
+	models.StatusSent: {
+		models.StatusViewed, models.StatusPartiallyPaid, models.StatusPaid, models.StatusOverdue, models.StatusVoid,
+	},
+	models.StatusViewed: {
+		models.StatusPartiallyPaid, models.StatusPaid, models.StatusOverdue, models.StatusVoid,
+	},
+	models.StatusPartiallyPaid: {
+		models.StatusPaid, models.StatusOverdue,
+	},
+	models.StatusOverdue: {
+		models.StatusPartiallyPaid, models.StatusPaid, models.StatusVoid,
+	},
+}
+
+// quoteTransitions is the equivalent of transitions for quotes. A quote is
+// sent to the client, who accepts or declines it. Accepted, declined and void
+// are terminal.
+var quoteTransitions = map[models.InvoiceStatus][]models.InvoiceStatus{
+	models.StatusDraft: {
+		models.StatusSent, models.StatusVoid,
+	},
+	models.StatusSent: {
+		models.StatusViewed, models.StatusAccepted, models.StatusDeclined, models.StatusVoid,
+	},
+	models.StatusViewed: {
+		models.StatusAccepted, models.StatusDeclined, models.StatusVoid,
+	},
+}
+
+// TransitionError is returned when a status change is not allowed.
+type TransitionError struct {
+	From models.InvoiceStatus
+	To   models.InvoiceStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change status from %q to %q", e.From, e.To)
+}
+
+// Current returns the invoice's status. Invoices stored before statuses
+// existed have none and are treated as drafts.
+func Current(invoice *models.Invoice) models.InvoiceStatus {
+	if invoice.Status == "" {
+		return models.StatusDraft
+	}
+	return invoice.Status
+}
+
+// IsValid reports whether s is a known invoice or quote status.
+func IsValid(s models.InvoiceStatus) bool {
+	switch s {
+	case models.StatusPaid, models.StatusVoid, models.StatusAccepted, models.StatusDeclined:
+		return true
+	}
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether an invoice may move from one status to another.
+func CanTransition(from, to models.InvoiceStatus) bool {
+	return allowed(transitions, from, to)
+}
+
+// CanTransitionQuote reports whether a quote may move from one status to another.
+func CanTransitionQuote(from, to models.InvoiceStatus) bool {
+	return allowed(quoteTransitions, from, to)
+}
+
+func allowed(table map[models.InvoiceStatus][]models.InvoiceStatus, from, to models.InvoiceStatus) bool {
+	for _, s := range table[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEditable reports whether the invoice content may still be changed.
+// Only drafts are editable; once issued, only the status may change.
+func IsEditable(invoice *models.Invoice) bool {
+	return Current(invoice) == models.StatusDraft
+}
+
+// IsOpen reports whether the invoice has been issued and still awaits payment.
+// Credit notes and quotes are never open.
+func IsOpen(invoice *models.Invoice) bool {
+	if invoice.IsCreditNote() || invoice.IsQuote() {
+		return false
+	}
+	switch Current(invoice) {
+	case models.StatusIssued, models.StatusSent, models.StatusViewed,
+		models.StatusPartiallyPaid, models.StatusOverdue:
+		return true
+	}
+	return false
+}
+
+// Init marks a new invoice as a draft and records when it was created.
+func Init(invoice *models.Invoice, at time.Time) {
+	invoice.Status = models.StatusDraft
+	invoice.StatusHistory = []models.StatusChange{{Status: models.StatusDraft, At: at}}
+}
+
+// Transition moves the invoice to a new status and records the change,
+// or returns a *TransitionError if the move is not allowed. Quotes follow
+// their own transitions.
+func Transition(invoice *models.Invoice, to models.InvoiceStatus, at time.Time) error {
+	from := Current(invoice)
+	can := CanTransition
+	if invoice.IsQuote() {
+		can = CanTransitionQuote
+	}
+	if !can(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+
+	invoice.Status = to
+	invoice.StatusHistory = append(invoice.StatusHistory, models.StatusChange{Status: to, At: at})
+	return nil
+}
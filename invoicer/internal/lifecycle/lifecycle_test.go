@@ -0,0 +1,111 @@
+package lifecycle
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+	"time"
+)
+
+func TestTransition_HappyPath(t *testing.T) {
+	invoice := &models.Invoice{}
+	start := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	Init(invoice, start)
+
+	path := []models.InvoiceStatus{
+		models.StatusIssued,
+		models.StatusSent,
+		models.StatusViewed,
+		models.StatusPartiallyPaid,
+		models.StatusPaid,
+	}
+	for i, status := range path {
+		if err := Transition(invoice, status, start.Add(time.Duration(i+1)*time.Hour)); err != nil {
+			t.Fatalf("Transition to %q failed: %v", status, err)
+		}
+	}
+
+	if invoice.Status != models.StatusPaid {
+		t.Errorf("expected status 'paid', got %q", invoice.Status)
+	}
+	if len(invoice.StatusHistory) != len(path)+1 {
+		t.Fatalf("expected %d history entries, got %d", len(path)+1, len(invoice.StatusHistory))
+	}
+	last := invoice.StatusHistory[len(invoice.StatusHistory)-1]
+	if last.Status != models.StatusPaid || !last.At.Equal(start.Add(5*time.Hour)) {
+		t.Errorf("unexpected last history entry: %+v", last)
+	}
+}
+
+func TestTransition_Rejected(t *testing.T) {
+	cases := []struct {
+		from, to models.InvoiceStatus
+	}{
+		{models.StatusDraft, models.StatusPaid},
+		{models.StatusDraft, models.StatusSent},
+		{models.StatusPaid, models.StatusVoid},
+		{models.StatusVoid, models.StatusIssued},
+		{models.StatusIssued, models.StatusDraft},
+		{models.StatusPartiallyPaid, models.StatusVoid},
+	}
+	for _, c := range cases {
+		invoice := &models.Invoice{Status: c.from}
+		err := Transition(invoice, c.to, time.Now())
+
+		var transitionErr *TransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Errorf("%s -> %s: expected *TransitionError, got %v", c.from, c.to, err)
+		}
+		if invoice.Status != c.from || len(invoice.StatusHistory) != 0 {
+			t.Errorf("%s -> %s: rejected transition modified the invoice", c.from, c.to)
+		}
+	}
+}
+
+func TestIsEditableAndIsOpen(t *testing.T) {
+	if !IsEditable(&models.Invoice{}) {
+		t.Error("expected invoice without status to be editable as a draft")
+	}
+	if IsEditable(&models.Invoice{Status: models.StatusIssued}) {
+		t.Error("expected issued invoice to be immutable")
+	}
+
+	open := []models.InvoiceStatus{models.StatusIssued, models.StatusSent, models.StatusViewed, models.StatusPartiallyPaid, models.StatusOverdue}
+	for _, s := range open {
+		if !IsOpen(&models.Invoice{Status: s}) {
+			t.Errorf("expected %q to be open", s)
+		}
+	}
+	closed := []models.InvoiceStatus{models.StatusDraft, models.StatusPaid, models.StatusVoid}
+	for _, s := range closed {
+		if IsOpen(&models.Invoice{Status: s}) {
+			t.Errorf("expected %q not to be open", s)
+		}
+	}
+}
+
+func TestTransition_Quote(t *testing.T) {
+	at := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	quote := &models.Invoice{DocumentType: models.DocumentQuote}
+	Init(quote, at)
+
+	if err := Transition(quote, models.StatusIssued, at); err == nil {
+		t.Error("expected quotes not to be issued like invoices")
+	}
+	for _, to := range []models.InvoiceStatus{models.StatusSent, models.StatusAccepted} {
+		if err := Transition(quote, to, at); err != nil {
+			t.Fatalf("-> %s: %v", to, err)
+		}
+	}
+	if err := Transition(quote, models.StatusDeclined, at); err == nil {
+		t.Error("expected accepted to be terminal")
+	}
+	if IsOpen(quote) {
+		t.Error("expected quotes never to be open for payment")
+	}
+
+	invoice := &models.Invoice{Status: models.StatusSent}
+	if err := Transition(invoice, models.StatusAccepted, at); err == nil {
+		t.Error("expected invoices not to be accepted like quotes")
+	}
+}
@@ -0,0 +1,383 @@
+// Package listing filters, sorts and pages invoice lists for the list
+// endpoints. Pages are addressed by opaque cursors that hold the sort key of
+// the last invoice returned, so paging stays stable while invoices are added
+// or removed.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"math/big"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is wrapped by errors for malformed query parameters.
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	// DefaultLimit is the page size when none is given.
+	DefaultLimit = 50
+	// MaxLimit is the largest page size accepted.
+	MaxLimit = 200
+)
+
+// Sort fields, named after the invoice's JSON fields.
+const (
+	SortCreatedAt     = "createdAt"
+	SortInvoiceNumber = "invoiceNumber"
+	SortClientName    = "clientName"
+	SortStatus        = "status"
+	SortCurrency      = "currency"
+	SortInvoiceDate   = "invoiceDate"
+	SortDueDate       = "dueDate"
+	SortTotal         = "total"
+)
+
+var sortFields = map[string]bool{
+	SortCreatedAt:     true,
+	SortInvoiceNumber: true,
+	SortClientName:    true,
+	SortStatus:        true,
+	SortCurrency:      true,
+	SortInvoiceDate:   true,
+	SortDueDate:       true,
+	SortTotal:         true,
+}
+
+// Query selects, orders and pages invoices. Zero values do not filter.
+type Query struct {
+	Client          string // client ID, or client name ignoring case
+	Statuses        []models.InvoiceStatus
+	Currencies      []string
+	InvoiceDateFrom civil.Date // inclusive
+	InvoiceDateTo   civil.Date // inclusive
+	DueDateFrom     civil.Date // inclusive
+	DueDateTo       civil.Date // inclusive
+	MinTotal        *money.Money
+	MaxTotal        *money.Money
+	Search          string // words that must each appear in the number, client name or a line description
+
+	Sort       string // one of the Sort fields
+	Descending bool
+	Cursor     string // from a previous page's NextCursor
+	Limit      int
+}
+
+// Page is one page of a listing.
+type Page struct {
+	Invoices   []*models.Invoice
+	Total      int    // invoices matching the filters, across all pages
+	NextCursor string // empty on the last page
+}
+
+// ParseQuery reads a query from URL parameters:
+//
+//	client, status, currency, invoiceDateFrom, invoiceDateTo, dueDateFrom,
+//	dueDateTo, minTotal, maxTotal, q, sort, cursor, limit
+//
+// status and currency take comma-separated lists. sort names a field, with a
+// leading "-" for descending order; the default is -createdAt (newest first).
+func ParseQuery(values url.Values) (*Query, error) {
+	q := &Query{
+		Client: strings.TrimSpace(values.Get("client")),
+		Search: strings.TrimSpace(values.Get("q")),
+		Cursor: values.Get("cursor"),
+		Limit:  DefaultLimit,
+	}
+
+	for _, s := range list(values, "status") {
+		status := models.InvoiceStatus(s)
+		if !lifecycle.IsValid(status) {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidQuery, s)
+		}
+		q.Statuses = append(q.Statuses, status)
+	}
+	for _, code := range list(values, "currency") {
+		q.Currencies = append(q.Currencies, currency.Normalize(code))
+	}
+
+	dates := []struct {
+		name string
+		date *civil.Date
+	}{
+		{"invoiceDateFrom", &q.InvoiceDateFrom},
+		{"invoiceDateTo", &q.InvoiceDateTo},
+		{"dueDateFrom", &q.DueDateFrom},
+		{"dueDateTo", &q.DueDateTo},
+	}
+	for _, d := range dates {
+		s := values.Get(d.name)
+		if s == "" {
+			continue
+		}
+		date, err := civil.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, d.name, err)
+		}
+		*d.date = date
+	}
+
+	amounts := []struct {
+		name   string
+		amount **money.Money
+	}{
+		{"minTotal", &q.MinTotal},
+		{"maxTotal", &q.MaxTotal},
+	}
+	for _, a := range amounts {
+		s := values.Get(a.name)
+		if s == "" {
+			continue
+		}
+		amount, err := money.Parse(s, "")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, a.name, err)
+		}
+		*a.amount = &amount
+	}
+
+	q.Sort = SortCreatedAt
+	q.Descending = true
+	if s := values.Get("sort"); s != "" {
+		q.Descending = strings.HasPrefix(s, "-")
+		q.Sort = strings.TrimPrefix(s, "-")
+		if !sortFields[q.Sort] {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, q.Sort)
+		}
+	}
+
+	if s := values.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxLimit)
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
+
+// list returns the comma-separated values of a parameter, which may also be
+// repeated.
+func list(values url.Values, name string) []string {
+	var result []string
+	for _, v := range values[name] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// Apply returns the page of invoices selected by the query. Invoices are
+// ordered by the sort field, then by creation time and ID, so that every
+// invoice has a fixed position.
+func Apply(invoices []*models.Invoice, q *Query) (*Page, error) {
+	var after *key
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sortSpec(q) {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidQuery, c.Sort)
+		}
+		after = &c.Key
+	}
+
+	matched := make([]*models.Invoice, 0, len(invoices))
+	for _, invoice := range invoices {
+		if Matches(invoice, q) {
+			matched = append(matched, invoice)
+		}
+	}
+
+	less := func(a, b key) bool {
+		if q.Descending {
+			return compare(a, b) > 0
+		}
+		return compare(a, b) < 0
+	}
+	keys := make(map[*models.Invoice]key, len(matched))
+	for _, invoice := range matched {
+		keys[invoice] = keyOf(invoice, q.Sort)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(keys[matched[i]], keys[matched[j]])
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return less(*after, keys[matched[i]])
+		})
+	}
+	end := start + q.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	page := &Page{Invoices: matched[start:end], Total: len(matched)}
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(cursor{Sort: sortSpec(q), Key: keys[matched[end-1]]})
+	}
+	return page, nil
+}
+
+// Matches reports whether the invoice passes the query's filters.
+func Matches(invoice *models.Invoice, q *Query) bool {
+	if q.Client != "" && invoice.ClientID != q.Client && !strings.EqualFold(invoice.ClientName, q.Client) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, lifecycle.Current(invoice)) {
+		return false
+	}
+	if len(q.Currencies) > 0 && !slices.Contains(q.Currencies, invoice.Currency) {
+		return false
+	}
+	if !inRange(invoice.InvoiceDate, q.InvoiceDateFrom, q.InvoiceDateTo) ||
+		!inRange(invoice.DueDate, q.DueDateFrom, q.DueDateTo) {
+		return false
+	}
+	if q.MinTotal != nil && invoice.Total.Cmp(*q.MinTotal) < 0 {
+		return false
+	}
+	if q.MaxTotal != nil && invoice.Total.Cmp(*q.MaxTotal) > 0 {
+		return false
+	}
+	for _, word := range strings.Fields(strings.ToLower(q.Search)) {
+		if !mentions(invoice, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRange reports whether the date is within the inclusive bounds. Invoices
+// without the date are excluded when either bound is set.
+func inRange(date, from, to civil.Date) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	if date.IsZero() {
+		return false
+	}
+	if !from.IsZero() && date.Before(from) {
+		return false
+	}
+	return to.IsZero() || !date.After(to)
+}
+
+// mentions reports whether a lower-case word appears in the invoice number,
+// client name or any line description.
+func mentions(invoice *models.Invoice, word string) bool {
+	if strings.Contains(strings.ToLower(invoice.InvoiceNumber), word) ||
+		strings.Contains(strings.ToLower(invoice.ClientName), word) {
+		return true
+	}
+	for _, item := range invoice.Items {
+		if strings.Contains(strings.ToLower(item.Description), word) {
+			return true
+		}
+	}
+	return false
+}
+
+// key is an invoice's position in a listing: the value of the sort field
+// (text or an exact amount), then its creation time and ID as tie-breakers.
+type key struct {
+	Text      string    `json:"t,omitempty"`
+	Amount    *big.Rat  `json:"a,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// keyOf returns the invoice's key for a sort field. Text is compared ignoring
+// case, dates as YYYY-MM-DD (missing dates first) and totals by amount
+// regardless of currency.
+func keyOf(invoice *models.Invoice, field string) key {
+	k := key{CreatedAt: invoice.CreatedAt, ID: invoice.ID}
+	switch field {
+	case SortInvoiceNumber:
+		k.Text = strings.ToLower(invoice.InvoiceNumber)
+	case SortClientName:
+		k.Text = strings.ToLower(invoice.ClientName)
+	case SortStatus:
+		k.Text = string(lifecycle.Current(invoice))
+	case SortCurrency:
+		k.Text = invoice.Currency
+	case SortInvoiceDate:
+		k.Text = invoice.InvoiceDate.String()
+	case SortDueDate:
+		k.Text = invoice.DueDate.String()
+	case SortTotal:
+		k.Amount = invoice.Total.Rat()
+	}
+	return k
+}
+
+// compare orders two keys: -1 if a comes first, +1 if b does, 0 if equal.
+func compare(a, b key) int {
+	switch {
+	case a.Text != b.Text:
+		return strings.Compare(a.Text, b.Text)
+	case amount(a).Cmp(amount(b)) != 0:
+		return amount(a).Cmp(amount(b))
+	case !a.CreatedAt.Equal(b.CreatedAt):
+		return a.CreatedAt.Compare(b.CreatedAt)
+	default:
+		return strings.Compare(a.ID, b.ID)
+	}
+}
+
+// amount returns the key's amount, or zero if it has none.
+func amount(k key) *big.Rat {
+	if k.Amount == nil {
+		return new(big.Rat)
+	}
+	return k.Amount
+}
+
+// cursor marks the last invoice of a page in a given sort order.
+type cursor struct {
+	Sort string `json:"s"`
+	Key  key    `json:"k"`
+}
+
+// sortSpec returns the query's order as written in the sort parameter.
+func sortSpec(q *Query) string {
+	if q.Descending {
+		return "-" + q.Sort
+	}
+	return q.Sort
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return c, nil
+}
@@ -0,0 +1,206 @@
+package listing
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// invoices returns five invoices created a minute apart, inv_1 first.
+func invoices() []*models.Invoice {
+	base := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	list := []*models.Invoice{
+		{ID: "inv_1", InvoiceNumber: "INV-2026-00001", ClientID: "cli_1", ClientName: "Globex", Currency: "USD", Status: models.StatusPaid,
+			InvoiceDate: civil.MustParse("2026-03-01"), DueDate: civil.MustParse("2026-03-31"), Total: money.New(50000, "USD"),
+			Items: []models.LineItem{{Description: "Website design"}}},
+		{ID: "inv_2", InvoiceNumber: "INV-2026-00002", ClientID: "cli_2", ClientName: "Initech", Currency: "EUR", Status: models.StatusIssued,
+			InvoiceDate: civil.MustParse("2026-03-05"), DueDate: civil.MustParse("2026-04-04"), Total: money.New(12000, "EUR"),
+			Items: []models.LineItem{{Description: "Hosting"}}},
+		{ID: "inv_3", InvoiceNumber: "INV-2026-00003", ClientID: "cli_1", ClientName: "Globex", Currency: "USD", Status: models.StatusOverdue,
+			InvoiceDate: civil.MustParse("2026-03-10"), DueDate: civil.MustParse("2026-03-20"), Total: money.New(12000, "USD"),
+			Items: []models.LineItem{{Description: "Hosting renewal"}}},
+		{ID: "inv_4", ClientName: "Umbrella", Currency: "USD",
+			InvoiceDate: civil.MustParse("2026-03-12"), Total: money.New(99900, "USD"),
+			Items: []models.LineItem{{Description: "Consulting"}}},
+		{ID: "inv_5", InvoiceNumber: "INV-2026-00004", ClientName: "globex", Currency: "JPY", Status: models.StatusIssued,
+			InvoiceDate: civil.MustParse("2026-03-15"), DueDate: civil.MustParse("2026-04-15"), Total: money.New(120, "JPY"),
+			Items: []models.LineItem{{Description: "Design review"}}},
+	}
+	for i, invoice := range list {
+		invoice.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+	}
+	return list
+}
+
+func ids(invoices []*models.Invoice) []string {
+	result := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		result[i] = invoice.ID
+	}
+	return result
+}
+
+func listPage(t *testing.T, query string) *Page {
+	t.Helper()
+	values, _ := url.ParseQuery(query)
+	q, err := ParseQuery(values)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) failed: %v", query, err)
+	}
+	result, err := Apply(invoices(), q)
+	if err != nil {
+		t.Fatalf("Apply(%q) failed: %v", query, err)
+	}
+	return result
+}
+
+func TestApply_Filters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"inv_5", "inv_4", "inv_3", "inv_2", "inv_1"}},
+		{"client=cli_1", []string{"inv_3", "inv_1"}},
+		{"client=GLOBEX", []string{"inv_5", "inv_3", "inv_1"}},
+		{"status=issued,overdue", []string{"inv_5", "inv_3", "inv_2"}},
+		{"status=draft", []string{"inv_4"}},
+		{"currency=usd&currency=JPY", []string{"inv_5", "inv_4", "inv_3", "inv_1"}},
+		{"invoiceDateFrom=2026-03-05&invoiceDateTo=2026-03-12", []string{"inv_4", "inv_3", "inv_2"}},
+		{"dueDateTo=2026-03-31", []string{"inv_3", "inv_1"}},
+		{"minTotal=120&maxTotal=500", []string{"inv_5", "inv_3", "inv_2", "inv_1"}},
+		{"minTotal=121", []string{"inv_4", "inv_1"}},
+		{"q=hosting", []string{"inv_3", "inv_2"}},
+		{"q=design+globex", []string{"inv_5", "inv_1"}},
+		{"q=00002", []string{"inv_2"}},
+	}
+	for _, tt := range tests {
+		if got := ids(listPage(t, tt.query).Invoices); !equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestApply_Sort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"sort=createdAt", []string{"inv_1", "inv_2", "inv_3", "inv_4", "inv_5"}},
+		{"sort=-total", []string{"inv_4", "inv_1", "inv_5", "inv_3", "inv_2"}},
+		{"sort=total", []string{"inv_2", "inv_3", "inv_5", "inv_1", "inv_4"}},
+		{"sort=clientName", []string{"inv_1", "inv_3", "inv_5", "inv_2", "inv_4"}},
+		{"sort=dueDate", []string{"inv_4", "inv_3", "inv_1", "inv_2", "inv_5"}},
+		{"sort=-status", []string{"inv_1", "inv_3", "inv_5", "inv_2", "inv_4"}},
+	}
+	for _, tt := range tests {
+		if got := ids(listPage(t, tt.query).Invoices); !equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestApply_Pagination(t *testing.T) {
+	all := invoices()
+	values := url.Values{"sort": {"-total"}, "limit": {"2"}}
+
+	var got []string
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not end")
+		}
+		q, err := ParseQuery(values)
+		if err != nil {
+			t.Fatalf("ParseQuery failed: %v", err)
+		}
+		page, err := Apply(all, q)
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if pages == 0 && page.Total != 5 {
+			t.Errorf("expected a total of 5, got %d", page.Total)
+		}
+		got = append(got, ids(page.Invoices)...)
+		if page.NextCursor == "" {
+			break
+		}
+		values.Set("cursor", page.NextCursor)
+
+		// An invoice added before the cursor does not shift later pages.
+		if pages == 0 {
+			all = append(all, &models.Invoice{ID: "inv_6", ClientName: "Hooli", Total: money.New(100000, "USD"), CreatedAt: time.Now()})
+		}
+	}
+	if want := []string{"inv_4", "inv_1", "inv_5", "inv_3", "inv_2"}; !equal(got, want) {
+		t.Errorf("got %v across pages, want %v", got, want)
+	}
+}
+
+func TestApply_SortsTotalsExactly(t *testing.T) {
+	// Amounts this large a cent apart are the same float64.
+	all := []*models.Invoice{
+		{ID: "inv_1", Total: money.New(1<<60+1, "USD"), CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "inv_2", Total: money.New(1<<60, "USD"), CreatedAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	values := url.Values{"sort": {"total"}, "limit": {"1"}}
+
+	var got []string
+	for {
+		q, err := ParseQuery(values)
+		if err != nil {
+			t.Fatalf("ParseQuery failed: %v", err)
+		}
+		page, err := Apply(all, q)
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		got = append(got, ids(page.Invoices)...)
+		if page.NextCursor == "" || len(got) > len(all) {
+			break
+		}
+		values.Set("cursor", page.NextCursor)
+	}
+	if want := []string{"inv_2", "inv_1"}; !equal(got, want) {
+		t.Errorf("got %v across pages, want %v", got, want)
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	for _, query := range []string{
+		"status=unknown",
+		"invoiceDateFrom=2026-13-01",
+		"minTotal=abc",
+		"sort=businessName",
+		"limit=0",
+		"limit=1000",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseQuery(values); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", query, err)
+		}
+	}
+
+	next := listPage(t, "sort=total&limit=1").NextCursor
+	for _, query := range []string{"cursor=not-a-cursor", "sort=-total&cursor=" + next} {
+		values, _ := url.ParseQuery(query)
+		q, _ := ParseQuery(values)
+		if _, err := Apply(invoices(), q); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", query, err)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"invoice-generator/invoicer/internal/auth"
+	"net/http"
+	"strings"
+)
+
+// RequireAdmin returns an HTTP middleware that only lets through users whose
+// email is in emails, compared ignoring case. It must run after
+// AuthMiddleware; other users receive a 403 Forbidden response.
+func RequireAdmin(emails []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			admins[email] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetClaims(r)
+			if claims == nil || !admins[strings.ToLower(claims.Email)] {
+				writeJSON(w, http.StatusForbidden, auth.ErrorResponse{
+					Error:   "forbidden",
+					Message: "Administrator access is required",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
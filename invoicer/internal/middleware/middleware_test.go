@@ -105,6 +105,40 @@ func TestAuthMiddleware_RefreshTokenRejected(t *testing.T) {
 	}
 }
 
+func TestRequireAdmin(t *testing.T) {
+	jwtService := auth.NewJWTService("test-secret", time.Hour, 7*24*time.Hour)
+	chain := func(next http.Handler) http.Handler {
+		return AuthMiddleware(jwtService)(RequireAdmin([]string{" Admin@Example.com ", ""})(next))
+	}
+
+	tests := []struct {
+		email string
+		want  int
+	}{
+		{"admin@example.com", http.StatusOK},
+		{"ADMIN@example.com", http.StatusOK},
+		{"user@example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		token, err := jwtService.GenerateToken(&auth.User{ID: "user_1", Email: tt.email})
+		if err != nil {
+			t.Fatalf("GenerateToken failed: %v", err)
+		}
+		handler := chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.email, tt.want, rr.Code)
+		}
+	}
+}
+
 func TestRateLimiter_AllowsUnderLimit(t *testing.T) {
 	rl := NewRateLimiter(100, 200) // high limit for test
 
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// History actions
+const (
+	AuditCreated         = "created"
+	AuditUpdated         = "updated"
+	AuditDeleted         = "deleted"
+	AuditStatusChanged   = "status_changed"
+	AuditPaymentRecorded = "payment_recorded"
+	AuditCredited        = "credited"         // a credit note was issued against the invoice
+	AuditConverted       = "converted"        // the quote was converted to an invoice
+	AuditRevised         = "revised"          // a revision superseded the invoice
+	AuditLateFeeCharged  = "late_fee_charged" // the overdue job added late fees
+)
+
+// Actors for changes the server makes on its own.
+const (
+	ActorScheduler  = "system:scheduler"
+	ActorOverdueJob = "system:overdue"
+)
+
+// AuditEntry records one change to an invoice or quote. Entries are only ever
+// appended to the history, never changed.
+type AuditEntry struct {
+	ID         string        `json:"id"`
+	UserID     string        `json:"userId"` // owner of the invoice
+	InvoiceID  string        `json:"invoiceId"`
+	Action     string        `json:"action"`
+	Actor      string        `json:"actor"` // ID of the user who made the change, or a system actor
+	ActorEmail string        `json:"actorEmail,omitempty"`
+	At         time.Time     `json:"at"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange is one changed field, named by its JSON path, e.g.
+// "items[0].rate". Old is absent for added fields and New for removed ones.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// Clone returns a deep copy of the entry.
+func (e *AuditEntry) Clone() *AuditEntry {
+	c := *e
+	if e.Changes != nil {
+		c.Changes = make([]FieldChange, len(e.Changes))
+		for i, change := range e.Changes {
+			c.Changes[i] = FieldChange{
+				Field: change.Field,
+				Old:   cloneRaw(change.Old),
+				New:   cloneRaw(change.New),
+			}
+		}
+	}
+	return &c
+}
+
+// cloneRaw returns a copy of raw, keeping nil as nil.
+func cloneRaw(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	return append(json.RawMessage(nil), raw...)
+}
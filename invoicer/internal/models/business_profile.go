@@ -0,0 +1,98 @@
+package models
+
+import (
+	"invoice-generator/invoicer/internal/money"
+	"time"
+)
+
+// BankDetails tells the client where to send payment.
+type BankDetails struct {
+	BankName      string `json:"bankName,omitempty"`
+	AccountName   string `json:"accountName,omitempty"`
+	AccountNumber string `json:"accountNumber,omitempty"`
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"` // SWIFT/BIC code
+}
+
+// IsZero reports whether no bank details are set.
+func (b BankDetails) IsZero() bool {
+	return b == BankDetails{}
+}
+
+// Late fee kinds.
+const (
+	LateFeeFlat    = "flat"    // a fixed amount per overdue period
+	LateFeePercent = "percent" // a percentage of the balance due per overdue period
+)
+
+// LateFeePolicy configures the fees added to the business's overdue invoices.
+// Fees are charged once per overdue month, starting when the grace period
+// after the due date has passed.
+type LateFeePolicy struct {
+	Kind      string      `json:"kind"`                // LateFeeFlat or LateFeePercent
+	Amount    money.Money `json:"amount"`              // flat fee, in Currency
+	Currency  string      `json:"currency,omitempty"`  // currency of the flat fee
+	Rate      float64     `json:"rate,omitempty"`      // percentage of the balance due per month
+	GraceDays int         `json:"graceDays,omitempty"` // days after the due date before the first fee
+	MaxFees   int         `json:"maxFees,omitempty"`   // fees per invoice; 0 for no limit
+}
+
+// BusinessProfile is one of the user's trading entities. Invoices select a
+// profile by ID and copy its identity and bank details.
+type BusinessProfile struct {
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Identity
+	Name               string `json:"name"`
+	Email              string `json:"email"`
+	Phone              string `json:"phone"`
+	Address            string `json:"address"`
+	TaxID              string `json:"taxId,omitempty"` // VAT / GST / EIN number
+	RegistrationNumber string `json:"registrationNumber,omitempty"`
+
+	Bank BankDetails `json:"bank"`
+
+	// Invoice defaults
+	Currency string `json:"currency,omitempty"`
+
+	// Currency the business reports in; invoices in other currencies are
+	// stamped with the exchange rate to it when issued
+	BaseCurrency string `json:"baseCurrency,omitempty"`
+
+	// Late fees for overdue invoices; nil for none
+	LateFee *LateFeePolicy `json:"lateFee,omitempty"`
+}
+
+// Clone returns a copy of the profile.
+func (p *BusinessProfile) Clone() *BusinessProfile {
+	c := *p
+	if p.LateFee != nil {
+		policy := *p.LateFee
+		c.LateFee = &policy
+	}
+	return &c
+}
+
+// ApplyTo copies the profile's identity, bank details and base currency into
+// the invoice and uses its currency if the invoice has none.
+func (p *BusinessProfile) ApplyTo(invoice *Invoice) {
+	invoice.BusinessProfileID = p.ID
+	invoice.BusinessName = p.Name
+	invoice.BusinessEmail = p.Email
+	invoice.BusinessPhone = p.Phone
+	invoice.BusinessAddress = p.Address
+	invoice.BusinessTaxID = p.TaxID
+	invoice.BusinessRegistrationNumber = p.RegistrationNumber
+	invoice.BusinessBank = p.Bank
+	if p.BaseCurrency != "" {
+		invoice.BaseCurrency = p.BaseCurrency
+	}
+
+	if invoice.Currency == "" {
+		invoice.Currency = p.Currency
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"invoice-generator/invoicer/internal/money"
+	"time"
+)
+
+// CatalogItem is a product or service in the user's catalog. Line items
+// reference an item by ID and take its details as defaults.
+type CatalogItem struct {
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name        string      `json:"name"`
+	Description string      `json:"description"` // printed on the invoice; defaults to the name
+	SKU         string      `json:"sku,omitempty"`
+	Unit        string      `json:"unit,omitempty"`     // e.g. "hour", "day", "each"
+	Rate        money.Money `json:"rate"`               // in Currency
+	Currency    string      `json:"currency,omitempty"` // required with a rate; only filled on invoices in it
+	TaxRate     float64     `json:"taxRate"`
+	Taxes       []Tax       `json:"taxes,omitempty"` // named taxes; take precedence over TaxRate
+
+	DiscountTiers []DiscountTier `json:"discountTiers,omitempty"` // volume discounts
+}
+
+// Clone returns a copy of the catalog item.
+func (c *CatalogItem) Clone() *CatalogItem {
+	cc := *c
+	cc.Taxes = cloneTaxes(c.Taxes)
+	cc.DiscountTiers = cloneTiers(c.DiscountTiers)
+	return &cc
+}
+
+// ApplyTo fills the line item's empty or zero description, unit, rate and
+// taxes from the catalog item. The caller checks that the item's rate is in
+// the invoice's currency. A line that sets either a tax rate or named
+// taxes keeps its own, and the item's volume tiers only apply to lines
+// without a discount.
+func (c *CatalogItem) ApplyTo(item *LineItem) {
+	item.CatalogItemID = c.ID
+
+	if item.Description == "" {
+		item.Description = c.Description
+		if item.Description == "" {
+			item.Description = c.Name
+		}
+	}
+	if item.Unit == "" {
+		item.Unit = c.Unit
+	}
+	if item.Rate.IsZero() {
+		item.Rate = c.Rate
+	}
+	if item.TaxRate == 0 && len(item.Taxes) == 0 {
+		item.TaxRate = c.TaxRate
+		item.Taxes = cloneTaxes(c.Taxes)
+	}
+	if !item.HasDiscount() {
+		item.DiscountTiers = cloneTiers(c.DiscountTiers)
+	}
+}
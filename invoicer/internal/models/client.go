@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Client is an entry in the user's client directory. Invoices reference a
+// client by ID and copy its contact details; the remaining fields are
+// defaults for invoices that leave them empty.
+type Client struct {
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Contact details
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+
+	// Invoice defaults
+	Currency     string  `json:"currency,omitempty"`
+	PaymentTerms string  `json:"paymentTerms,omitempty"` // e.g. "net_30"; see package terms
+	TaxRate      float64 `json:"taxRate,omitempty"`
+	Taxes        []Tax   `json:"taxes,omitempty"` // named invoice-level taxes; take precedence over TaxRate
+	DiscountRate float64 `json:"discountRate,omitempty"`
+	Template     string  `json:"template,omitempty"` // "minimal", "corporate", or "modern"
+}
+
+// Clone returns a copy of the client.
+func (c *Client) Clone() *Client {
+	cc := *c
+	cc.Taxes = cloneTaxes(c.Taxes)
+	return &cc
+}
+
+// ApplyTo copies the client's contact details into the invoice and fills
+// empty or zero invoice fields with the client's defaults.
+func (c *Client) ApplyTo(invoice *Invoice) {
+	invoice.ClientID = c.ID
+	invoice.ClientName = c.Name
+	invoice.ClientEmail = c.Email
+	invoice.ClientAddress = c.Address
+
+	if invoice.Currency == "" {
+		invoice.Currency = c.Currency
+	}
+	if invoice.TaxRate == 0 && len(invoice.Taxes) == 0 {
+		invoice.TaxRate = c.TaxRate
+		invoice.Taxes = cloneTaxes(c.Taxes)
+	}
+	if invoice.DiscountRate == 0 && invoice.Discount == nil && invoice.PromoCode == "" {
+		invoice.DiscountRate = c.DiscountRate
+	}
+	if invoice.SelectedTemplate == "" {
+		invoice.SelectedTemplate = c.Template
+	}
+	if invoice.PaymentTerms == "" && invoice.DueDate.IsZero() {
+		invoice.PaymentTerms = c.PaymentTerms
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"testing"
+)
+
+func TestClientApplyTo(t *testing.T) {
+	client := &Client{
+		ID:           "cli_1",
+		Name:         "Globex",
+		Email:        "ap@globex.example",
+		Address:      "1 Globex Way",
+		Currency:     "EUR",
+		PaymentTerms: "net_30",
+		TaxRate:      20,
+		Template:     "corporate",
+	}
+
+	invoice := &Invoice{ClientName: "typo", InvoiceDate: civil.MustParse("2026-01-15"), SelectedTemplate: "modern"}
+	client.ApplyTo(invoice)
+
+	if invoice.ClientName != "Globex" || invoice.ClientEmail != "ap@globex.example" || invoice.ClientAddress != "1 Globex Way" {
+		t.Errorf("expected contact details from the directory, got %q %q %q", invoice.ClientName, invoice.ClientEmail, invoice.ClientAddress)
+	}
+	if invoice.Currency != "EUR" || invoice.TaxRate != 20 || invoice.PaymentTerms != "net_30" {
+		t.Errorf("expected defaults to fill empty fields, got %q %v %q", invoice.Currency, invoice.TaxRate, invoice.PaymentTerms)
+	}
+
+	withDueDate := &Invoice{DueDate: civil.MustParse("2026-01-20")}
+	client.ApplyTo(withDueDate)
+	if withDueDate.PaymentTerms != "" {
+		t.Errorf("expected an explicit due date to keep the invoice without terms, got %q", withDueDate.PaymentTerms)
+	}
+	if invoice.SelectedTemplate != "modern" {
+		t.Errorf("expected explicit template to be kept, got %q", invoice.SelectedTemplate)
+	}
+}
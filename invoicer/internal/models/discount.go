@@ -0,0 +1,120 @@
+package models
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/money"
+	"time"
+)
+
+// Discount kinds
+const (
+	DiscountPercent = "percent" // a percentage of the amount
+	DiscountFixed   = "fixed"   // a fixed amount in the invoice's currency
+)
+
+// Discount is a discount on a line item or the whole invoice. It is taken off
+// before tax unless AfterTax is set.
+type Discount struct {
+	Label    string      `json:"label,omitempty"` // printed on the PDF, e.g. "Loyalty discount"
+	Kind     string      `json:"kind"`            // DiscountPercent or DiscountFixed
+	Rate     float64     `json:"rate,omitempty"`  // percentage, for percent discounts
+	Amount   money.Money `json:"amount"`          // for fixed discounts
+	AfterTax bool        `json:"afterTax,omitempty"`
+}
+
+// DiscountTier is a volume discount: Rate percent off a line of at least
+// MinQuantity units.
+type DiscountTier struct {
+	MinQuantity float64 `json:"minQuantity"`
+	Rate        float64 `json:"rate"`
+}
+
+// PromoCode is a reusable code that applies its discount to an invoice.
+type PromoCode struct {
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Code     string   `json:"code"` // stored in upper case; matched ignoring case
+	Discount Discount `json:"discount"`
+	Currency string   `json:"currency,omitempty"` // currency of a fixed discount's amount
+
+	ExpiresOn civil.Date `json:"expiresOn"` // last day the code can be used; empty for no expiry
+	MaxUses   int        `json:"maxUses"`   // 0 for unlimited
+	Uses      int        `json:"uses"`      // invoices saved with the code (managed by the server)
+}
+
+// Clone returns a copy of the promo code.
+func (p *PromoCode) Clone() *PromoCode {
+	c := *p
+	return &c
+}
+
+// volumeDiscountLabel labels discounts taken from a volume tier.
+const volumeDiscountLabel = "Volume discount"
+
+// AppliedDiscount returns the line's discount: its Discount, a percentage
+// discount at DiscountRate, or the highest volume tier its quantity reaches,
+// in that order. It returns nil for lines without a discount.
+func (item LineItem) AppliedDiscount() *Discount {
+	if item.Discount != nil {
+		return item.Discount
+	}
+	if item.DiscountRate != 0 {
+		return &Discount{Kind: DiscountPercent, Rate: item.DiscountRate}
+	}
+
+	quantity := item.Quantity
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	var tier *DiscountTier
+	for i, t := range item.DiscountTiers {
+		if quantity >= t.MinQuantity && (tier == nil || t.MinQuantity > tier.MinQuantity) {
+			tier = &item.DiscountTiers[i]
+		}
+	}
+	if tier == nil {
+		return nil
+	}
+	return &Discount{Label: volumeDiscountLabel, Kind: DiscountPercent, Rate: tier.Rate}
+}
+
+// AppliedDiscount returns the invoice-level discount: its Discount or, if it
+// has none, a percentage discount at DiscountRate. It returns nil for
+// invoices without a discount.
+func (inv *Invoice) AppliedDiscount() *Discount {
+	if inv.Discount != nil {
+		return inv.Discount
+	}
+	if inv.DiscountRate != 0 {
+		return &Discount{Kind: DiscountPercent, Rate: inv.DiscountRate}
+	}
+	return nil
+}
+
+// HasDiscount reports whether the line sets any discount of its own.
+func (item LineItem) HasDiscount() bool {
+	return item.Discount != nil || item.DiscountRate != 0 || len(item.DiscountTiers) > 0
+}
+
+// cloneDiscount returns a copy of d, keeping nil as nil.
+func cloneDiscount(d *Discount) *Discount {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	return &c
+}
+
+// cloneTiers returns a copy of tiers, keeping nil as nil.
+func cloneTiers(tiers []DiscountTier) []DiscountTier {
+	if tiers == nil {
+		return nil
+	}
+	c := make([]DiscountTier, len(tiers))
+	copy(c, tiers)
+	return c
+}
@@ -0,0 +1,21 @@
+package models
+
+import "invoice-generator/invoicer/internal/civil"
+
+// ExchangeRates are one day's exchange rates against a base currency, as a
+// central bank publishes them: 1 Base buys Rates[code] units of code.
+type ExchangeRates struct {
+	Date  civil.Date         `json:"date"`
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Clone returns a deep copy of the rates.
+func (r *ExchangeRates) Clone() *ExchangeRates {
+	c := *r
+	c.Rates = make(map[string]float64, len(r.Rates))
+	for code, rate := range r.Rates {
+		c.Rates[code] = rate
+	}
+	return &c
+}
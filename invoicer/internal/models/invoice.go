@@ -1,29 +1,155 @@
 package models
 
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/money"
+	"time"
+)
+
 // LineItem represents a single line item in the invoice
 type LineItem struct {
-	Description  string  `json:"description"`
-	Quantity     float64 `json:"quantity"`
-	Rate         float64 `json:"rate"`
-	TaxRate      float64 `json:"taxRate"`
-	DiscountRate float64 `json:"discountRate"`
-	Amount       float64 `json:"amount"`
+	CatalogItemID string         `json:"catalogItemId,omitempty"` // saved catalog item the line was filled from
+	Description   string         `json:"description"`
+	Unit          string         `json:"unit,omitempty"` // e.g. "hour", "day", "each"
+	Quantity      float64        `json:"quantity"`
+	Rate          money.Money    `json:"rate"`                    // unit rate; may have more decimal places than the currency
+	TaxRate       float64        `json:"taxRate"`                 // single unnamed tax; ignored when Taxes is set
+	Taxes         []Tax          `json:"taxes,omitempty"`         // named taxes, charged in order
+	DiscountRate  float64        `json:"discountRate"`            // percentage; ignored when Discount is set
+	Discount      *Discount      `json:"discount,omitempty"`      // percentage or fixed discount, before or after tax
+	DiscountTiers []DiscountTier `json:"discountTiers,omitempty"` // volume discounts, used when the line sets no other discount
+	Amount        money.Money    `json:"amount"`
+}
+
+// Payment records money received against an invoice.
+type Payment struct {
+	ID        string      `json:"id"`
+	Amount    money.Money `json:"amount"`
+	Date      civil.Date  `json:"date"`
+	Method    string      `json:"method"` // e.g. "bank_transfer", "card", "cash", "cheque"
+	Reference string      `json:"reference"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// CreditNoteRef records a credit note issued against an invoice.
+type CreditNoteRef struct {
+	ID        string         `json:"id"`
+	Number    string         `json:"number"`
+	Amount    money.Money    `json:"amount"` // amount credited, as a positive value
+	Lines     []CreditedLine `json:"lines,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// CreditedLine is the quantity of one line of an invoice that a credit note credits.
+type CreditedLine struct {
+	Index    int     `json:"index"` // position in the invoice's items
+	Quantity float64 `json:"quantity"`
+}
+
+// LateFee records a late fee charged on an overdue invoice. Fees are charged
+// after the invoice is issued, so they are kept beside its frozen content and
+// added to the balance due rather than to the total.
+type LateFee struct {
+	Period      civil.Date  `json:"period"` // first day of the overdue period the fee covers
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+// DocumentType distinguishes invoices from other billing documents.
+type DocumentType string
+
+const (
+	DocumentInvoice    DocumentType = "invoice"
+	DocumentCreditNote DocumentType = "credit_note"
+	DocumentQuote      DocumentType = "quote"
+)
+
+// InvoiceStatus is a stage in the invoice lifecycle.
+type InvoiceStatus string
+
+const (
+	StatusDraft         InvoiceStatus = "draft"
+	StatusIssued        InvoiceStatus = "issued"
+	StatusSent          InvoiceStatus = "sent"
+	StatusViewed        InvoiceStatus = "viewed"
+	StatusPartiallyPaid InvoiceStatus = "partially_paid"
+	StatusPaid          InvoiceStatus = "paid"
+	StatusOverdue       InvoiceStatus = "overdue"
+	StatusVoid          InvoiceStatus = "void"
+
+	// Quote outcomes
+	StatusAccepted InvoiceStatus = "accepted"
+	StatusDeclined InvoiceStatus = "declined"
+)
+
+// StatusChange records when an invoice entered a status.
+type StatusChange struct {
+	Status InvoiceStatus `json:"status"`
+	At     time.Time     `json:"at"`
 }
 
 // Invoice represents the complete invoice data
 type Invoice struct {
-	// Invoice details
-	InvoiceNumber string `json:"invoiceNumber"`
-	InvoiceDate   string `json:"invoiceDate"`
-	DueDate       string `json:"dueDate"`
-
-	// Business information
-	BusinessName    string `json:"businessName"`
-	BusinessEmail   string `json:"businessEmail"`
-	BusinessPhone   string `json:"businessPhone"`
-	BusinessAddress string `json:"businessAddress"`
-
-	// Client information
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Document type; credit notes reference the invoice they credit
+	DocumentType          DocumentType   `json:"documentType,omitempty"`
+	OriginalInvoiceID     string         `json:"originalInvoiceId,omitempty"`
+	OriginalInvoiceNumber string         `json:"originalInvoiceNumber,omitempty"`
+	CreditedLines         []CreditedLine `json:"creditedLines,omitempty"` // lines of the original credited, one per item
+
+	// Quotes: the last day the quote can be accepted, and the invoice it was
+	// converted into. Invoices converted from a quote link back to it.
+	ValidUntil         civil.Date `json:"validUntil"`
+	ConvertedInvoiceID string     `json:"convertedInvoiceId,omitempty"`
+	QuoteID            string     `json:"quoteId,omitempty"`
+	QuoteNumber        string     `json:"quoteNumber,omitempty"`
+
+	// Recurring schedule that generated the invoice, and the occurrence date it bills
+	RecurringID     string `json:"recurringId,omitempty"`
+	RecurringPeriod string `json:"recurringPeriod,omitempty"`
+
+	// Revisions: a correction of an issued invoice links back to the revision
+	// it replaces, which links forward to it (managed by the server)
+	Revision               int    `json:"revision,omitempty"` // 0 for the original, 1 for the first correction
+	PreviousRevisionID     string `json:"previousRevisionId,omitempty"`
+	PreviousRevisionNumber string `json:"previousRevisionNumber,omitempty"`
+	SupersededByID         string `json:"supersededById,omitempty"`
+	SupersededByNumber     string `json:"supersededByNumber,omitempty"`
+
+	// Lifecycle (managed by the server)
+	Status        InvoiceStatus  `json:"status,omitempty"`
+	StatusHistory []StatusChange `json:"statusHistory,omitempty"`
+
+	// SHA-256 hashes of the snapshot and PDF frozen when the document was
+	// issued (managed by the server)
+	DocumentSHA256 string `json:"documentSha256,omitempty"`
+	PDFSHA256      string `json:"pdfSha256,omitempty"`
+
+	// Invoice details. With PaymentTerms set, the server derives DueDate from
+	// InvoiceDate.
+	InvoiceNumber string     `json:"invoiceNumber"`
+	InvoiceDate   civil.Date `json:"invoiceDate"`
+	DueDate       civil.Date `json:"dueDate"`
+	PaymentTerms  string     `json:"paymentTerms,omitempty"` // e.g. "net_30", "eom", "2/10_net_30"
+
+	// Business information; with BusinessProfileID set, the server fills these from the profile
+	BusinessProfileID          string      `json:"businessProfileId,omitempty"`
+	BusinessName               string      `json:"businessName"`
+	BusinessEmail              string      `json:"businessEmail"`
+	BusinessPhone              string      `json:"businessPhone"`
+	BusinessAddress            string      `json:"businessAddress"`
+	BusinessTaxID              string      `json:"businessTaxId,omitempty"`
+	BusinessRegistrationNumber string      `json:"businessRegistrationNumber,omitempty"`
+	BusinessBank               BankDetails `json:"businessBank"`
+
+	// Client information; with ClientID set, the server fills these from the client directory
+	ClientID      string `json:"clientId,omitempty"`
 	ClientName    string `json:"clientName"`
 	ClientEmail   string `json:"clientEmail"`
 	ClientAddress string `json:"clientAddress"`
@@ -32,15 +158,142 @@ type Invoice struct {
 	Items []LineItem `json:"items"`
 
 	// Totals
-	Subtotal       float64 `json:"subtotal"`
-	DiscountRate   float64 `json:"discountRate"`
-	DiscountAmount float64 `json:"discountAmount"`
-	TaxRate        float64 `json:"taxRate"`
-	TaxAmount      float64 `json:"taxAmount"`
-	Total          float64 `json:"total"`
+	Subtotal       money.Money `json:"subtotal"`
+	DiscountRate   float64     `json:"discountRate"` // percentage; ignored when Discount is set
+	Discount       *Discount   `json:"discount,omitempty"`
+	PromoCode      string      `json:"promoCode,omitempty"` // the server sets Discount from the promo code
+	DiscountAmount money.Money `json:"discountAmount"`
+	TaxRate        float64     `json:"taxRate"`                // single unnamed tax; ignored when Taxes is set
+	Taxes          []Tax       `json:"taxes,omitempty"`        // named invoice-level taxes, charged in order
+	TaxInclusive   bool        `json:"taxInclusive,omitempty"` // rates already include tax; taxes are backed out
+	TaxAmount      money.Money `json:"taxAmount"`
+	CashRounding   bool        `json:"cashRounding,omitempty"` // round the total to the currency's cash increment
+	Rounding       money.Money `json:"rounding"`               // added to the total by cash rounding (computed by the server)
+	Total          money.Money `json:"total"`
+
+	// Promo codes whose use has been counted for this document, so that
+	// switching back to one does not count it again (managed by the server)
+	RedeemedPromoCodes []string `json:"redeemedPromoCodes,omitempty"`
+
+	// Taxable base and tax per tax name and rate, across lines and invoice (computed by the server)
+	TaxSummary []TaxLine `json:"taxSummary,omitempty"`
+
+	// Payments, credits and late fees, recorded after the invoice is issued
+	Payments       []Payment       `json:"payments,omitempty"`
+	AmountPaid     money.Money     `json:"amountPaid"`
+	CreditNotes    []CreditNoteRef `json:"creditNotes,omitempty"`
+	CreditedAmount money.Money     `json:"creditedAmount"`
+	LateFees       []LateFee       `json:"lateFees,omitempty"` // charged while overdue (managed by the server)
+	LateFeeAmount  money.Money     `json:"lateFeeAmount"`
+	BalanceDue     money.Money     `json:"balanceDue"` // total + late fees − amount paid − credited amount
+
+	// Base currency the business reports in; the server stamps the exchange
+	// rate to it when the invoice is issued
+	BaseCurrency     string     `json:"baseCurrency,omitempty"`
+	ExchangeRate     float64    `json:"exchangeRate,omitempty"`     // baseCurrency units per unit of currency
+	ExchangeRateDate civil.Date `json:"exchangeRateDate"`           // date of the rates used
+	ShowBaseCurrency bool       `json:"showBaseCurrency,omitempty"` // print base-currency equivalents in the PDF footer
 
 	// Additional
 	Currency         string `json:"currency"`
 	Notes            string `json:"notes"`
 	SelectedTemplate string `json:"selectedTemplate"` // "minimal", "corporate", or "modern"
 }
+
+// IsCreditNote reports whether the document is a credit note.
+func (inv *Invoice) IsCreditNote() bool {
+	return inv.DocumentType == DocumentCreditNote
+}
+
+// IsQuote reports whether the document is a quote (estimate).
+func (inv *Invoice) IsQuote() bool {
+	return inv.DocumentType == DocumentQuote
+}
+
+// Clone returns a deep copy of the invoice so callers can modify it
+// without affecting the stored original.
+func (inv *Invoice) Clone() *Invoice {
+	c := *inv
+	if inv.Items != nil {
+		c.Items = make([]LineItem, len(inv.Items))
+		for i, item := range inv.Items {
+			item.Taxes = cloneTaxes(item.Taxes)
+			item.Discount = cloneDiscount(item.Discount)
+			item.DiscountTiers = cloneTiers(item.DiscountTiers)
+			c.Items[i] = item
+		}
+	}
+	c.Taxes = cloneTaxes(inv.Taxes)
+	c.Discount = cloneDiscount(inv.Discount)
+	if inv.TaxSummary != nil {
+		c.TaxSummary = make([]TaxLine, len(inv.TaxSummary))
+		copy(c.TaxSummary, inv.TaxSummary)
+	}
+	if inv.Payments != nil {
+		c.Payments = make([]Payment, len(inv.Payments))
+		copy(c.Payments, inv.Payments)
+	}
+	if inv.RedeemedPromoCodes != nil {
+		c.RedeemedPromoCodes = append([]string(nil), inv.RedeemedPromoCodes...)
+	}
+	if inv.CreditedLines != nil {
+		c.CreditedLines = make([]CreditedLine, len(inv.CreditedLines))
+		copy(c.CreditedLines, inv.CreditedLines)
+	}
+	if inv.CreditNotes != nil {
+		c.CreditNotes = make([]CreditNoteRef, len(inv.CreditNotes))
+		for i, ref := range inv.CreditNotes {
+			if ref.Lines != nil {
+				ref.Lines = append([]CreditedLine(nil), ref.Lines...)
+			}
+			c.CreditNotes[i] = ref
+		}
+	}
+	if inv.LateFees != nil {
+		c.LateFees = make([]LateFee, len(inv.LateFees))
+		copy(c.LateFees, inv.LateFees)
+	}
+	if inv.StatusHistory != nil {
+		c.StatusHistory = make([]StatusChange, len(inv.StatusHistory))
+		copy(c.StatusHistory, inv.StatusHistory)
+	}
+	return &c
+}
+
+// StampCurrency stamps every monetary field with the invoice's currency.
+// Amounts decoded from JSON carry no currency until this is called. Unit rates
+// are left as they were written, at full precision: a rate of 0.125 is only
+// rounded once multiplied by its quantity.
+func (inv *Invoice) StampCurrency() {
+	for i := range inv.Items {
+		inv.Items[i].Amount = inv.Items[i].Amount.WithCurrency(inv.Currency)
+		if d := inv.Items[i].Discount; d != nil {
+			d.Amount = d.Amount.WithCurrency(inv.Currency)
+		}
+	}
+	if inv.Discount != nil {
+		inv.Discount.Amount = inv.Discount.Amount.WithCurrency(inv.Currency)
+	}
+	inv.Subtotal = inv.Subtotal.WithCurrency(inv.Currency)
+	inv.DiscountAmount = inv.DiscountAmount.WithCurrency(inv.Currency)
+	inv.TaxAmount = inv.TaxAmount.WithCurrency(inv.Currency)
+	for i := range inv.TaxSummary {
+		inv.TaxSummary[i].Base = inv.TaxSummary[i].Base.WithCurrency(inv.Currency)
+		inv.TaxSummary[i].Amount = inv.TaxSummary[i].Amount.WithCurrency(inv.Currency)
+	}
+	inv.Rounding = inv.Rounding.WithCurrency(inv.Currency)
+	inv.Total = inv.Total.WithCurrency(inv.Currency)
+	for i := range inv.Payments {
+		inv.Payments[i].Amount = inv.Payments[i].Amount.WithCurrency(inv.Currency)
+	}
+	inv.AmountPaid = inv.AmountPaid.WithCurrency(inv.Currency)
+	for i := range inv.CreditNotes {
+		inv.CreditNotes[i].Amount = inv.CreditNotes[i].Amount.WithCurrency(inv.Currency)
+	}
+	inv.CreditedAmount = inv.CreditedAmount.WithCurrency(inv.Currency)
+	for i := range inv.LateFees {
+		inv.LateFees[i].Amount = inv.LateFees[i].Amount.WithCurrency(inv.Currency)
+	}
+	inv.LateFeeAmount = inv.LateFeeAmount.WithCurrency(inv.Currency)
+	inv.BalanceDue = inv.BalanceDue.WithCurrency(inv.Currency)
+}
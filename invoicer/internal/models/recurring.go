@@ -0,0 +1,53 @@
+package models
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"time"
+)
+
+// Cadence is how often a recurring schedule produces an invoice.
+type Cadence string
+
+const (
+	CadenceWeekly    Cadence = "weekly"
+	CadenceMonthly   Cadence = "monthly"
+	CadenceQuarterly Cadence = "quarterly"
+	CadenceCron      Cadence = "cron" // uses RecurringSchedule.Cron
+)
+
+// RecurringSchedule generates invoices from a base invoice at a fixed cadence.
+type RecurringSchedule struct {
+	// Persistence metadata (assigned by the server)
+	ID        string    `json:"id,omitempty"`
+	UserID    string    `json:"userId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name string `json:"name"`
+
+	// Base invoice copied for every occurrence. Its invoice number is ignored;
+	// each generated invoice gets the next number from the user's sequence.
+	Template Invoice `json:"template"`
+
+	// Timing
+	Cadence        Cadence    `json:"cadence"`
+	Cron           string     `json:"cron,omitempty"`           // standard 5-field expression, for CadenceCron
+	StartDate      civil.Date `json:"startDate"`                // the first occurrence
+	EndDate        civil.Date `json:"endDate"`                  // last possible occurrence (inclusive); empty for none
+	MaxOccurrences int        `json:"maxOccurrences,omitempty"` // 0 means unlimited
+	AutoIssue      bool       `json:"autoIssue"`                // issue generated invoices instead of leaving drafts
+	Paused         bool       `json:"paused"`
+
+	// Progress (managed by the server)
+	Occurrences int        `json:"occurrences"` // invoices generated so far
+	Skipped     int        `json:"skipped"`     // occurrences passed over while paused
+	LastRun     civil.Date `json:"lastRun"`     // date of the last generated occurrence
+	NextRun     civil.Date `json:"nextRun"`     // date of the next occurrence; empty once finished
+}
+
+// Clone returns a deep copy of the schedule.
+func (s *RecurringSchedule) Clone() *RecurringSchedule {
+	c := *s
+	c.Template = *s.Template.Clone()
+	return &c
+}
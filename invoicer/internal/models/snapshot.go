@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// InvoiceSnapshot is an invoice frozen when it was issued: its canonical JSON
+// and the exact PDF sent to the client, each with its SHA-256 hash in hex.
+type InvoiceSnapshot struct {
+	InvoiceID      string    `json:"invoiceId"`
+	UserID         string    `json:"userId"`
+	FrozenAt       time.Time `json:"frozenAt"`
+	Document       []byte    `json:"-"`
+	DocumentSHA256 string    `json:"documentSha256"`
+	PDF            []byte    `json:"-"`
+	PDFSHA256      string    `json:"pdfSha256"`
+}
+
+// Clone returns a deep copy of the snapshot.
+func (s *InvoiceSnapshot) Clone() *InvoiceSnapshot {
+	c := *s
+	c.Document = append([]byte(nil), s.Document...)
+	c.PDF = append([]byte(nil), s.PDF...)
+	return &c
+}
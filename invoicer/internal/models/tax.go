@@ -0,0 +1,56 @@
+package models
+
+import "invoice-generator/invoicer/internal/money"
+
+// Tax is a named tax charged on a line item or on the whole invoice, such as
+// VAT, or CGST and SGST together.
+type Tax struct {
+	Name     string  `json:"name"`               // e.g. "VAT", "CGST", "Eco levy"
+	Rate     float64 `json:"rate"`               // percentage
+	Compound bool    `json:"compound,omitempty"` // charged on the amount plus the taxes listed before it
+}
+
+// TaxLine is one row of the tax summary: a tax, the amount it was charged on
+// and the tax charged.
+type TaxLine struct {
+	Name     string      `json:"name"`
+	Rate     float64     `json:"rate"`
+	Compound bool        `json:"compound,omitempty"`
+	Base     money.Money `json:"base"`   // taxable amount
+	Amount   money.Money `json:"amount"` // tax charged
+}
+
+// legacyTaxName names the tax of lines and invoices that only set TaxRate.
+const legacyTaxName = "Tax"
+
+// AppliedTaxes returns the taxes charged on the line: its named taxes or, if
+// it has none, a single tax at TaxRate.
+func (item LineItem) AppliedTaxes() []Tax {
+	return appliedTaxes(item.Taxes, item.TaxRate)
+}
+
+// AppliedTaxes returns the invoice-level taxes: the named taxes or, if there
+// are none, a single tax at TaxRate.
+func (inv *Invoice) AppliedTaxes() []Tax {
+	return appliedTaxes(inv.Taxes, inv.TaxRate)
+}
+
+func appliedTaxes(taxes []Tax, rate float64) []Tax {
+	if len(taxes) > 0 {
+		return taxes
+	}
+	if rate != 0 {
+		return []Tax{{Name: legacyTaxName, Rate: rate}}
+	}
+	return nil
+}
+
+// cloneTaxes returns a copy of taxes, keeping nil as nil.
+func cloneTaxes(taxes []Tax) []Tax {
+	if taxes == nil {
+		return nil
+	}
+	c := make([]Tax, len(taxes))
+	copy(c, taxes)
+	return c
+}
@@ -0,0 +1,433 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"invoice-generator/invoicer/internal/currency"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is a fixed-point monetary amount stored as an integer number of minor
+// units (e.g. cents) together with its ISO 4217 currency code.
+//
+// In JSON a Money is a plain number such as 212.4 or "212.40", so it is
+// wire-compatible with the float64 amounts it replaces. The currency is not
+// part of the JSON value; decoded amounts are unstamped until WithCurrency is
+// called with the currency of the document they belong to.
+//
+// An amount has the currency's number of decimal places (see package
+// currency). Unstamped amounts have two, or up to MaxPlaces when they were
+// written with more, so a unit rate of 0.125 keeps its precision until it is
+// multiplied out with Extend or stamped with a currency.
+//
+// Amounts are limited to the range of an int64 number of minor units. A
+// calculation that leaves it saturates at the limit and marks the result, and
+// every amount computed from it, as Overflowed.
+type Money struct {
+	minor    int64
+	currency string
+	places   int  // decimal places of an unstamped amount that needs more than two; 0 otherwise
+	overflow bool // the amount, or one it was computed from, was out of range
+}
+
+// MaxPlaces is the largest number of decimal places an unstamped amount keeps.
+const MaxPlaces = 9
+
+// New returns an amount of minor units in the given currency.
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// FromFloat converts a major-unit float (e.g. 12.34) to Money, rounding half
+// away from zero to the currency's minor units.
+func FromFloat(v float64, currency string) Money {
+	m, err := Parse(strconv.FormatFloat(v, 'f', -1, 64), currency)
+	if err != nil {
+		return Money{currency: currency}
+	}
+	return m
+}
+
+// Parse parses a decimal string in major units (e.g. "12.34") exactly, rounding
+// half away from zero to the currency's minor units. Without a currency the
+// amount keeps as many decimals as it was written with, up to MaxPlaces.
+func Parse(s, code string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	exp, places := exponent(code), 0
+	if code == "" {
+		for exp < MaxPlaces && !new(big.Rat).Mul(r, scaleRat(exp)).IsInt() {
+			exp++
+		}
+		if exp > currency.DefaultMinorUnits {
+			places = exp
+		}
+	}
+	minor := roundBig(r.Mul(r, scaleRat(exp)))
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("amount %q out of range", s)
+	}
+	return Money{minor: minor.Int64(), currency: code, places: places}, nil
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 { return m.minor }
+
+// Currency returns the ISO 4217 currency code, or "" for an unstamped amount.
+func (m Money) Currency() string { return m.currency }
+
+// WithCurrency stamps the amount with a currency, rescaling the minor units if
+// the currency has a different number of decimal places. Stamping an amount
+// with "" leaves it as it is.
+func (m Money) WithCurrency(code string) Money {
+	if code == "" {
+		return m
+	}
+	return m.rescale(exponent(code), code)
+}
+
+// FitsIn reports whether the amount can be stamped with a currency without
+// rounding, i.e. it has no more decimal places than the currency uses.
+func (m Money) FitsIn(code string) bool {
+	return m.rescale(exponent(code), code).rescale(m.exponent(), m.currency).minor == m.minor
+}
+
+// Add returns m + o. Amounts are expected to share a currency; the result
+// keeps m's currency, or o's if m is unstamped.
+func (m Money) Add(o Money) Money {
+	m, o = align(m, o)
+	sum := m.withBig(new(big.Int).Add(big.NewInt(m.minor), big.NewInt(o.minor)))
+	sum.overflow = sum.overflow || o.overflow
+	return sum
+}
+
+// Sub returns m − o.
+func (m Money) Sub(o Money) Money {
+	m, o = align(m, o)
+	diff := m.withBig(new(big.Int).Sub(big.NewInt(m.minor), big.NewInt(o.minor)))
+	diff.overflow = diff.overflow || o.overflow
+	return diff
+}
+
+// Neg returns −m.
+func (m Money) Neg() Money {
+	return m.withBig(new(big.Int).Neg(big.NewInt(m.minor)))
+}
+
+// Abs returns |m|.
+func (m Money) Abs() Money {
+	if m.minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul multiplies by a (possibly fractional) factor such as a quantity and
+// rounds half away from zero to minor units. The factor is taken at its
+// shortest decimal representation, so Mul(0.35) multiplies by exactly 0.35.
+func (m Money) Mul(factor float64) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return m.with(0)
+	}
+	r := new(big.Rat).SetInt64(m.minor)
+	return m.withBig(roundBig(r.Mul(r, f)))
+}
+
+// MulRat multiplies by an exact ratio, such as one amount's share of another,
+// and rounds once, half away from zero, to minor units.
+func (m Money) MulRat(f *big.Rat) Money {
+	r := new(big.Rat).SetInt64(m.minor)
+	return m.withBig(roundBig(r.Mul(r, f)))
+}
+
+// Extend returns quantity × m in the given currency, where m is a unit rate
+// that may have more decimal places than the currency uses. The product is
+// computed exactly and rounded once, half away from zero, to the currency's
+// minor units, so 1000 × 0.125 is exactly 125.00. The quantity is taken at its
+// shortest decimal representation.
+func (m Money) Extend(quantity float64, code string) Money {
+	return m.Convert(quantity, code)
+}
+
+// Percent returns rate percent of m (Percent(18) is 18%), rounded to minor units.
+func (m Money) Percent(rate float64) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return m.with(0)
+	}
+	r := new(big.Rat).SetInt64(m.minor)
+	r.Mul(r, f)
+	r.Quo(r, big.NewRat(100, 1))
+	return m.withBig(roundBig(r))
+}
+
+// WithoutPercent returns the amount that, with rate percent added, makes m:
+// WithoutPercent(20) of 120.00 is 100.00. It is used to back tax out of a
+// tax-inclusive price, and rounds half away from zero to minor units.
+func (m Money) WithoutPercent(rate float64) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return m.with(0)
+	}
+	r := new(big.Rat).SetInt64(m.minor)
+	r.Mul(r, big.NewRat(100, 1))
+	r.Quo(r, f.Add(f, big.NewRat(100, 1)))
+	return m.withBig(roundBig(r))
+}
+
+// Convert converts the amount to another currency at rate units of that
+// currency per unit of m's, rounding once, half away from zero, to its minor
+// units. The rate is taken at its shortest decimal representation.
+func (m Money) Convert(rate float64, code string) Money {
+	f, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return Money{currency: code}
+	}
+	r := new(big.Rat).SetInt64(m.minor)
+	r.Mul(r, f)
+	r.Mul(r, scaleRat(exponent(code)))
+	r.Quo(r, scaleRat(m.exponent()))
+	return Money{currency: code, overflow: m.overflow}.withBig(roundBig(r))
+}
+
+// RoundTo rounds the amount half away from zero to a multiple of increment
+// minor units, such as a currency's cash increment. Increments below 2 leave
+// the amount unchanged.
+func (m Money) RoundTo(increment int64) Money {
+	if increment < 2 {
+		return m
+	}
+	q := roundBig(big.NewRat(m.minor, increment))
+	return m.withBig(q.Mul(q, big.NewInt(increment)))
+}
+
+// RoundToCash rounds the amount to the smallest amount that can be paid in
+// cash in its currency, e.g. to a multiple of 0.05 for CHF.
+func (m Money) RoundToCash() Money {
+	c, _ := currency.Lookup(m.currency)
+	return m.RoundTo(c.CashIncrement)
+}
+
+// Cmp compares two amounts: -1 if m < o, 0 if equal, +1 if m > o.
+func (m Money) Cmp(o Money) int {
+	m, o = align(m, o)
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool { return m.minor == 0 }
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool { return m.minor > 0 }
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool { return m.minor < 0 }
+
+// Overflowed reports whether the amount, or an amount it was computed from,
+// was out of range and saturated. Such amounts are meaningless and must be
+// rejected rather than stored or printed.
+func (m Money) Overflowed() bool { return m.overflow }
+
+// Rat returns the amount in major units exactly, for comparing amounts of
+// different currencies and scales.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.minor), scaleInt(m.exponent()))
+}
+
+// Float64 returns the amount in major units. Use only for display or
+// interoperability; never feed the result back into calculations.
+func (m Money) Float64() float64 {
+	f, _ := m.Rat().Float64()
+	return f
+}
+
+// String formats the amount in major units with exactly the currency's number
+// of decimal places, e.g. "-1234.50" or "-1235" for JPY.
+func (m Money) String() string {
+	exp := m.exponent()
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absUint(minor), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Format prefixes the amount with a currency symbol, placing any minus sign
+// before the symbol: "-$12.00".
+func (m Money) Format(symbol string) string {
+	if m.minor < 0 {
+		return "-" + symbol + m.Neg().String()
+	}
+	return symbol + m.String()
+}
+
+// MarshalJSON encodes the amount as a JSON number in major units.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a numeric string or null. The result is
+// unstamped; call WithCurrency once the document's currency is known.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			*m = Money{}
+			return nil
+		}
+	}
+
+	parsed, err := Parse(text, "")
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Sum adds up amounts. The result takes the currency of the first stamped amount.
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, a := range amounts {
+		total = total.Add(a)
+	}
+	return total
+}
+
+// with returns an amount of minor units with m's currency and scale.
+func (m Money) with(minor int64) Money {
+	return Money{minor: minor, currency: m.currency, places: m.places, overflow: m.overflow}
+}
+
+// withBig is with for a result that may be out of range, which saturates it
+// and marks it as overflowed.
+func (m Money) withBig(minor *big.Int) Money {
+	v, ok := saturate(minor)
+	r := m.with(v)
+	r.overflow = r.overflow || !ok
+	return r
+}
+
+// exponent returns the amount's number of decimal places.
+func (m Money) exponent() int {
+	if m.places > 0 {
+		return m.places
+	}
+	return exponent(m.currency)
+}
+
+// rescale converts the amount to exp decimal places in the given currency,
+// rounding half away from zero if it loses places.
+func (m Money) rescale(exp int, code string) Money {
+	places := 0
+	if code == "" && exp > currency.DefaultMinorUnits {
+		places = exp
+	}
+	from := m.exponent()
+	rescaled := Money{minor: m.minor, currency: code, places: places, overflow: m.overflow}
+	if from == exp {
+		return rescaled
+	}
+	r := new(big.Rat).SetInt64(m.minor)
+	r.Mul(r, scaleRat(exp))
+	r.Quo(r, scaleRat(from))
+	return rescaled.withBig(roundBig(r))
+}
+
+// align brings two amounts to the same currency and scale before they are
+// combined: the currency of the first stamped one or, if neither is stamped,
+// the larger number of decimal places.
+func align(m, o Money) (Money, Money) {
+	switch {
+	case m.currency != "":
+		if o.currency != m.currency || o.places != 0 {
+			o = o.rescale(exponent(m.currency), m.currency)
+		}
+	case o.currency != "":
+		m = m.rescale(exponent(o.currency), o.currency)
+	case m.exponent() < o.exponent():
+		m = m.rescale(o.exponent(), "")
+	case m.exponent() > o.exponent():
+		o = o.rescale(m.exponent(), "")
+	}
+	return m, o
+}
+
+// exponent returns the number of minor-unit decimal places for a currency
+// code, or two if it is empty or unknown.
+func exponent(code string) int {
+	return currency.MinorUnits(code)
+}
+
+func scaleInt(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func scaleRat(exp int) *big.Rat {
+	return new(big.Rat).SetInt(scaleInt(exp))
+}
+
+// saturate returns v as an int64, or the nearest int64 limit and false if it
+// is out of range.
+func saturate(v *big.Int) (int64, bool) {
+	switch {
+	case v.IsInt64():
+		return v.Int64(), true
+	case v.Sign() < 0:
+		return math.MinInt64, false
+	default:
+		return math.MaxInt64, false
+	}
+}
+
+// roundBig rounds r to the nearest integer, half away from zero.
+func roundBig(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
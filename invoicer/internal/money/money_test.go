@@ -0,0 +1,263 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"12.34", 1234},
+		{"12.345", 1235},   // half away from zero
+		{"-12.345", -1235}, // half away from zero
+		{"0.1", 10},
+		{"7", 700},
+		{"1e2", 10000},
+		{"3.3e-16", 0},
+	}
+	for _, c := range cases {
+		m, err := Parse(c.in, "USD")
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", c.in, err)
+			continue
+		}
+		if m.Minor() != c.want {
+			t.Errorf("Parse(%q): expected %d minor units, got %d", c.in, c.want, m.Minor())
+		}
+	}
+
+	if _, err := Parse("abc", "USD"); err == nil {
+		t.Error("expected error for non-numeric amount")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var payload struct {
+		Rate   Money `json:"rate"`
+		Amount Money `json:"amount"`
+		Empty  Money `json:"empty"`
+	}
+	in := `{"rate": 33.33, "amount": "212.4", "empty": null}`
+	if err := json.Unmarshal([]byte(in), &payload); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if payload.Rate.Minor() != 3333 || payload.Amount.Minor() != 21240 || !payload.Empty.IsZero() {
+		t.Errorf("unexpected decoded values: %+v", payload)
+	}
+
+	out, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(out) != `{"rate":33.33,"amount":212.40,"empty":0.00}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	price := New(1999, "USD") // 19.99
+
+	if got := price.Mul(3); got.Minor() != 5997 {
+		t.Errorf("Mul(3): expected 5997, got %d", got.Minor())
+	}
+	// 12345 × 0.35 = 4320.75 → 4321; float multiplication would give 4320.749…
+	if got := New(12345, "USD").Mul(0.35); got.Minor() != 4321 {
+		t.Errorf("Mul(0.35): expected 4321, got %d", got.Minor())
+	}
+	if got := price.Percent(18); got.Minor() != 360 { // 3.5982 → 3.60
+		t.Errorf("Percent(18): expected 360, got %d", got.Minor())
+	}
+	if got := New(12000, "USD").WithoutPercent(20); got.Minor() != 10000 {
+		t.Errorf("WithoutPercent(20): expected 10000, got %d", got.Minor())
+	}
+	if got := New(1000, "USD").WithoutPercent(18); got.Minor() != 847 { // 8.4746 → 8.47
+		t.Errorf("WithoutPercent(18): expected 847, got %d", got.Minor())
+	}
+	if got := price.Sub(New(2999, "USD")); !got.IsNegative() || got.Minor() != -1000 {
+		t.Errorf("Sub: expected -1000, got %d", got.Minor())
+	}
+	if got := Sum(price, price, price); got.Minor() != 5997 || got.Currency() != "USD" {
+		t.Errorf("Sum: expected 5997 USD, got %d %s", got.Minor(), got.Currency())
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		m    Money
+		want string
+	}{
+		{New(123450, "USD"), "$1234.50"},
+		{New(5, "USD"), "$0.05"},
+		{New(-1200, "USD"), "-$12.00"},
+		{New(0, "USD"), "$0.00"},
+	}
+	for _, c := range cases {
+		if got := c.m.Format("$"); got != c.want {
+			t.Errorf("Format(%d): expected %q, got %q", c.m.Minor(), c.want, got)
+		}
+	}
+}
+
+func TestMinorUnitsFollowCurrency(t *testing.T) {
+	cases := []struct {
+		in       string
+		currency string
+		minor    int64
+		str      string
+	}{
+		{"1500.5", "JPY", 1501, "1501"},
+		{"1.1255", "KWD", 1126, "1.126"},
+		{"0.12345", "CLF", 1235, "0.1235"},
+		{"12.3", "USD", 1230, "12.30"},
+	}
+	for _, c := range cases {
+		m, err := Parse(c.in, c.currency)
+		if err != nil {
+			t.Fatalf("Parse(%q, %s) failed: %v", c.in, c.currency, err)
+		}
+		if m.Minor() != c.minor || m.String() != c.str {
+			t.Errorf("Parse(%q, %s): expected %d (%s), got %d (%s)", c.in, c.currency, c.minor, c.str, m.Minor(), m)
+		}
+	}
+}
+
+func TestUnstampedKeepsPrecisionUntilStamped(t *testing.T) {
+	var rate Money
+	if err := json.Unmarshal([]byte(`1.125`), &rate); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if rate.String() != "1.125" {
+		t.Errorf("expected unstamped 1.125 to keep three decimals, got %s", rate)
+	}
+	if got := rate.WithCurrency("KWD"); got.Minor() != 1125 || got.String() != "1.125" {
+		t.Errorf("KWD: expected 1125 minor units, got %d", got.Minor())
+	}
+	if got := rate.WithCurrency("USD"); got.Minor() != 113 {
+		t.Errorf("USD: expected 113 minor units, got %d", got.Minor())
+	}
+	if got := New(150000, "").WithCurrency("JPY"); got.Minor() != 1500 || got.String() != "1500" {
+		t.Errorf("JPY: expected 1500, got %d (%s)", got.Minor(), got)
+	}
+	if got := New(1000, "KWD").Add(rate); got.Minor() != 2125 || got.Currency() != "KWD" {
+		t.Errorf("Add: expected 2.125 KWD, got %s %s", got, got.Currency())
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	cases := []struct {
+		minor, increment, want int64
+	}{
+		{1232, 5, 1230},
+		{1233, 5, 1235},
+		{-1233, 5, -1235},
+		{1249, 100, 1200},
+		{1250, 100, 1300},
+		{1233, 1, 1233},
+		{1233, 0, 1233},
+	}
+	for _, c := range cases {
+		if got := New(c.minor, "CHF").RoundTo(c.increment); got.Minor() != c.want {
+			t.Errorf("RoundTo(%d) of %d: expected %d, got %d", c.increment, c.minor, c.want, got.Minor())
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		m    Money
+		rate float64
+		code string
+		want int64
+	}{
+		{New(10000, "EUR"), 89.9512, "INR", 899512},  // 100.00 EUR → 8995.12 INR
+		{New(12345, "USD"), 151.237, "JPY", 18670},   // 123.45 × 151.237 = 18670.2…
+		{New(1500, "JPY"), 0.0066, "USD", 990},       // ¥1500 → $9.90
+		{New(-10000, "EUR"), 0.33335, "KWD", -33335}, // rounded once, at three decimals
+	}
+	for _, c := range cases {
+		got := c.m.Convert(c.rate, c.code)
+		if got.Minor() != c.want || got.Currency() != c.code {
+			t.Errorf("Convert(%s %s × %g): expected %d %s, got %d %s", c.m, c.m.Currency(), c.rate, c.want, c.code, got.Minor(), got.Currency())
+		}
+	}
+}
+
+func TestExtend_SubCentRates(t *testing.T) {
+	cases := []struct {
+		rate     string
+		quantity float64
+		code     string
+		want     string
+	}{
+		{"0.125", 1000, "USD", "125.00"},      // not 0.13 × 1000 = 130.00
+		{"0.0015", 250000, "USD", "375.00"},   // a fifth of a cent per unit
+		{"0.000015", 1234567, "EUR", "18.52"}, // 18.518505 rounded once
+		{"0.125", 3, "USD", "0.38"},           // 0.375 rounded half away from zero
+		{"0.125", -1000, "USD", "-125.00"},    // credit notes
+		{"1.0005", 2, "KWD", "2.001"},         // three-decimal currency
+		{"12.5", 10, "JPY", "125"},            // zero-decimal currency
+		{"0.123456789", 1000000000, "USD", "123456789.00"},
+	}
+	for _, c := range cases {
+		rate, err := Parse(c.rate, "")
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", c.rate, err)
+		}
+		got := rate.Extend(c.quantity, c.code)
+		if got.String() != c.want || got.Currency() != c.code {
+			t.Errorf("%g × %s %s: expected %s, got %s %s", c.quantity, c.rate, c.code, c.want, got, got.Currency())
+		}
+	}
+}
+
+func TestFitsIn(t *testing.T) {
+	rate, _ := Parse("0.125", "")
+	if rate.FitsIn("USD") || !rate.FitsIn("KWD") {
+		t.Errorf("expected 0.125 to fit KWD but not USD")
+	}
+	if !New(150000, "").FitsIn("JPY") || New(150050, "").FitsIn("JPY") {
+		t.Errorf("expected 1500.00 but not 1500.50 to fit JPY")
+	}
+}
+
+func TestOverflowSaturatesAndIsReported(t *testing.T) {
+	half := New(math.MaxInt64/2, "USD")
+	if half.Overflowed() {
+		t.Fatalf("expected an in-range amount not to be overflowed")
+	}
+	cases := map[string]Money{
+		"Mul":     half.Mul(1e18),
+		"Extend":  New(100, "").Extend(1e18, "USD"),
+		"Add":     half.Add(half).Add(half),
+		"Sub":     half.Neg().Sub(half).Sub(half),
+		"Percent": half.Percent(1e6),
+		"Convert": half.Convert(1000, "KWD"),
+		"Neg":     New(math.MinInt64, "USD").Neg(),
+	}
+	for name, got := range cases {
+		if !got.Overflowed() {
+			t.Errorf("%s: expected the result (%s) to be overflowed", name, got)
+		}
+		if got.Minor() != math.MaxInt64 && got.Minor() != math.MinInt64 {
+			t.Errorf("%s: expected the result to saturate, got %d", name, got.Minor())
+		}
+	}
+	if got := half.Mul(1e18).Sub(half.Mul(1e18)).Add(New(100, "USD")); !got.Overflowed() {
+		t.Errorf("expected amounts computed from an overflowed amount to stay overflowed, got %s", got)
+	}
+}
+
+func TestMulRat(t *testing.T) {
+	// 29.00 / 215.00 of 43.00 is exactly 5.80
+	if got := New(4300, "USD").MulRat(big.NewRat(2900, 21500)); got != New(580, "USD") {
+		t.Errorf("expected 5.80, got %s", got)
+	}
+	if got := New(100, "USD").MulRat(big.NewRat(-1, 8)); got != New(-13, "USD") {
+		t.Errorf("expected -0.13 (half away from zero), got %s", got)
+	}
+}
@@ -0,0 +1,230 @@
+package numbering
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/store"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestScheme_Format(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"INV-{YYYY}-{SEQ:5}", "INV-2026-00042"},
+		{"{YY}{MM}{DD}/{SEQ}", "260309/42"},
+		{"A{SEQ:1}", "A42"},
+	}
+	for _, c := range cases {
+		got := Scheme{Pattern: c.pattern, Reset: ResetNever}.Format(date(2026, time.March, 9), 42)
+		if got != c.want {
+			t.Errorf("Format(%q): expected %q, got %q", c.pattern, c.want, got)
+		}
+	}
+}
+
+func TestScheme_Validate(t *testing.T) {
+	valid := []string{"INV-{YYYY}-{SEQ:5}", "{YY}{SEQ}"}
+	for _, p := range valid {
+		if err := (Scheme{Pattern: p, Reset: ResetYearly}).Validate(); err != nil {
+			t.Errorf("expected %q to be valid, got %v", p, err)
+		}
+	}
+
+	invalid := []string{"", "INV-{YYYY}", "{SEQ}-{SEQ}", "{SEQ:0}", "{FOO}-{SEQ}", "{YYYY:2}-{SEQ}", "{seq}"}
+	for _, p := range invalid {
+		if err := (Scheme{Pattern: p, Reset: ResetYearly}).Validate(); err == nil {
+			t.Errorf("expected %q to be invalid", p)
+		}
+	}
+
+	if err := (Scheme{Pattern: "{SEQ}", Reset: "weekly"}).Validate(); err == nil {
+		t.Error("expected unknown reset policy to be invalid")
+	}
+
+	// A resetting sequence must show its period, or numbers would repeat
+	resets := []struct {
+		pattern string
+		reset   ResetPolicy
+		valid   bool
+	}{
+		{"{SEQ}", ResetNever, true},
+		{"INV-{SEQ}", ResetYearly, false},
+		{"INV-{MM}-{SEQ}", ResetYearly, false},
+		{"INV-{YY}{MM}-{SEQ}", ResetMonthly, true},
+		{"INV-{YYYY}-{MM}-{SEQ}", ResetMonthly, true},
+		{"INV-{YYYY}-{SEQ}", ResetMonthly, false},
+		{"INV-{MM}-{SEQ}", ResetMonthly, false},
+		{"INV-{DD}-{SEQ}", ResetMonthly, false},
+	}
+	for _, c := range resets {
+		err := (Scheme{Pattern: c.pattern, Reset: c.reset}).Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("%q with %s reset: expected valid=%v, got %v", c.pattern, c.reset, c.valid, err)
+		}
+	}
+}
+
+func TestMemoryStore_AllocateYearlyReset(t *testing.T) {
+	s := NewMemoryStore()
+	ok := func(string) error { return nil }
+
+	first, _ := s.Allocate("user_1", ScopeInvoice, date(2025, time.December, 31), ok)
+	second, _ := s.Allocate("user_1", ScopeInvoice, date(2025, time.December, 31), ok)
+	newYear, _ := s.Allocate("user_1", ScopeInvoice, date(2026, time.January, 1), ok)
+	otherUser, _ := s.Allocate("user_2", ScopeInvoice, date(2025, time.December, 31), ok)
+
+	if first != "INV-2025-00001" || second != "INV-2025-00002" {
+		t.Errorf("expected sequential 2025 numbers, got %q and %q", first, second)
+	}
+	if newYear != "INV-2026-00001" {
+		t.Errorf("expected sequence to reset in 2026, got %q", newYear)
+	}
+	if otherUser != "INV-2025-00001" {
+		t.Errorf("expected independent sequence per user, got %q", otherUser)
+	}
+}
+
+func TestMemoryStore_ScopesCountIndependently(t *testing.T) {
+	s := NewMemoryStore()
+	ok := func(string) error { return nil }
+	d := date(2026, time.March, 1)
+
+	invoice, _ := s.Allocate("user_1", ScopeInvoice, d, ok)
+	creditNote, _ := s.Allocate("user_1", ScopeCreditNote, d, ok)
+
+	if invoice != "INV-2026-00001" || creditNote != "CN-2026-00001" {
+		t.Errorf("expected independent default sequences, got %q and %q", invoice, creditNote)
+	}
+}
+
+func TestMemoryStore_AllocateIsGapless(t *testing.T) {
+	s := NewMemoryStore()
+	d := date(2026, time.May, 1)
+
+	// A failed commit must not consume the number
+	_, err := s.Allocate("user_1", ScopeInvoice, d, func(string) error { return errors.New("save failed") })
+	if err == nil {
+		t.Fatal("expected commit error to be returned")
+	}
+
+	if peek, _ := s.Peek("user_1", ScopeInvoice, d); peek != "INV-2026-00001" {
+		t.Errorf("expected failed allocation to leave 00001 free, got %q", peek)
+	}
+
+	// A taken number is skipped rather than failing the allocation
+	number, err := s.Allocate("user_1", ScopeInvoice, d, func(n string) error {
+		if n == "INV-2026-00001" {
+			return ErrNumberTaken
+		}
+		return nil
+	})
+	if err != nil || number != "INV-2026-00002" {
+		t.Errorf("expected taken number to be skipped, got %q (%v)", number, err)
+	}
+}
+
+func TestMemoryStore_AllocateConcurrent(t *testing.T) {
+	s := NewMemoryStore()
+	d := date(2026, time.May, 1)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			number, err := s.Allocate("user_1", ScopeInvoice, d, func(string) error { return nil })
+			if err != nil {
+				t.Errorf("Allocate failed: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[number] {
+				t.Errorf("number %q allocated twice", number)
+			}
+			seen[number] = true
+		}()
+	}
+	wg.Wait()
+
+	if peek, _ := s.Peek("user_1", ScopeInvoice, d); peek != "INV-2026-00051" {
+		t.Errorf("expected next number INV-2026-00051, got %q", peek)
+	}
+}
+
+func TestMemoryStore_SlowCommitBlocksOnlyItsSequence(t *testing.T) {
+	s := NewMemoryStore()
+	d := date(2026, time.May, 1)
+	other := make(chan string)
+
+	// user_1's commit only returns once user_2 has allocated, which would
+	// deadlock if allocations shared one lock.
+	number, err := s.Allocate("user_1", ScopeInvoice, d, func(string) error {
+		go func() {
+			n, _ := s.Allocate("user_2", ScopeInvoice, d, func(string) error { return nil })
+			other <- n
+		}()
+		select {
+		case n := <-other:
+			if n != "INV-2026-00001" {
+				t.Errorf("expected user_2 to get INV-2026-00001, got %q", n)
+			}
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("user_2's allocation waited for user_1's commit")
+		}
+	})
+	if err != nil || number != "INV-2026-00001" {
+		t.Fatalf("expected INV-2026-00001, got %q (%v)", number, err)
+	}
+}
+
+func TestSQLiteStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "numbers.db")
+	d := date(2026, time.May, 1)
+	ok := func(string) error { return nil }
+
+	db, err := store.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	s := NewSQLiteStore(db)
+	if err := s.SetScheme("user_1", ScopeQuote, Scheme{Pattern: "Q-{SEQ:3}", Reset: ResetNever}); err != nil {
+		t.Fatalf("SetScheme failed: %v", err)
+	}
+	s.Allocate("user_1", ScopeInvoice, d, ok)
+	s.Allocate("user_1", ScopeQuote, d, ok)
+	if _, err := s.Allocate("user_1", ScopeInvoice, d, func(string) error { return errors.New("save failed") }); err == nil {
+		t.Fatal("expected commit error to be returned")
+	}
+	db.Close()
+
+	db, err = store.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer db.Close()
+	s = NewSQLiteStore(db)
+
+	if invoice, _ := s.Allocate("user_1", ScopeInvoice, d, ok); invoice != "INV-2026-00002" {
+		t.Errorf("expected the invoice sequence to continue at 00002, got %q", invoice)
+	}
+	if quote, _ := s.Allocate("user_1", ScopeQuote, d, ok); quote != "Q-002" {
+		t.Errorf("expected the stored quote scheme to continue at Q-002, got %q", quote)
+	}
+	if peek, _ := s.Peek("user_2", ScopeInvoice, d); peek != "INV-2026-00001" {
+		t.Errorf("expected independent sequence per user, got %q", peek)
+	}
+}
@@ -0,0 +1,149 @@
+package numbering
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPattern is used for users that have not configured their own invoice pattern.
+const DefaultPattern = "INV-{YYYY}-{SEQ:5}"
+
+// defaultPatterns holds the default pattern for each known scope.
+var defaultPatterns = map[string]string{
+	ScopeInvoice:    DefaultPattern,
+	ScopeCreditNote: "CN-{YYYY}-{SEQ:5}",
+	ScopeQuote:      "EST-{YYYY}-{SEQ:5}",
+}
+
+// tokenRegex matches placeholders such as {YYYY} or {SEQ:5}.
+var tokenRegex = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+
+// ResetPolicy controls when a sequence starts again from 1.
+type ResetPolicy string
+
+const (
+	// ResetYearly restarts the sequence on the first number of each calendar year.
+	ResetYearly ResetPolicy = "yearly"
+	// ResetMonthly restarts the sequence on the first number of each calendar month.
+	ResetMonthly ResetPolicy = "monthly"
+	// ResetNever keeps counting forever.
+	ResetNever ResetPolicy = "never"
+)
+
+// Scheme describes how numbers are generated for one sequence.
+//
+// Supported placeholders in Pattern:
+//
+//	{YYYY}   four-digit year        {YY} two-digit year
+//	{MM}     two-digit month        {DD} two-digit day
+//	{SEQ}    sequence number        {SEQ:n} sequence zero-padded to n digits
+//
+// Pattern must contain exactly one {SEQ} placeholder.
+type Scheme struct {
+	Pattern string      `json:"pattern"`
+	Reset   ResetPolicy `json:"reset"`
+}
+
+// DefaultScheme returns the scheme used for the scope when none has been configured.
+func DefaultScheme(scope string) Scheme {
+	pattern, ok := defaultPatterns[scope]
+	if !ok {
+		pattern = DefaultPattern
+	}
+	return Scheme{Pattern: pattern, Reset: ResetYearly}
+}
+
+// IsKnownScope reports whether scope names a sequence with a default scheme.
+func IsKnownScope(scope string) bool {
+	_, ok := defaultPatterns[scope]
+	return ok
+}
+
+// Validate checks that the pattern is well formed and the reset policy is known.
+// A sequence that resets must show the period it counts in, or numbers would
+// repeat: yearly resets need a year placeholder and monthly resets a year and
+// a month placeholder.
+func (s Scheme) Validate() error {
+	switch s.Reset {
+	case ResetYearly, ResetMonthly, ResetNever:
+	default:
+		return fmt.Errorf("invalid reset policy %q: must be %q, %q or %q", s.Reset, ResetYearly, ResetMonthly, ResetNever)
+	}
+
+	if strings.TrimSpace(s.Pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+
+	seqCount := 0
+	hasYear, hasMonth := false, false
+	for _, m := range tokenRegex.FindAllStringSubmatch(s.Pattern, -1) {
+		switch m[1] {
+		case "YYYY", "YY", "MM", "DD":
+			if m[2] != "" {
+				return fmt.Errorf("placeholder {%s} does not take a width", m[1])
+			}
+			hasYear = hasYear || m[1] == "YYYY" || m[1] == "YY"
+			hasMonth = hasMonth || m[1] == "MM"
+		case "SEQ":
+			seqCount++
+			if m[2] != "" {
+				if width, _ := strconv.Atoi(m[2]); width < 1 || width > 12 {
+					return fmt.Errorf("sequence width must be between 1 and 12")
+				}
+			}
+		default:
+			return fmt.Errorf("unknown placeholder {%s}", m[1])
+		}
+	}
+	if seqCount != 1 {
+		return fmt.Errorf("pattern must contain exactly one {SEQ} placeholder")
+	}
+	if s.Reset == ResetYearly && !hasYear {
+		return fmt.Errorf("yearly reset requires a {YYYY} or {YY} placeholder")
+	}
+	if s.Reset == ResetMonthly && (!hasYear || !hasMonth) {
+		return fmt.Errorf("monthly reset requires a {YYYY} or {YY} and a {MM} placeholder")
+	}
+
+	// Stray braces usually mean a mistyped placeholder
+	if rest := tokenRegex.ReplaceAllString(s.Pattern, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("pattern contains an unrecognised placeholder")
+	}
+	return nil
+}
+
+// Format renders the pattern for the given date and sequence number.
+func (s Scheme) Format(date time.Time, seq int) string {
+	return tokenRegex.ReplaceAllStringFunc(s.Pattern, func(token string) string {
+		m := tokenRegex.FindStringSubmatch(token)
+		switch m[1] {
+		case "YYYY":
+			return fmt.Sprintf("%04d", date.Year())
+		case "YY":
+			return fmt.Sprintf("%02d", date.Year()%100)
+		case "MM":
+			return fmt.Sprintf("%02d", int(date.Month()))
+		case "DD":
+			return fmt.Sprintf("%02d", date.Day())
+		case "SEQ":
+			width, _ := strconv.Atoi(m[2])
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+		return token
+	})
+}
+
+// period returns the key of the counter a number dated date belongs to.
+func (s Scheme) period(date time.Time) string {
+	switch s.Reset {
+	case ResetYearly:
+		return date.Format("2006")
+	case ResetMonthly:
+		return date.Format("2006-01")
+	default:
+		return ""
+	}
+}
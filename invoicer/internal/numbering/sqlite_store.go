@@ -0,0 +1,112 @@
+package numbering
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SQLiteStore is a Store backed by the number_schemes and number_counters
+// tables of a database opened with store.OpenSQLite.
+type SQLiteStore struct {
+	sequences sequenceLocks
+	db        *sql.DB
+}
+
+// NewSQLiteStore creates a numbering store on db.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// Scheme returns the user's scheme for the scope, or DefaultScheme(scope) if none is set.
+func (s *SQLiteStore) Scheme(userID, scope string) (Scheme, error) {
+	var scheme Scheme
+	err := s.db.QueryRow(`SELECT pattern, reset FROM number_schemes WHERE user_id = ? AND scope = ?`,
+		userID, scope).Scan(&scheme.Pattern, &scheme.Reset)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultScheme(scope), nil
+	}
+	if err != nil {
+		return Scheme{}, fmt.Errorf("failed to query numbering scheme: %w", err)
+	}
+	return scheme, nil
+}
+
+// SetScheme stores a validated scheme for the user and scope.
+func (s *SQLiteStore) SetScheme(userID, scope string, scheme Scheme) error {
+	if err := scheme.Validate(); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`INSERT INTO number_schemes (user_id, scope, pattern, reset) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, scope) DO UPDATE SET pattern = excluded.pattern, reset = excluded.reset`,
+		userID, scope, scheme.Pattern, scheme.Reset)
+	if err != nil {
+		return fmt.Errorf("failed to store numbering scheme: %w", err)
+	}
+	return nil
+}
+
+// Peek returns the next number without consuming it.
+func (s *SQLiteStore) Peek(userID, scope string, date time.Time) (string, error) {
+	scheme, err := s.Scheme(userID, scope)
+	if err != nil {
+		return "", err
+	}
+	last, err := s.counter(userID, scope, scheme.period(date))
+	if err != nil {
+		return "", err
+	}
+	return scheme.Format(date, last+1), nil
+}
+
+// Allocate proposes numbers to commit until one succeeds. The counter is
+// written after the commit; if the process stops in between, the committed
+// number is reported as taken on the next allocation and skipped.
+func (s *SQLiteStore) Allocate(userID, scope string, date time.Time, commit func(number string) error) (string, error) {
+	// As in MemoryStore, only the user's sequence is held during commits.
+	defer s.sequences.lock(schemeKey(userID, scope))()
+
+	scheme, err := s.Scheme(userID, scope)
+	if err != nil {
+		return "", err
+	}
+	period := scheme.period(date)
+	last, err := s.counter(userID, scope, period)
+	if err != nil {
+		return "", err
+	}
+
+	for seq := last + 1; seq <= last+maxSkips; seq++ {
+		number := scheme.Format(date, seq)
+		err := commit(number)
+		if errors.Is(err, ErrNumberTaken) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = s.db.Exec(`INSERT INTO number_counters (user_id, scope, period, value) VALUES (?, ?, ?, ?)
+			ON CONFLICT (user_id, scope, period) DO UPDATE SET value = excluded.value`,
+			userID, scope, period, seq)
+		if err != nil {
+			// The number is committed; it is skipped as taken next time.
+			log.Printf("⚠️  Numbering: failed to store counter for %s: %v", number, err)
+		}
+		return number, nil
+	}
+	return "", fmt.Errorf("no free number found after %d attempts", maxSkips)
+}
+
+// counter returns the last value allocated in the period, or 0.
+func (s *SQLiteStore) counter(userID, scope, period string) (int, error) {
+	var value int
+	err := s.db.QueryRow(`SELECT value FROM number_counters WHERE user_id = ? AND scope = ? AND period = ?`,
+		userID, scope, period).Scan(&value)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to query number counter: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,156 @@
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sequence scopes. Each scope counts independently and has its own default pattern.
+const (
+	ScopeInvoice    = "invoice"
+	ScopeCreditNote = "credit_note"
+	ScopeQuote      = "quote"
+)
+
+// maxSkips bounds how many already-taken numbers Allocate will step over.
+const maxSkips = 1000
+
+// ErrNumberTaken is returned by an Allocate commit function when the proposed
+// number is already in use (for example, entered manually). Allocate then
+// moves on to the next number.
+var ErrNumberTaken = errors.New("number already taken")
+
+// Store keeps numbering schemes and sequence counters. Sequences are keyed by
+// user and scope, so each user (and each scope such as "invoice") counts independently.
+type Store interface {
+	// Scheme returns the user's scheme for the scope, or DefaultScheme(scope) if none is set.
+	Scheme(userID, scope string) (Scheme, error)
+
+	// SetScheme stores a validated scheme for the user and scope.
+	SetScheme(userID, scope string, scheme Scheme) error
+
+	// Peek returns the number the next allocation dated date would receive,
+	// without consuming it.
+	Peek(userID, scope string, date time.Time) (string, error)
+
+	// Allocate proposes the next number to commit. The counter advances only
+	// if commit returns nil, so numbers are gapless: a failed commit leaves the
+	// number available for the next caller. Allocations for the same user and
+	// scope are serialised; others run concurrently.
+	Allocate(userID, scope string, date time.Time, commit func(number string) error) (string, error)
+}
+
+// MemoryStore is a thread-safe in-memory Store.
+type MemoryStore struct {
+	sequences sequenceLocks
+	mu        sync.Mutex
+	schemes   map[string]Scheme // keyed by user|scope
+	counters  map[string]int    // keyed by user|scope|period; last allocated value
+}
+
+// NewMemoryStore creates an empty in-memory numbering store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		schemes:  make(map[string]Scheme),
+		counters: make(map[string]int),
+	}
+}
+
+// Scheme returns the user's scheme for the scope, or DefaultScheme(scope) if none is set.
+func (s *MemoryStore) Scheme(userID, scope string) (Scheme, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scheme(userID, scope), nil
+}
+
+// SetScheme stores a validated scheme for the user and scope.
+func (s *MemoryStore) SetScheme(userID, scope string, scheme Scheme) error {
+	if err := scheme.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemes[schemeKey(userID, scope)] = scheme
+	return nil
+}
+
+// Peek returns the next number without consuming it.
+func (s *MemoryStore) Peek(userID, scope string, date time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheme := s.scheme(userID, scope)
+	next := s.counters[counterKey(userID, scope, scheme.period(date))] + 1
+	return scheme.Format(date, next), nil
+}
+
+// Allocate proposes numbers to commit until one succeeds.
+func (s *MemoryStore) Allocate(userID, scope string, date time.Time, commit func(number string) error) (string, error) {
+	// Commits can be slow (issuing a document renders its PDF), so only the
+	// user's sequence is held while they run, not the whole store.
+	defer s.sequences.lock(schemeKey(userID, scope))()
+
+	s.mu.Lock()
+	scheme := s.scheme(userID, scope)
+	key := counterKey(userID, scope, scheme.period(date))
+	last := s.counters[key]
+	s.mu.Unlock()
+
+	for seq := last + 1; seq <= last+maxSkips; seq++ {
+		number := scheme.Format(date, seq)
+		err := commit(number)
+		if errors.Is(err, ErrNumberTaken) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		s.mu.Lock()
+		s.counters[key] = seq
+		s.mu.Unlock()
+		return number, nil
+	}
+	return "", fmt.Errorf("no free number found after %d attempts", maxSkips)
+}
+
+// scheme returns the configured or default scheme. Callers must hold the lock.
+func (s *MemoryStore) scheme(userID, scope string) Scheme {
+	if scheme, ok := s.schemes[schemeKey(userID, scope)]; ok {
+		return scheme
+	}
+	return DefaultScheme(scope)
+}
+
+func schemeKey(userID, scope string) string {
+	return userID + "|" + scope
+}
+
+func counterKey(userID, scope, period string) string {
+	return userID + "|" + scope + "|" + period
+}
+
+// sequenceLocks serialises allocations per user and scope.
+type sequenceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // keyed by user|scope
+}
+
+// lock locks the sequence key and returns the function that unlocks it.
+func (l *sequenceLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.Mutex)
+	}
+	m, ok := l.locks[key]
+	if !ok {
+		m = new(sync.Mutex)
+		l.locks[key] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
@@ -0,0 +1,142 @@
+package overdue
+
+import (
+	"context"
+	"errors"
+	"invoice-generator/invoicer/internal/audit"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"log"
+	"time"
+)
+
+// errUnchanged aborts an invoice update that has nothing to do.
+var errUnchanged = errors.New("invoice unchanged")
+
+// Result counts what one run of the job changed.
+type Result struct {
+	Flagged int // invoices moved to overdue
+	Fees    int // late fees added
+}
+
+// Job flags overdue invoices and charges late fees in the background.
+//
+// Every fee is recorded with the overdue period it covers, and each invoice is
+// checked and updated in a single atomic store update, so running the job
+// several times a day, or again after an interruption, never charges a period
+// twice.
+type Job struct {
+	invoices store.InvoiceStore
+	profiles store.BusinessProfileStore
+	interval time.Duration
+}
+
+// NewJob creates a job that checks for overdue invoices every interval. The
+// changes it makes are recorded in the invoices' history.
+func NewJob(invoices store.InvoiceStore, profiles store.BusinessProfileStore, interval time.Duration) *Job {
+	return &Job{invoices: invoices, profiles: profiles, interval: interval}
+}
+
+// Run checks invoices immediately and then on every tick until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := j.RunOnce(time.Now().UTC()); err != nil {
+			log.Printf("⚠️  Overdue invoices: %v", err)
+		} else if result.Flagged > 0 || result.Fees > 0 {
+			log.Printf("⏰ Overdue invoices: flagged %d invoice(s), added %d late fee(s)", result.Flagged, result.Fees)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce flags every invoice whose due date passed before today and charges
+// the late fees of its business profile. A failing invoice does not stop the
+// others; the first error is returned.
+func (j *Job) RunOnce(now time.Time) (Result, error) {
+	invoices, err := j.invoices.ListAll()
+	if err != nil {
+		return Result{}, err
+	}
+
+	today := civil.Of(now)
+	var result Result
+	var firstErr error
+	for _, invoice := range invoices {
+		if !IsOverdue(invoice, today) {
+			continue
+		}
+		flagged, fees, err := j.process(invoice, today, now)
+		if flagged {
+			result.Flagged++
+		}
+		result.Fees += fees
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return result, firstErr
+}
+
+// process flags one invoice and charges its late fees.
+func (j *Job) process(invoice *models.Invoice, today civil.Date, now time.Time) (bool, int, error) {
+	policy, err := j.policy(invoice)
+	if err != nil {
+		return false, 0, err
+	}
+
+	var flagged bool
+	var fees int
+	// The entry's action depends on whether fees were charged, which is only
+	// known once the update has run.
+	journal := func(before, after *models.Invoice) (*models.AuditEntry, error) {
+		entry := models.AuditEntry{Action: models.AuditStatusChanged, Actor: models.ActorOverdueJob, At: now}
+		if fees > 0 {
+			entry.Action = models.AuditLateFeeCharged
+		}
+		return audit.Journal(entry)(before, after)
+	}
+	_, err = j.invoices.Update(invoice.UserID, invoice.ID, func(inv *models.Invoice) error {
+		var err error
+		if flagged, err = Flag(inv, today, now); err != nil {
+			return err
+		}
+		fees = ApplyFees(inv, policy, today, now)
+		if !flagged && fees == 0 {
+			return errUnchanged
+		}
+		return nil
+	}, journal)
+	if errors.Is(err, errUnchanged) || errors.Is(err, store.ErrInvoiceNotFound) {
+		// Nothing to do, or deleted since it was listed.
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return flagged, fees, nil
+}
+
+// policy returns the late fee policy of the invoice's business profile, or
+// nil if the invoice has no profile or the profile charges no fees.
+func (j *Job) policy(invoice *models.Invoice) (*models.LateFeePolicy, error) {
+	if invoice.BusinessProfileID == "" {
+		return nil, nil
+	}
+	profile, err := j.profiles.Get(invoice.UserID, invoice.BusinessProfileID)
+	if errors.Is(err, store.ErrBusinessProfileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return profile.LateFee, nil
+}
@@ -0,0 +1,78 @@
+package overdue
+
+import (
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/store"
+	"testing"
+	"time"
+)
+
+func setup(t *testing.T, policy *models.LateFeePolicy) (*Job, *store.MemoryInvoiceStore, *store.MemoryAuditStore, string) {
+	t.Helper()
+	history := store.NewMemoryAuditStore()
+	invoices := store.NewMemoryInvoiceStore(history, nil)
+	profiles := store.NewMemoryBusinessProfileStore()
+
+	profile, err := profiles.Create("user_1", &models.BusinessProfile{Name: "Acme", LateFee: policy})
+	if err != nil {
+		t.Fatalf("Create profile failed: %v", err)
+	}
+	invoice := issuedInvoice()
+	invoice.BusinessProfileID = profile.ID
+	created, err := invoices.Create("user_1", invoice, nil)
+	if err != nil {
+		t.Fatalf("Create invoice failed: %v", err)
+	}
+	return NewJob(invoices, profiles, time.Hour), invoices, history, created.ID
+}
+
+func TestRunOnce_FlagsAndChargesOncePerPeriod(t *testing.T) {
+	job, invoices, history, id := setup(t, &models.LateFeePolicy{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD", GraceDays: 10})
+
+	// Overdue, but still within the grace period
+	result, err := job.RunOnce(time.Date(2026, time.February, 5, 6, 0, 0, 0, time.UTC))
+	if err != nil || result != (Result{Flagged: 1}) {
+		t.Fatalf("expected 1 flagged invoice and no fees, got %+v (err %v)", result, err)
+	}
+	invoice, _ := invoices.Get("user_1", id)
+	if invoice.Status != models.StatusOverdue || len(invoice.LateFees) != 0 {
+		t.Errorf("expected an overdue invoice without fees, got %q with %d fee(s)", invoice.Status, len(invoice.LateFees))
+	}
+
+	result, _ = job.RunOnce(time.Date(2026, time.February, 11, 6, 0, 0, 0, time.UTC))
+	if result != (Result{Fees: 1}) {
+		t.Errorf("expected 1 fee after the grace period, got %+v", result)
+	}
+
+	// Running again in the same period changes nothing
+	result, _ = job.RunOnce(time.Date(2026, time.February, 11, 18, 0, 0, 0, time.UTC))
+	if result != (Result{}) {
+		t.Errorf("expected no changes on a second run, got %+v", result)
+	}
+
+	invoice, _ = invoices.Get("user_1", id)
+	if len(invoice.LateFees) != 1 || invoice.LateFees[0].Period.String() != "2026-02-11" || invoice.Total != usd(22000) || invoice.BalanceDue != usd(24500) {
+		t.Errorf("expected one 25.00 fee for 2026-02-11 on top of the total, got %+v (total %s, balance due %s)", invoice.LateFees, invoice.Total, invoice.BalanceDue)
+	}
+
+	entries, _ := history.List("user_1", id)
+	if len(entries) != 2 || entries[0].Action != models.AuditStatusChanged || entries[1].Action != models.AuditLateFeeCharged {
+		t.Fatalf("expected the flag and the fee in history, got %+v", entries)
+	}
+	if entries[1].Actor != models.ActorOverdueJob || len(entries[1].Changes) == 0 {
+		t.Errorf("expected the fee recorded by the overdue job with its changes, got %+v", entries[1])
+	}
+}
+
+func TestRunOnce_WithoutPolicyOnlyFlags(t *testing.T) {
+	job, invoices, _, id := setup(t, nil)
+
+	result, err := job.RunOnce(time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || result != (Result{Flagged: 1}) {
+		t.Fatalf("expected 1 flagged invoice, got %+v (err %v)", result, err)
+	}
+	invoice, _ := invoices.Get("user_1", id)
+	if len(invoice.Items) != 1 || len(invoice.LateFees) != 0 {
+		t.Errorf("expected no fees without a policy, got %+v", invoice.LateFees)
+	}
+}
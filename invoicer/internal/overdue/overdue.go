@@ -0,0 +1,138 @@
+package overdue
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"time"
+)
+
+// maxGraceDays bounds the grace period of a late fee policy.
+const maxGraceDays = 365
+
+// ValidatePolicy checks a business's late fee policy.
+func ValidatePolicy(p *models.LateFeePolicy) error {
+	switch p.Kind {
+	case models.LateFeeFlat:
+		if !p.Amount.IsPositive() {
+			return fmt.Errorf("lateFee.amount must be greater than zero")
+		}
+		if !currency.Valid(p.Currency) {
+			return fmt.Errorf("lateFee.currency must be an ISO 4217 currency code")
+		}
+		if !p.Amount.FitsIn(p.Currency) {
+			return fmt.Errorf("lateFee.amount has more decimal places than %s allows", p.Currency)
+		}
+	case models.LateFeePercent:
+		if p.Rate <= 0 || p.Rate > 100 {
+			return fmt.Errorf("lateFee.rate must be greater than 0 and at most 100")
+		}
+		if p.Currency != "" {
+			return fmt.Errorf("lateFee.currency is only used by %q fees", models.LateFeeFlat)
+		}
+	default:
+		return fmt.Errorf("lateFee.kind must be %q or %q", models.LateFeeFlat, models.LateFeePercent)
+	}
+	if p.GraceDays < 0 || p.GraceDays > maxGraceDays {
+		return fmt.Errorf("lateFee.graceDays must be between 0 and %d", maxGraceDays)
+	}
+	if p.MaxFees < 0 {
+		return fmt.Errorf("lateFee.maxFees must not be negative")
+	}
+	return nil
+}
+
+// IsOverdue reports whether the invoice is open, has a balance due and its
+// due date is before today.
+func IsOverdue(invoice *models.Invoice, today civil.Date) bool {
+	return lifecycle.IsOpen(invoice) &&
+		!invoice.DueDate.IsZero() &&
+		invoice.DueDate.Before(today) &&
+		calc.Balance(invoice).IsPositive()
+}
+
+// Periods returns the start dates of the overdue periods that began on or
+// before today. The first period starts the day after the grace period ends;
+// each further period starts a month later. MaxFees limits the count.
+func Periods(invoice *models.Invoice, p *models.LateFeePolicy, today civil.Date) []civil.Date {
+	if invoice.DueDate.IsZero() {
+		return nil
+	}
+	first := invoice.DueDate.AddDays(p.GraceDays + 1)
+
+	var periods []civil.Date
+	for n := 0; p.MaxFees == 0 || n < p.MaxFees; n++ {
+		start := first.AddMonths(n)
+		if start.After(today) {
+			break
+		}
+		periods = append(periods, start)
+	}
+	return periods
+}
+
+// Flag moves an overdue invoice to the overdue status. It reports whether the
+// status changed.
+func Flag(invoice *models.Invoice, today civil.Date, at time.Time) (bool, error) {
+	if !IsOverdue(invoice, today) || lifecycle.Current(invoice) == models.StatusOverdue {
+		return false, nil
+	}
+	if err := lifecycle.Transition(invoice, models.StatusOverdue, at); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ApplyFees charges the policy's fee for every period that has begun and has
+// not been charged yet, as a charge in LateFees that is added to the balance
+// due, and returns the number of fees added. The invoice's lines and total are
+// left as they were issued. Periods already in LateFees are skipped, so
+// running it again for the same day adds nothing. Percentage fees are taken
+// from the part of the total still unpaid, leaving out earlier fees so that
+// they do not compound; flat fees are only charged on invoices in the
+// policy's currency.
+func ApplyFees(invoice *models.Invoice, p *models.LateFeePolicy, today civil.Date, at time.Time) int {
+	if p == nil || !IsOverdue(invoice, today) {
+		return 0
+	}
+
+	calc.Settle(invoice)
+	charged := make(map[string]bool, len(invoice.LateFees))
+	for _, fee := range invoice.LateFees {
+		charged[fee.Period.String()] = true
+	}
+
+	added := 0
+	for _, period := range Periods(invoice, p, today) {
+		if charged[period.String()] {
+			continue
+		}
+		amount, description := fee(invoice, p, period)
+		if !amount.IsPositive() {
+			continue
+		}
+
+		invoice.LateFees = append(invoice.LateFees, models.LateFee{Period: period, Description: description, Amount: amount, CreatedAt: at})
+		calc.Settle(invoice)
+		added++
+	}
+	return added
+}
+
+// fee returns the amount and description of the fee for one period of a
+// settled invoice. A flat fee in another currency than the invoice's is zero,
+// and so not charged.
+func fee(invoice *models.Invoice, p *models.LateFeePolicy, period civil.Date) (money.Money, string) {
+	if p.Kind == models.LateFeePercent {
+		unpaid := invoice.Total.Sub(invoice.AmountPaid).Sub(invoice.CreditedAmount)
+		return unpaid.Percent(p.Rate), fmt.Sprintf("Late fee: %g%% of %s %s overdue (from %s)", p.Rate, unpaid, invoice.Currency, period)
+	}
+	if p.Currency != invoice.Currency {
+		return money.Money{}, ""
+	}
+	return p.Amount.WithCurrency(p.Currency), fmt.Sprintf("Late fee (from %s)", period)
+}
@@ -0,0 +1,193 @@
+package overdue
+
+import (
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func usd(minor int64) money.Money {
+	return money.New(minor, "USD")
+}
+
+// issuedInvoice returns an issued invoice for $200.00 due on 31 January 2026.
+func issuedInvoice() *models.Invoice {
+	invoice := &models.Invoice{
+		InvoiceDate: civil.MustParse("2026-01-01"),
+		DueDate:     civil.MustParse("2026-01-31"),
+		Currency:    "USD",
+		Items:       []models.LineItem{{Description: "Design", Quantity: 2, Rate: usd(10000)}},
+		TaxRate:     10,
+		Status:      models.StatusIssued,
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func TestValidatePolicy(t *testing.T) {
+	valid := []*models.LateFeePolicy{
+		{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD"},
+		{Kind: models.LateFeePercent, Rate: 1.5, GraceDays: 7, MaxFees: 3},
+	}
+	for _, p := range valid {
+		if err := ValidatePolicy(p); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", p, err)
+		}
+	}
+
+	invalid := []*models.LateFeePolicy{
+		{Kind: "daily", Amount: usd(2500)},
+		{Kind: models.LateFeeFlat},
+		{Kind: models.LateFeeFlat, Amount: usd(2500)},
+		{Kind: models.LateFeeFlat, Amount: money.New(2550, ""), Currency: "JPY"},
+		{Kind: models.LateFeePercent, Rate: 1.5, Currency: "USD"},
+		{Kind: models.LateFeePercent, Rate: 0},
+		{Kind: models.LateFeePercent, Rate: 150},
+		{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD", GraceDays: -1},
+		{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD", MaxFees: -1},
+	}
+	for _, p := range invalid {
+		if err := ValidatePolicy(p); err == nil {
+			t.Errorf("expected %+v to be rejected", p)
+		}
+	}
+}
+
+func TestIsOverdue(t *testing.T) {
+	invoice := issuedInvoice()
+	if IsOverdue(invoice, civil.MustParse("2026-01-31")) {
+		t.Error("expected invoice not to be overdue on its due date")
+	}
+	if !IsOverdue(invoice, civil.MustParse("2026-02-01")) {
+		t.Error("expected invoice to be overdue the day after its due date")
+	}
+
+	draft := issuedInvoice()
+	draft.Status = models.StatusDraft
+	noDueDate := issuedInvoice()
+	noDueDate.DueDate = civil.Date{}
+	paid := issuedInvoice()
+	paid.Payments = []models.Payment{{Amount: paid.Total}}
+	for _, inv := range []*models.Invoice{draft, noDueDate, paid} {
+		if IsOverdue(inv, civil.MustParse("2026-06-01")) {
+			t.Errorf("expected %q invoice due %q not to be overdue", inv.Status, inv.DueDate)
+		}
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	invoice := issuedInvoice()
+	policy := &models.LateFeePolicy{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD", GraceDays: 5}
+
+	if got := Periods(invoice, policy, civil.MustParse("2026-02-05")); len(got) != 0 {
+		t.Errorf("expected no periods during the grace period, got %v", got)
+	}
+
+	got := Periods(invoice, policy, civil.MustParse("2026-04-10"))
+	want := []string{"2026-02-06", "2026-03-06", "2026-04-06"}
+	if len(got) != len(want) {
+		t.Fatalf("expected periods %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("period %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+
+	policy.MaxFees = 1
+	if got := Periods(invoice, policy, civil.MustParse("2026-04-10")); len(got) != 1 {
+		t.Errorf("expected maxFees to limit the periods to 1, got %v", got)
+	}
+}
+
+func TestFlag(t *testing.T) {
+	invoice := issuedInvoice()
+	at := time.Date(2026, time.February, 1, 6, 0, 0, 0, time.UTC)
+
+	if flagged, err := Flag(invoice, civil.Of(at), at); err != nil || !flagged {
+		t.Fatalf("expected invoice to be flagged, got %v (err %v)", flagged, err)
+	}
+	if invoice.Status != models.StatusOverdue {
+		t.Errorf("expected status overdue, got %q", invoice.Status)
+	}
+	if flagged, _ := Flag(invoice, civil.Of(at), at); flagged {
+		t.Error("expected an overdue invoice not to be flagged again")
+	}
+}
+
+func TestApplyFees_Flat(t *testing.T) {
+	invoice := issuedInvoice() // 200.00 + 10% tax = 220.00
+	policy := &models.LateFeePolicy{Kind: models.LateFeeFlat, Amount: money.New(2500, ""), Currency: "USD"}
+	at := time.Date(2026, time.March, 2, 6, 0, 0, 0, time.UTC)
+
+	if added := ApplyFees(invoice, policy, civil.Of(at), at); added != 2 {
+		t.Fatalf("expected 2 fees (from 2026-02-01 and 2026-03-01), got %d", added)
+	}
+	if len(invoice.Items) != 1 || invoice.Total != usd(22000) || invoice.TaxAmount != usd(2000) {
+		t.Errorf("expected the issued lines and total of 220.00 to be left unchanged, got %d lines and %s", len(invoice.Items), invoice.Total)
+	}
+	if len(invoice.LateFees) != 2 || invoice.LateFees[1].Period.String() != "2026-03-01" || !invoice.LateFees[1].CreatedAt.Equal(at) ||
+		invoice.LateFees[1].Amount != usd(2500) || invoice.LateFees[1].Description != "Late fee (from 2026-03-01)" {
+		t.Errorf("unexpected fee records: %+v", invoice.LateFees)
+	}
+	if invoice.LateFeeAmount != usd(5000) || invoice.BalanceDue != usd(27000) {
+		t.Errorf("expected late fees of 50.00 and balance due 270.00, got %s and %s", invoice.LateFeeAmount, invoice.BalanceDue)
+	}
+
+	// Idempotent per period
+	if added := ApplyFees(invoice, policy, civil.Of(at), at); added != 0 {
+		t.Errorf("expected no new fees on a second run, got %d", added)
+	}
+}
+
+func TestApplyFees_FlatSkipsOtherCurrencies(t *testing.T) {
+	invoice := issuedInvoice()
+	invoice.Currency = "EUR"
+	policy := &models.LateFeePolicy{Kind: models.LateFeeFlat, Amount: usd(2500), Currency: "USD"}
+	at := time.Date(2026, time.March, 2, 6, 0, 0, 0, time.UTC)
+
+	if added := ApplyFees(invoice, policy, civil.Of(at), at); added != 0 || len(invoice.LateFees) != 0 {
+		t.Errorf("expected a USD fee not to be charged on a EUR invoice, got %d fee(s)", added)
+	}
+}
+
+func TestApplyFees_Percent(t *testing.T) {
+	invoice := issuedInvoice()
+	invoice.Payments = []models.Payment{{Amount: usd(2000)}}
+	calc.Apply(invoice) // balance due 200.00
+	policy := &models.LateFeePolicy{Kind: models.LateFeePercent, Rate: 1.5}
+	at := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if added := ApplyFees(invoice, policy, civil.Of(at), at); added != 2 {
+		t.Fatalf("expected 2 fees, got %d", added)
+	}
+	// 1.5% of 200.00 each month; the first fee is not charged on again
+	if invoice.LateFees[0].Amount != usd(300) || invoice.LateFees[1].Amount != usd(300) {
+		t.Errorf("expected fees of 3.00 and 3.00, got %s and %s", invoice.LateFees[0].Amount, invoice.LateFees[1].Amount)
+	}
+	if invoice.BalanceDue != usd(20600) {
+		t.Errorf("expected balance due 206.00, got %s", invoice.BalanceDue)
+	}
+
+	// A later run charges the next month on the issued total, as paid so far.
+	invoice.Total = usd(21000)
+	invoice.Payments = append(invoice.Payments, models.Payment{Amount: usd(10000)})
+	at = time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+	if added := ApplyFees(invoice, policy, civil.Of(at), at); added != 1 || invoice.LateFees[2].Amount != usd(135) {
+		t.Fatalf("expected a third fee of 1.35 (1.5%% of 90.00), got %d fees", added)
+	}
+	if invoice.Items[0].Amount != usd(20000) || invoice.BalanceDue != usd(9735) {
+		t.Errorf("expected the issued lines to be kept and a balance of 97.35, got %s and %s", invoice.Items[0].Amount, invoice.BalanceDue)
+	}
+}
+
+func TestApplyFees_NoPolicy(t *testing.T) {
+	invoice := issuedInvoice()
+	at := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if added := ApplyFees(invoice, nil, civil.Of(at), at); added != 0 || len(invoice.Items) != 1 {
+		t.Errorf("expected no fees without a policy, got %d", added)
+	}
+}
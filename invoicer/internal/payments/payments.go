@@ -0,0 +1,76 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotPayable is returned when recording a payment against an invoice
+	// that is not open (a draft, or already paid or void).
+	ErrNotPayable = errors.New("payments can only be recorded against issued invoices that are not yet paid")
+
+	// ErrOverpayment is returned when a payment exceeds the balance due.
+	ErrOverpayment = errors.New("payment exceeds the balance due")
+
+	// ErrInvalidPayment wraps validation failures of the payment itself.
+	ErrInvalidPayment = errors.New("invalid payment")
+)
+
+// Record validates a payment, appends it to the invoice and refreshes the
+// amount paid and balance due, leaving the issued lines and total as they are. A payment that clears the balance moves the
+// invoice to paid; a partial payment moves an issued, sent or viewed invoice to
+// partially paid. Overdue invoices stay overdue until fully paid.
+func Record(invoice *models.Invoice, payment models.Payment, at time.Time) (models.Payment, error) {
+	if !lifecycle.IsOpen(invoice) {
+		return models.Payment{}, ErrNotPayable
+	}
+
+	payment.Amount = payment.Amount.WithCurrency(invoice.Currency)
+	if !payment.Amount.IsPositive() {
+		return models.Payment{}, fmt.Errorf("%w: amount must be greater than zero", ErrInvalidPayment)
+	}
+
+	if payment.Date.IsZero() {
+		payment.Date = civil.Of(at)
+	}
+
+	calc.Settle(invoice)
+	if payment.Amount.Cmp(invoice.BalanceDue) > 0 {
+		return models.Payment{}, fmt.Errorf("%w (balance due is %s)", ErrOverpayment, invoice.BalanceDue)
+	}
+
+	payment.ID = fmt.Sprintf("pay_%d", len(invoice.Payments)+1)
+	payment.Method = strings.TrimSpace(payment.Method)
+	payment.Reference = strings.TrimSpace(payment.Reference)
+	payment.CreatedAt = at
+
+	invoice.Payments = append(invoice.Payments, payment)
+	calc.Settle(invoice)
+
+	if err := settleStatus(invoice, at); err != nil {
+		return models.Payment{}, err
+	}
+	return payment, nil
+}
+
+// settleStatus moves the invoice to the status its balance implies.
+func settleStatus(invoice *models.Invoice, at time.Time) error {
+	status := lifecycle.Current(invoice)
+
+	if !invoice.BalanceDue.IsPositive() {
+		return lifecycle.Transition(invoice, models.StatusPaid, at)
+	}
+
+	switch status {
+	case models.StatusIssued, models.StatusSent, models.StatusViewed:
+		return lifecycle.Transition(invoice, models.StatusPartiallyPaid, at)
+	}
+	return nil
+}
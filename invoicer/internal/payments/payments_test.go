@@ -0,0 +1,109 @@
+package payments
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func usd(minor int64) money.Money {
+	return money.New(minor, "USD")
+}
+
+// issuedInvoice returns an issued invoice with a total of $100.00.
+func issuedInvoice() *models.Invoice {
+	invoice := &models.Invoice{
+		Status:   models.StatusIssued,
+		Currency: "USD",
+		Items:    []models.LineItem{{Description: "Work", Quantity: 1, Rate: usd(10000)}},
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func TestRecord_PartialThenFull(t *testing.T) {
+	invoice := issuedInvoice()
+	at := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	first, err := Record(invoice, models.Payment{Amount: usd(4000), Method: "bank_transfer", Reference: "TX-1"}, at)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if first.ID != "pay_1" || first.Date.String() != "2026-03-10" {
+		t.Errorf("unexpected payment: %+v", first)
+	}
+	if invoice.Status != models.StatusPartiallyPaid {
+		t.Errorf("expected status 'partially_paid', got %q", invoice.Status)
+	}
+	if invoice.AmountPaid != usd(4000) || invoice.BalanceDue != usd(6000) {
+		t.Errorf("expected paid 40.00 / due 60.00, got %s / %s", invoice.AmountPaid, invoice.BalanceDue)
+	}
+
+	if _, err := Record(invoice, models.Payment{Amount: usd(6000), Date: civil.MustParse("2026-03-20")}, at); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if invoice.Status != models.StatusPaid {
+		t.Errorf("expected status 'paid', got %q", invoice.Status)
+	}
+	if !invoice.BalanceDue.IsZero() || len(invoice.Payments) != 2 {
+		t.Errorf("expected zero balance and 2 payments, got %s and %d", invoice.BalanceDue, len(invoice.Payments))
+	}
+}
+
+func TestRecord_KeepsIssuedTotals(t *testing.T) {
+	invoice := issuedInvoice()
+	// The total as issued, which a later change to the calculation would
+	// no longer produce from the lines.
+	invoice.Total = usd(9999)
+
+	if _, err := Record(invoice, models.Payment{Amount: usd(4000)}, time.Now()); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if invoice.Total != usd(9999) || invoice.Items[0].Amount != usd(10000) {
+		t.Errorf("expected the issued amounts to be kept, got total %s and line %s", invoice.Total, invoice.Items[0].Amount)
+	}
+	if invoice.BalanceDue != usd(5999) {
+		t.Errorf("expected the balance to follow the issued total, got %s", invoice.BalanceDue)
+	}
+}
+
+func TestRecord_OverdueStaysOverdueUntilPaid(t *testing.T) {
+	invoice := issuedInvoice()
+	invoice.Status = models.StatusOverdue
+
+	if _, err := Record(invoice, models.Payment{Amount: usd(1000)}, time.Now()); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if invoice.Status != models.StatusOverdue {
+		t.Errorf("expected status 'overdue', got %q", invoice.Status)
+	}
+}
+
+func TestRecord_Rejected(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  models.InvoiceStatus
+		payment models.Payment
+		want    error
+	}{
+		{"draft", models.StatusDraft, models.Payment{Amount: usd(100)}, ErrNotPayable},
+		{"void", models.StatusVoid, models.Payment{Amount: usd(100)}, ErrNotPayable},
+		{"zero amount", models.StatusIssued, models.Payment{Amount: usd(0)}, ErrInvalidPayment},
+		{"overpayment", models.StatusIssued, models.Payment{Amount: usd(10001)}, ErrOverpayment},
+	}
+	for _, c := range cases {
+		invoice := issuedInvoice()
+		invoice.Status = c.status
+
+		if _, err := Record(invoice, c.payment, time.Now()); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+		if len(invoice.Payments) != 0 || invoice.Status != c.status {
+			t.Errorf("%s: rejected payment modified the invoice", c.name)
+		}
+	}
+}
@@ -2,11 +2,34 @@ package pdf
 
 import (
 	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/currency"
+	"invoice-generator/invoicer/internal/fx"
 	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/terms"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
+// Templates lists the available invoice layouts. Unknown names render as "minimal".
+var Templates = []string{"minimal", "corporate", "modern"}
+
+// IsTemplate reports whether name is one of Templates.
+func IsTemplate(name string) bool {
+	for _, t := range Templates {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Generator handles PDF generation for invoices
 type Generator struct {
 	pdf *gofpdf.Fpdf
@@ -44,12 +67,12 @@ func (g *Generator) GenerateInvoice(invoice *models.Invoice) ([]byte, error) {
 }
 
 func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
-	currencySymbol := getCurrencySymbol(invoice.Currency)
+	amounts := newAmountFormat(g.pdf, invoice.Currency)
 
 	// Header - INVOICE title and Business Name (side by side)
 	g.pdf.SetFont("Arial", "B", 24)
 	g.pdf.SetXY(15, 15)
-	g.pdf.Cell(0, 10, "INVOICE")
+	g.pdf.Cell(0, 10, documentTitle(invoice))
 
 	g.pdf.SetFont("Arial", "B", 14)
 	g.pdf.SetXY(120, 15)
@@ -59,7 +82,7 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "", 10)
 	g.pdf.SetTextColor(100, 100, 100)
 	g.pdf.SetXY(15, 25)
-	g.pdf.Cell(0, 6, fmt.Sprintf("#%s", invoice.InvoiceNumber))
+	g.pdf.Cell(0, 6, documentReference(invoice))
 
 	// Business contact info (right aligned)
 	g.pdf.SetFont("Arial", "", 9)
@@ -69,10 +92,19 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.CellFormat(75, 5, invoice.BusinessPhone, "", 0, "R", false, 0, "")
 
 	// Business address (right aligned, multi-line)
+	identityY := 35.0
 	if invoice.BusinessAddress != "" {
 		g.pdf.SetXY(120, 35)
 		g.pdf.SetFont("Arial", "", 9)
 		g.pdf.MultiCell(75, 4, invoice.BusinessAddress, "", "R", false)
+		identityY = g.pdf.GetY()
+	}
+
+	// Tax ID and registration number (right aligned)
+	if identity := identityLine(invoice); identity != "" {
+		g.pdf.SetFont("Arial", "", 8)
+		g.pdf.SetXY(90, identityY)
+		g.pdf.CellFormat(105, 4, identity, "", 0, "R", false, 0, "")
 	}
 
 	g.pdf.SetTextColor(0, 0, 0)
@@ -102,21 +134,22 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	}
 
 	// Right column - Invoice & Due Date
+	issued, due := documentDates(invoice)
 	g.pdf.SetTextColor(120, 120, 120)
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetXY(120, y)
-	g.pdf.Cell(40, 5, "Invoice Date:")
+	g.pdf.Cell(40, 5, issued.label+":")
 	g.pdf.SetTextColor(0, 0, 0)
 	g.pdf.SetFont("Arial", "", 9)
-	g.pdf.CellFormat(35, 5, invoice.InvoiceDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(35, 5, issued.value, "", 0, "R", false, 0, "")
 
 	g.pdf.SetTextColor(120, 120, 120)
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetXY(120, y+6)
-	g.pdf.Cell(40, 5, "Due Date:")
+	g.pdf.Cell(40, 5, due.label+":")
 	g.pdf.SetTextColor(0, 0, 0)
 	g.pdf.SetFont("Arial", "", 9)
-	g.pdf.CellFormat(35, 5, invoice.DueDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(35, 5, due.value, "", 0, "R", false, 0, "")
 
 	g.pdf.SetTextColor(0, 0, 0)
 
@@ -128,21 +161,9 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetX(15)
 
-	headers := []struct {
-		text  string
-		width float64
-		align string
-	}{
-		{"DESCRIPTION", 60, "L"},
-		{"QTY", 18, "R"},
-		{"RATE", 25, "R"},
-		{"TAX%", 18, "R"},
-		{"DISC%", 18, "R"},
-		{"AMOUNT", 28, "R"},
-	}
-
-	for _, h := range headers {
-		g.pdf.CellFormat(h.width, 6, h.text, "", 0, h.align, false, 0, "")
+	columns := itemColumns(invoice, amounts, 167, "R")
+	for _, col := range columns {
+		g.pdf.CellFormat(col.width, 6, col.header, "", 0, col.align, false, 0, "")
 	}
 	g.pdf.Ln(6)
 
@@ -155,27 +176,11 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetLineWidth(0.1)
 
-	for _, item := range invoice.Items {
+	for i, item := range invoice.Items {
 		g.pdf.SetX(15)
-
-		// Description
-		g.pdf.CellFormat(60, 6, truncateString(item.Description, 40), "", 0, "L", false, 0, "")
-
-		// Quantity
-		g.pdf.CellFormat(18, 6, fmt.Sprintf("%.0f", item.Quantity), "", 0, "R", false, 0, "")
-
-		// Rate
-		g.pdf.CellFormat(25, 6, fmt.Sprintf("%s%.2f", currencySymbol, item.Rate), "", 0, "R", false, 0, "")
-
-		// Tax%
-		g.pdf.CellFormat(18, 6, fmt.Sprintf("%.0f%%", item.TaxRate), "", 0, "R", false, 0, "")
-
-		// Disc%
-		g.pdf.CellFormat(18, 6, fmt.Sprintf("%.0f%%", item.DiscountRate), "", 0, "R", false, 0, "")
-
-		// Amount
-		g.pdf.CellFormat(28, 6, fmt.Sprintf("%s%.2f", currencySymbol, item.Amount), "", 0, "R", false, 0, "")
-
+		for _, col := range columns {
+			g.pdf.CellFormat(col.width, 6, col.value(i, item), "", 0, col.align, false, 0, "")
+		}
 		g.pdf.Ln(6)
 
 		// Thin line under each row
@@ -189,6 +194,7 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	// Totals section (right aligned)
 	totalsY := g.pdf.GetY() + 8
 	totalsX := 125.0
+	totalsStartY := totalsY
 
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(100, 100, 100)
@@ -197,26 +203,16 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(totalsX, totalsY)
 	g.pdf.Cell(35, 5, "Subtotal:")
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(35, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Subtotal), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(35, 5, amounts.Format(invoice.Subtotal), "", 0, "R", false, 0, "")
 	totalsY += 5
 
-	// Discount (if applicable)
-	if invoice.DiscountRate > 0 {
-		g.pdf.SetTextColor(100, 100, 100)
-		g.pdf.SetXY(totalsX, totalsY)
-		g.pdf.Cell(35, 5, fmt.Sprintf("Discount (%.0f%%):", invoice.DiscountRate))
-		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(35, 5, fmt.Sprintf("-%s%.2f", currencySymbol, invoice.DiscountAmount), "", 0, "R", false, 0, "")
-		totalsY += 5
-	}
-
-	// Tax (if applicable)
-	if invoice.TaxRate > 0 {
+	// Invoice-level discount and taxes (if applicable)
+	for _, row := range adjustmentRows(invoice, amounts) {
 		g.pdf.SetTextColor(100, 100, 100)
 		g.pdf.SetXY(totalsX, totalsY)
-		g.pdf.Cell(35, 5, fmt.Sprintf("Tax (%.0f%%):", invoice.TaxRate))
+		g.pdf.Cell(35, 5, row.label+":")
 		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(35, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.TaxAmount), "", 0, "R", false, 0, "")
+		g.pdf.CellFormat(35, 5, row.value, "", 0, "R", false, 0, "")
 		totalsY += 5
 	}
 
@@ -230,11 +226,38 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 	g.pdf.SetTextColor(0, 0, 0)
 	g.pdf.SetXY(totalsX, totalsY)
 	g.pdf.Cell(35, 6, "Total:")
-	g.pdf.CellFormat(35, 6, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Total), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(35, 6, amounts.Format(invoice.Total), "", 0, "R", false, 0, "")
+
+	// Late fees, payments and credits (if any)
+	if rows := settlementRows(invoice, amounts); len(rows) > 0 {
+		totalsY += 3
+		g.pdf.SetFont("Arial", "", 9)
+		for _, row := range rows {
+			totalsY += 5
+			g.pdf.SetTextColor(100, 100, 100)
+			g.pdf.SetXY(totalsX, totalsY)
+			g.pdf.Cell(35, 5, row.label+":")
+			g.pdf.SetTextColor(0, 0, 0)
+			g.pdf.CellFormat(35, 5, row.value, "", 0, "R", false, 0, "")
+		}
+		totalsY += 5
+
+		g.pdf.SetFont("Arial", "B", 10)
+		g.pdf.SetXY(totalsX, totalsY)
+		g.pdf.Cell(35, 5, "Balance Due:")
+		g.pdf.CellFormat(35, 5, amounts.Format(invoice.BalanceDue), "", 0, "R", false, 0, "")
+	}
 
 	g.pdf.SetLineWidth(0.1)
 
+	// Tax summary (left of the totals)
+	if bottom := g.drawTaxSummary(15, totalsStartY, invoice, amounts); bottom > totalsY {
+		totalsY = bottom
+	}
+	totalsY = g.drawLateFees(15, totalsY, invoice, amounts)
+
 	// Notes section (if present)
+	detailsY := totalsY + 20
 	if invoice.Notes != "" {
 		notesY := totalsY + 20
 		g.pdf.SetXY(15, notesY)
@@ -247,13 +270,26 @@ func (g *Generator) drawMinimalInvoice(invoice *models.Invoice) {
 		g.pdf.SetTextColor(100, 100, 100)
 		g.pdf.SetX(15)
 		g.pdf.MultiCell(180, 4, invoice.Notes, "", "L", false)
+		detailsY = g.pdf.GetY() + 8
+	}
+
+	// Payment terms and bank details (if present)
+	if rows := paymentRows(invoice, amounts); len(rows) > 0 {
+		g.pdf.SetXY(15, detailsY)
+		g.pdf.SetFont("Arial", "B", 9)
+		g.pdf.SetTextColor(120, 120, 120)
+		g.pdf.Cell(0, 5, "PAYMENT DETAILS")
+		g.drawPaymentRows(15, detailsY+6, rows)
 	}
 
 	g.pdf.SetTextColor(0, 0, 0)
+
+	// Base-currency equivalents (bottom of the page)
+	g.drawBaseCurrencyFooter(invoice)
 }
 
 func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
-	currencySymbol := getCurrencySymbol(invoice.Currency)
+	amounts := newAmountFormat(g.pdf, invoice.Currency)
 
 	// Blue header background (RGB: 30, 58, 138 = blue-900)
 	g.pdf.SetFillColor(30, 58, 138)
@@ -263,13 +299,13 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetTextColor(255, 255, 255)
 	g.pdf.SetFont("Arial", "B", 20)
 	g.pdf.SetXY(15, 10)
-	g.pdf.Cell(0, 8, "INVOICE")
+	g.pdf.Cell(0, 8, documentTitle(invoice))
 
 	// Invoice number
 	g.pdf.SetFont("Arial", "", 10)
 	g.pdf.SetTextColor(191, 219, 254) // blue-200
 	g.pdf.SetXY(15, 20)
-	g.pdf.Cell(0, 5, fmt.Sprintf("#%s", invoice.InvoiceNumber))
+	g.pdf.Cell(0, 5, documentReference(invoice))
 
 	// Business name (right aligned)
 	g.pdf.SetFont("Arial", "B", 14)
@@ -285,6 +321,13 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(110, 24)
 	g.pdf.CellFormat(85, 4, invoice.BusinessPhone, "", 0, "R", false, 0, "")
 
+	// Tax ID and registration number (right aligned)
+	if identity := identityLine(invoice); identity != "" {
+		g.pdf.SetFont("Arial", "", 8)
+		g.pdf.SetXY(80, 28)
+		g.pdf.CellFormat(115, 4, identity, "", 0, "R", false, 0, "")
+	}
+
 	g.pdf.SetTextColor(0, 0, 0)
 
 	// Bill To & Invoice Info section
@@ -315,25 +358,26 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	}
 
 	// Right column - Invoice details (in gray boxes)
+	issued, due := documentDates(invoice)
 	g.pdf.SetFillColor(249, 250, 251)
 	g.pdf.Rect(110, y, 85, 10, "F")
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(80, 80, 80)
 	g.pdf.SetXY(113, y+3)
-	g.pdf.Cell(40, 5, "Invoice Date")
+	g.pdf.Cell(40, 5, issued.label)
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(39, 5, invoice.InvoiceDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 5, issued.value, "", 0, "R", false, 0, "")
 
 	g.pdf.SetFillColor(249, 250, 251)
 	g.pdf.Rect(110, y+12, 85, 10, "F")
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(80, 80, 80)
 	g.pdf.SetXY(113, y+15)
-	g.pdf.Cell(40, 5, "Due Date")
+	g.pdf.Cell(40, 5, due.label)
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(39, 5, invoice.DueDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 5, due.value, "", 0, "R", false, 0, "")
 
 	// Amount Due box (highlighted in blue)
 	g.pdf.SetFillColor(219, 234, 254) // blue-50
@@ -342,9 +386,9 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(30, 58, 138) // blue-900
 	g.pdf.SetXY(113, y+27)
-	g.pdf.Cell(40, 5, "Amount Due")
+	g.pdf.Cell(40, 5, amountDueLabel(invoice))
 	g.pdf.SetFont("Arial", "B", 12)
-	g.pdf.CellFormat(39, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Total), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 5, amounts.Format(invoice.BalanceDue), "", 0, "R", false, 0, "")
 
 	g.pdf.SetDrawColor(0, 0, 0)
 	g.pdf.SetTextColor(0, 0, 0)
@@ -358,21 +402,9 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "B", 8)
 	g.pdf.SetX(15)
 
-	headers := []struct {
-		text  string
-		width float64
-		align string
-	}{
-		{"DESCRIPTION", 55, "L"},
-		{"QTY", 18, "C"},
-		{"RATE", 25, "R"},
-		{"TAX%", 18, "R"},
-		{"DISC%", 18, "R"},
-		{"AMOUNT", 28, "R"},
-	}
-
-	for _, h := range headers {
-		g.pdf.CellFormat(h.width, 7, h.text, "1", 0, h.align, true, 0, "")
+	columns := itemColumns(invoice, amounts, 162, "C")
+	for _, col := range columns {
+		g.pdf.CellFormat(col.width, 7, col.header, "1", 0, col.align, true, 0, "")
 	}
 	g.pdf.Ln(7)
 
@@ -380,15 +412,14 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetFillColor(255, 255, 255)
 
-	for _, item := range invoice.Items {
+	for i, item := range invoice.Items {
 		g.pdf.SetX(15)
-		g.pdf.CellFormat(55, 7, truncateString(item.Description, 35), "1", 0, "L", false, 0, "")
-		g.pdf.CellFormat(18, 7, fmt.Sprintf("%.0f", item.Quantity), "1", 0, "C", false, 0, "")
-		g.pdf.CellFormat(25, 7, fmt.Sprintf("%s%.2f", currencySymbol, item.Rate), "1", 0, "R", false, 0, "")
-		g.pdf.CellFormat(18, 7, fmt.Sprintf("%.0f%%", item.TaxRate), "1", 0, "R", false, 0, "")
-		g.pdf.CellFormat(18, 7, fmt.Sprintf("%.0f%%", item.DiscountRate), "1", 0, "R", false, 0, "")
-		g.pdf.SetFont("Arial", "B", 9)
-		g.pdf.CellFormat(28, 7, fmt.Sprintf("%s%.2f", currencySymbol, item.Amount), "1", 0, "R", false, 0, "")
+		for c, col := range columns {
+			if c == len(columns)-1 {
+				g.pdf.SetFont("Arial", "B", 9)
+			}
+			g.pdf.CellFormat(col.width, 7, col.value(i, item), "1", 0, col.align, false, 0, "")
+		}
 		g.pdf.SetFont("Arial", "", 9)
 		g.pdf.Ln(7)
 	}
@@ -396,6 +427,7 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	// Totals section
 	totalsY := g.pdf.GetY() + 8
 	totalsX := 125.0
+	totalsStartY := totalsY
 
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(100, 100, 100)
@@ -404,30 +436,18 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(totalsX, totalsY)
 	g.pdf.Cell(35, 5, "Subtotal")
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(35, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Subtotal), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(35, 5, amounts.Format(invoice.Subtotal), "", 0, "R", false, 0, "")
 	totalsY += 5
 
-	// Discount
-	if invoice.DiscountRate > 0 {
-		g.pdf.SetFillColor(249, 250, 251)
-		g.pdf.Rect(totalsX, totalsY, 70, 5, "F")
-		g.pdf.SetTextColor(100, 100, 100)
-		g.pdf.SetXY(totalsX, totalsY)
-		g.pdf.Cell(35, 5, fmt.Sprintf("Discount (%.0f%%)", invoice.DiscountRate))
-		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(35, 5, fmt.Sprintf("-%s%.2f", currencySymbol, invoice.DiscountAmount), "", 0, "R", false, 0, "")
-		totalsY += 5
-	}
-
-	// Tax
-	if invoice.TaxRate > 0 {
+	// Invoice-level discount and taxes
+	for _, row := range adjustmentRows(invoice, amounts) {
 		g.pdf.SetFillColor(249, 250, 251)
 		g.pdf.Rect(totalsX, totalsY, 70, 5, "F")
 		g.pdf.SetTextColor(100, 100, 100)
 		g.pdf.SetXY(totalsX, totalsY)
-		g.pdf.Cell(35, 5, fmt.Sprintf("Tax (%.0f%%)", invoice.TaxRate))
+		g.pdf.Cell(35, 5, row.label)
 		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(35, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.TaxAmount), "", 0, "R", false, 0, "")
+		g.pdf.CellFormat(35, 5, row.value, "", 0, "R", false, 0, "")
 		totalsY += 5
 	}
 
@@ -440,11 +460,41 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(totalsX+2, totalsY+2)
 	g.pdf.Cell(33, 5, "Total Due")
 	g.pdf.SetFont("Arial", "B", 12)
-	g.pdf.CellFormat(33, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Total), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(33, 5, amounts.Format(invoice.Total), "", 0, "R", false, 0, "")
+
+	// Late fees, payments and credits (if any)
+	if rows := settlementRows(invoice, amounts); len(rows) > 0 {
+		totalsY += 6
+		g.pdf.SetFont("Arial", "", 9)
+		for _, row := range rows {
+			totalsY += 5
+			g.pdf.SetTextColor(100, 100, 100)
+			g.pdf.SetXY(totalsX, totalsY)
+			g.pdf.Cell(35, 5, row.label)
+			g.pdf.SetTextColor(0, 0, 0)
+			g.pdf.CellFormat(35, 5, row.value, "", 0, "R", false, 0, "")
+		}
+		totalsY += 5
+
+		g.pdf.SetFillColor(219, 234, 254) // blue-50
+		g.pdf.Rect(totalsX, totalsY, 70, 6, "F")
+		g.pdf.SetFont("Arial", "B", 10)
+		g.pdf.SetTextColor(30, 58, 138) // blue-900
+		g.pdf.SetXY(totalsX, totalsY+0.5)
+		g.pdf.Cell(35, 5, "Balance Due")
+		g.pdf.CellFormat(35, 5, amounts.Format(invoice.BalanceDue), "", 0, "R", false, 0, "")
+	}
 
 	g.pdf.SetTextColor(0, 0, 0)
 
+	// Tax summary (left of the totals)
+	if bottom := g.drawTaxSummary(15, totalsStartY, invoice, amounts); bottom > totalsY {
+		totalsY = bottom
+	}
+	totalsY = g.drawLateFees(15, totalsY, invoice, amounts)
+
 	// Notes
+	detailsY := totalsY + 18
 	if invoice.Notes != "" {
 		notesY := totalsY + 18
 		g.pdf.SetFillColor(249, 250, 251)
@@ -460,31 +510,49 @@ func (g *Generator) drawCorporateInvoice(invoice *models.Invoice) {
 		g.pdf.SetTextColor(80, 80, 80)
 		g.pdf.SetX(15)
 		g.pdf.MultiCell(180, 4, invoice.Notes, "", "L", false)
+		detailsY = g.pdf.GetY() + 8
+	}
+
+	// Payment terms and bank details (gray box)
+	if rows := paymentRows(invoice, amounts); len(rows) > 0 {
+		g.pdf.SetFillColor(249, 250, 251)
+		g.pdf.Rect(15, detailsY, 90, 9+5*float64(len(rows)), "F")
+
+		g.pdf.SetFont("Arial", "B", 8)
+		g.pdf.SetTextColor(120, 120, 120)
+		g.pdf.SetXY(18, detailsY+2)
+		g.pdf.Cell(0, 4, "PAYMENT DETAILS")
+		g.drawPaymentRows(18, detailsY+7, rows)
 	}
 
 	g.pdf.SetTextColor(0, 0, 0)
+
+	// Base-currency equivalents (bottom of the page)
+	g.drawBaseCurrencyFooter(invoice)
 }
 
 func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
-	currencySymbol := getCurrencySymbol(invoice.Currency)
+	amounts := newAmountFormat(g.pdf, invoice.Currency)
 
 	// Set purple gradient background (solid purple for PDF)
 	g.pdf.SetFillColor(243, 232, 255) // purple-100
 	g.pdf.Rect(0, 0, 210, 297, "F")
 
-	// Header - INVOICE in gradient box
+	// Header - title in gradient box, widened for longer titles
+	title := documentTitle(invoice)
+	g.pdf.SetFont("Arial", "B", 20)
+	titleWidth := math.Max(60, g.pdf.GetStringWidth(title)+12)
 	g.pdf.SetFillColor(147, 51, 234) // purple-600
-	g.pdf.RoundedRect(15, 12, 60, 12, 3, "1234", "F")
+	g.pdf.RoundedRect(15, 12, titleWidth, 12, 3, "1234", "F")
 	g.pdf.SetTextColor(255, 255, 255)
-	g.pdf.SetFont("Arial", "B", 20)
 	g.pdf.SetXY(15, 14.5)
-	g.pdf.CellFormat(60, 8, "INVOICE", "", 0, "C", false, 0, "")
+	g.pdf.CellFormat(titleWidth, 8, title, "", 0, "C", false, 0, "")
 
 	// Invoice number
 	g.pdf.SetFont("Arial", "B", 10)
 	g.pdf.SetTextColor(80, 80, 80)
 	g.pdf.SetXY(15, 27)
-	g.pdf.Cell(0, 5, fmt.Sprintf("#%s", invoice.InvoiceNumber))
+	g.pdf.Cell(0, 5, documentReference(invoice))
 
 	// Business name (right aligned with gradient color effect)
 	g.pdf.SetFont("Arial", "B", 14)
@@ -500,6 +568,13 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(110, 29)
 	g.pdf.CellFormat(85, 4, invoice.BusinessPhone, "", 0, "R", false, 0, "")
 
+	// Tax ID and registration number (right aligned)
+	if identity := identityLine(invoice); identity != "" {
+		g.pdf.SetFont("Arial", "", 8)
+		g.pdf.SetXY(80, 33)
+		g.pdf.CellFormat(115, 4, identity, "", 0, "R", false, 0, "")
+	}
+
 	g.pdf.SetTextColor(0, 0, 0)
 
 	// Bill To & Dates cards (white rounded boxes)
@@ -533,24 +608,25 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	}
 
 	// Invoice details card
+	issued, due := documentDates(invoice)
 	g.pdf.SetFillColor(255, 255, 255)
 	g.pdf.RoundedRect(110, y, 85, 30, 3, "1234", "F")
 
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(100, 100, 100)
 	g.pdf.SetXY(113, y+5)
-	g.pdf.Cell(40, 4, "Invoice Date")
+	g.pdf.Cell(40, 4, issued.label)
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(39, 4, invoice.InvoiceDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 4, issued.value, "", 0, "R", false, 0, "")
 
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(100, 100, 100)
 	g.pdf.SetXY(113, y+12)
-	g.pdf.Cell(40, 4, "Due Date")
+	g.pdf.Cell(40, 4, due.label)
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(39, 4, invoice.DueDate, "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 4, due.value, "", 0, "R", false, 0, "")
 
 	// Divider
 	g.pdf.SetDrawColor(229, 231, 235)
@@ -561,10 +637,10 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.SetFont("Arial", "B", 9)
 	g.pdf.SetTextColor(0, 0, 0)
 	g.pdf.SetXY(113, y+22)
-	g.pdf.Cell(40, 5, "Amount Due")
+	g.pdf.Cell(40, 5, amountDueLabel(invoice))
 	g.pdf.SetFont("Arial", "B", 14)
 	g.pdf.SetTextColor(147, 51, 234)
-	g.pdf.CellFormat(39, 5, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Total), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 5, amounts.Format(invoice.BalanceDue), "", 0, "R", false, 0, "")
 
 	g.pdf.SetTextColor(0, 0, 0)
 
@@ -582,24 +658,12 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.SetTextColor(255, 255, 255)
 	g.pdf.SetXY(20, tableY+2)
 
-	headers := []struct {
-		text  string
-		width float64
-		align string
-	}{
-		{"DESCRIPTION", 55, "L"},
-		{"QTY", 18, "C"},
-		{"RATE", 25, "R"},
-		{"TAX%", 18, "R"},
-		{"DISC%", 18, "R"},
-		{"AMOUNT", 26, "R"},
-	}
-
+	columns := itemColumns(invoice, amounts, 160, "C")
 	x := 20.0
-	for _, h := range headers {
+	for _, col := range columns {
 		g.pdf.SetXY(x, tableY+2)
-		g.pdf.CellFormat(h.width, 4, h.text, "", 0, h.align, false, 0, "")
-		x += h.width
+		g.pdf.CellFormat(col.width, 4, col.header, "", 0, col.align, false, 0, "")
+		x += col.width
 	}
 
 	// Table rows
@@ -607,15 +671,14 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.SetTextColor(0, 0, 0)
 	rowY := tableY + 10
 
-	for _, item := range invoice.Items {
+	for i, item := range invoice.Items {
 		g.pdf.SetXY(20, rowY)
-		g.pdf.CellFormat(55, 5, truncateString(item.Description, 35), "", 0, "L", false, 0, "")
-		g.pdf.CellFormat(18, 5, fmt.Sprintf("%.0f", item.Quantity), "", 0, "C", false, 0, "")
-		g.pdf.CellFormat(25, 5, fmt.Sprintf("%s%.2f", currencySymbol, item.Rate), "", 0, "R", false, 0, "")
-		g.pdf.CellFormat(18, 5, fmt.Sprintf("%.0f%%", item.TaxRate), "", 0, "R", false, 0, "")
-		g.pdf.CellFormat(18, 5, fmt.Sprintf("%.0f%%", item.DiscountRate), "", 0, "R", false, 0, "")
-		g.pdf.SetFont("Arial", "B", 9)
-		g.pdf.CellFormat(26, 5, fmt.Sprintf("%s%.2f", currencySymbol, item.Amount), "", 0, "R", false, 0, "")
+		for c, col := range columns {
+			if c == len(columns)-1 {
+				g.pdf.SetFont("Arial", "B", 9)
+			}
+			g.pdf.CellFormat(col.width, 5, col.value(i, item), "", 0, col.align, false, 0, "")
+		}
 		g.pdf.SetFont("Arial", "", 9)
 		rowY += 7
 
@@ -628,11 +691,11 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	// Totals card (white rounded box)
 	totalsY := tableY + tableHeight + 8
 	totalsHeight := 25.0
-	if invoice.DiscountRate > 0 {
-		totalsHeight += 5
-	}
-	if invoice.TaxRate > 0 {
-		totalsHeight += 5
+	adjustments := adjustmentRows(invoice, amounts)
+	totalsHeight += 5 * float64(len(adjustments))
+	settlements := settlementRows(invoice, amounts)
+	if len(settlements) > 0 {
+		totalsHeight += 5*float64(len(settlements)) + 5
 	}
 
 	g.pdf.SetFillColor(255, 255, 255)
@@ -647,28 +710,17 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.Cell(40, 4, "Subtotal")
 	g.pdf.SetFont("Arial", "", 9)
 	g.pdf.SetTextColor(0, 0, 0)
-	g.pdf.CellFormat(39, 4, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Subtotal), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 4, amounts.Format(invoice.Subtotal), "", 0, "R", false, 0, "")
 	ty += 5
 
-	// Discount
-	if invoice.DiscountRate > 0 {
+	// Invoice-level discount and taxes
+	for _, row := range adjustments {
 		g.pdf.SetFont("Arial", "", 9)
 		g.pdf.SetTextColor(100, 100, 100)
 		g.pdf.SetXY(113, ty)
-		g.pdf.Cell(40, 4, fmt.Sprintf("Discount (%.0f%%)", invoice.DiscountRate))
+		g.pdf.Cell(40, 4, row.label)
 		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(39, 4, fmt.Sprintf("-%s%.2f", currencySymbol, invoice.DiscountAmount), "", 0, "R", false, 0, "")
-		ty += 5
-	}
-
-	// Tax
-	if invoice.TaxRate > 0 {
-		g.pdf.SetFont("Arial", "", 9)
-		g.pdf.SetTextColor(100, 100, 100)
-		g.pdf.SetXY(113, ty)
-		g.pdf.Cell(40, 4, fmt.Sprintf("Tax (%.0f%%)", invoice.TaxRate))
-		g.pdf.SetTextColor(0, 0, 0)
-		g.pdf.CellFormat(39, 4, fmt.Sprintf("%s%.2f", currencySymbol, invoice.TaxAmount), "", 0, "R", false, 0, "")
+		g.pdf.CellFormat(39, 4, row.value, "", 0, "R", false, 0, "")
 		ty += 5
 	}
 
@@ -681,11 +733,39 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 	g.pdf.SetXY(113, ty+2)
 	g.pdf.Cell(40, 4, "Total")
 	g.pdf.SetFont("Arial", "B", 14)
-	g.pdf.CellFormat(39, 4, fmt.Sprintf("%s%.2f", currencySymbol, invoice.Total), "", 0, "R", false, 0, "")
+	g.pdf.CellFormat(39, 4, amounts.Format(invoice.Total), "", 0, "R", false, 0, "")
+
+	// Late fees, payments and credits (if any)
+	if len(settlements) > 0 {
+		ty += 5
+		g.pdf.SetFont("Arial", "", 9)
+		for _, row := range settlements {
+			ty += 5
+			g.pdf.SetTextColor(100, 100, 100)
+			g.pdf.SetXY(113, ty)
+			g.pdf.Cell(40, 4, row.label)
+			g.pdf.SetTextColor(0, 0, 0)
+			g.pdf.CellFormat(39, 4, row.value, "", 0, "R", false, 0, "")
+		}
+		ty += 5
+
+		g.pdf.SetFont("Arial", "B", 10)
+		g.pdf.SetTextColor(147, 51, 234)
+		g.pdf.SetXY(113, ty)
+		g.pdf.Cell(40, 4, "Balance Due")
+		g.pdf.CellFormat(39, 4, amounts.Format(invoice.BalanceDue), "", 0, "R", false, 0, "")
+	}
 
 	g.pdf.SetTextColor(0, 0, 0)
 
+	// Tax summary (left of the totals card)
+	if bottom := g.drawTaxSummary(15, totalsY, invoice, amounts); bottom > totalsY+totalsHeight {
+		totalsHeight = bottom - totalsY
+	}
+	totalsHeight = g.drawLateFees(15, totalsY+totalsHeight, invoice, amounts) - totalsY
+
 	// Notes
+	detailsY := totalsY + totalsHeight + 10
 	if invoice.Notes != "" {
 		notesY := totalsY + totalsHeight + 10
 		g.pdf.SetFillColor(255, 255, 255)
@@ -705,28 +785,482 @@ func (g *Generator) drawModernInvoice(invoice *models.Invoice) {
 		g.pdf.SetTextColor(80, 80, 80)
 		g.pdf.SetX(20)
 		g.pdf.MultiCell(170, 4, invoice.Notes, "", "L", false)
+		detailsY = notesY + 38
+	}
+
+	// Payment terms and bank details card
+	if rows := paymentRows(invoice, amounts); len(rows) > 0 {
+		cardHeight := 10 + 5*float64(len(rows))
+		g.pdf.SetFillColor(255, 255, 255)
+		g.pdf.RoundedRect(15, detailsY, 95, cardHeight, 3, "23", "F")
+
+		// Purple accent bar
+		g.pdf.SetFillColor(147, 51, 234)
+		g.pdf.Rect(15, detailsY, 2, cardHeight, "F")
+
+		g.pdf.SetFont("Arial", "B", 8)
+		g.pdf.SetTextColor(0, 0, 0)
+		g.pdf.SetXY(20, detailsY+3)
+		g.pdf.Cell(0, 4, "PAYMENT DETAILS")
+		g.drawPaymentRows(20, detailsY+8, rows)
 	}
 
 	g.pdf.SetTextColor(0, 0, 0)
+
+	// Base-currency equivalents (bottom of the page)
+	g.drawBaseCurrencyFooter(invoice)
 }
 
 // Helper functions
 
-func getCurrencySymbol(currency string) string {
-	symbols := map[string]string{
-		"USD": "$",
-		"EUR": "€",
-		"GBP": "£",
-		"JPY": "¥",
-		"AUD": "A$",
-		"CAD": "C$",
-		"INR": "₹",
+// documentTitle returns the heading printed at the top of the document.
+func documentTitle(invoice *models.Invoice) string {
+	switch {
+	case invoice.IsCreditNote():
+		return "CREDIT NOTE"
+	case invoice.IsQuote():
+		return "ESTIMATE"
+	}
+	return "INVOICE"
+}
+
+// documentReference returns the number line under the heading, or "Draft"
+// for an invoice not yet numbered. Credit notes also name the invoice they
+// credit, and converted invoices the estimate they came from.
+func documentReference(invoice *models.Invoice) string {
+	ref := "Draft"
+	if invoice.InvoiceNumber != "" {
+		ref = fmt.Sprintf("#%s", invoice.InvoiceNumber)
+	}
+	if invoice.IsCreditNote() && invoice.OriginalInvoiceNumber != "" {
+		ref += fmt.Sprintf("  (credit for invoice #%s)", invoice.OriginalInvoiceNumber)
+	}
+	if invoice.QuoteNumber != "" {
+		ref += fmt.Sprintf("  (from estimate #%s)", invoice.QuoteNumber)
+	}
+	return ref
+}
+
+// documentDates returns the two labelled dates printed in the header.
+// Estimates show how long they remain valid instead of a due date.
+func documentDates(invoice *models.Invoice) (issued, due totalsRow) {
+	if invoice.IsQuote() {
+		return totalsRow{"Estimate Date", invoice.InvoiceDate.String()}, totalsRow{"Valid Until", invoice.ValidUntil.String()}
+	}
+	return totalsRow{"Invoice Date", invoice.InvoiceDate.String()}, totalsRow{"Due Date", invoice.DueDate.String()}
+}
+
+// amountDueLabel labels the highlighted balance in the document header.
+func amountDueLabel(invoice *models.Invoice) string {
+	switch {
+	case invoice.IsCreditNote():
+		return "Credit Total"
+	case invoice.IsQuote():
+		return "Estimate Total"
 	}
+	return "Amount Due"
+}
+
+// quantityLabel formats a line's quantity, with as many decimals as it has,
+// followed by its unit, if any.
+func quantityLabel(item models.LineItem) string {
+	quantity := strconv.FormatFloat(item.Quantity, 'f', -1, 64)
+	if item.Unit == "" {
+		return quantity
+	}
+	return quantity + " " + item.Unit
+}
+
+// unitRate returns a line's unit rate for printing: with the currency's
+// decimal places, or with all of its own when it has more, such as 0.125 USD.
+func unitRate(rate money.Money, code string) money.Money {
+	if rate.FitsIn(code) {
+		return rate.WithCurrency(code)
+	}
+	return rate
+}
+
+// itemColumn is a column of the line items table.
+type itemColumn struct {
+	header string
+	width  float64
+	align  string
+	value  func(i int, item models.LineItem) string
+}
+
+// itemColumns returns the line item columns for a table width wide. Tax-exclusive
+// invoices show the rate, tax rates, discount and amount of each line;
+// tax-inclusive invoices show the price including tax, then the net amount, the
+// tax backed out of it and the gross amount. The description takes the width
+// the other columns leave.
+func itemColumns(invoice *models.Invoice, amounts amountFormat, width float64, qtyAlign string) []itemColumn {
+	lines := calc.Compute(invoice).Lines
+	format := func(m money.Money) string { return amounts.Format(m) }
+	rate := func(_ int, item models.LineItem) string { return amounts.Format(unitRate(item.Rate, invoice.Currency)) }
+	lineDiscount := func(_ int, item models.LineItem) string { return lineDiscountLabel(item, amounts) }
+
+	var columns []itemColumn
+	if invoice.TaxInclusive {
+		columns = []itemColumn{
+			{"QTY", 15, qtyAlign, func(_ int, item models.LineItem) string { return quantityLabel(item) }},
+			{"PRICE", 22, "R", rate},
+			{"DISC", 15, "R", lineDiscount},
+			{"NET", 24, "R", func(i int, _ models.LineItem) string { return format(lines[i].Net) }},
+			{"TAX", 20, "R", func(i int, _ models.LineItem) string { return format(lines[i].Tax) }},
+			{"GROSS", 26, "R", func(i int, _ models.LineItem) string { return format(lines[i].Amount) }},
+		}
+	} else {
+		columns = []itemColumn{
+			{"QTY", 18, qtyAlign, func(_ int, item models.LineItem) string { return quantityLabel(item) }},
+			{"RATE", 25, "R", rate},
+			{"TAX%", 18, "R", func(_ int, item models.LineItem) string { return lineTaxLabel(item) }},
+			{"DISC", 18, "R", lineDiscount},
+			{"AMOUNT", 28, "R", func(i int, _ models.LineItem) string { return format(lines[i].Amount) }},
+		}
+	}
+
+	descWidth := width
+	for _, col := range columns {
+		descWidth -= col.width
+	}
+	description := itemColumn{"DESCRIPTION", descWidth, "L", func(_ int, item models.LineItem) string {
+		return truncateString(item.Description, int(descWidth*2/3))
+	}}
+	return append([]itemColumn{description}, columns...)
+}
+
+// lineDiscountLabel formats a line's discount for the items table: its rate,
+// or the amount of a fixed discount.
+func lineDiscountLabel(item models.LineItem, amounts amountFormat) string {
+	d := item.AppliedDiscount()
+	switch {
+	case d == nil:
+		return "0%"
+	case d.Kind == models.DiscountFixed:
+		return "-" + amounts.Format(d.Amount)
+	default:
+		return fmt.Sprintf("%g%%", d.Rate)
+	}
+}
+
+// discountLabel names an invoice-level discount in the totals block, e.g.
+// "Discount (10%)", "SPRING10 (15%)" or, for a fixed discount, its label.
+func discountLabel(d *models.Discount) string {
+	name := d.Label
+	if name == "" {
+		name = "Discount"
+	}
+	if d.Kind == models.DiscountFixed {
+		return name
+	}
+	return fmt.Sprintf("%s (%g%%)", name, d.Rate)
+}
+
+// adjustmentRows returns the totals rows between the subtotal and the total,
+// in the order they are applied: a pre-tax discount, the invoice-level
+// taxes, an after-tax discount, then any cash rounding.
+func adjustmentRows(invoice *models.Invoice, amounts amountFormat) []totalsRow {
+	rows := invoiceTaxRows(invoice, amounts)
+	if d := invoice.AppliedDiscount(); d != nil && !invoice.DiscountAmount.IsZero() {
+		discount := totalsRow{discountLabel(d), "-" + amounts.Format(invoice.DiscountAmount)}
+		if d.AfterTax {
+			rows = append(rows, discount)
+		} else {
+			rows = append([]totalsRow{discount}, rows...)
+		}
+	}
+	if !invoice.Rounding.IsZero() {
+		rows = append(rows, totalsRow{"Rounding", amounts.Format(invoice.Rounding)})
+	}
+	return rows
+}
+
+// lineTaxLabel formats a line's tax rates for the items table, e.g. "18%" or
+// "9+9%" for CGST and SGST.
+func lineTaxLabel(item models.LineItem) string {
+	taxes := item.AppliedTaxes()
+	if len(taxes) == 0 {
+		return "0%"
+	}
+	rates := make([]string, len(taxes))
+	for i, tax := range taxes {
+		rates[i] = fmt.Sprintf("%g", tax.Rate)
+	}
+	return strings.Join(rates, "+") + "%"
+}
+
+// taxRateLabel formats a tax rate for the tax summary, marking compound taxes.
+func taxRateLabel(line models.TaxLine) string {
+	if line.Compound {
+		return fmt.Sprintf("%g%% compound", line.Rate)
+	}
+	return fmt.Sprintf("%g%%", line.Rate)
+}
+
+// invoiceTaxRows returns a totals row for each invoice-level tax, e.g.
+// "VAT (20%)", or "Incl. VAT (20%)" when the total already includes it.
+// Line-level taxes are already in the line amounts.
+func invoiceTaxRows(invoice *models.Invoice, amounts amountFormat) []totalsRow {
+	var rows []totalsRow
+	for _, charge := range calc.Compute(invoice).InvoiceTaxes {
+		label := fmt.Sprintf("%s (%g%%)", charge.Name, charge.Rate)
+		if invoice.TaxInclusive {
+			label = "Incl. " + label
+		}
+		rows = append(rows, totalsRow{label, amounts.Format(charge.Amount)})
+	}
+	return rows
+}
+
+// drawTaxSummary prints the taxable amount and tax per tax name and rate as a
+// table starting at (x, y), and returns the y position below it. It prints
+// nothing for documents without taxes.
+func (g *Generator) drawTaxSummary(x, y float64, invoice *models.Invoice, amounts amountFormat) float64 {
+	if len(invoice.TaxSummary) == 0 {
+		return y
+	}
+	widths := []float64{28, 20, 22, 22}
+
+	g.pdf.SetFont("Arial", "B", 8)
+	g.pdf.SetTextColor(120, 120, 120)
+	g.pdf.SetXY(x, y)
+	title := "TAX SUMMARY"
+	if invoice.TaxInclusive {
+		title += " (INCLUDED IN PRICES)"
+	}
+	g.pdf.Cell(0, 5, title)
+	y += 5
+
+	g.pdf.SetXY(x, y)
+	for i, header := range []string{"Tax", "Rate", "Taxable", "Tax Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		g.pdf.CellFormat(widths[i], 5, header, "", 0, align, false, 0, "")
+	}
+	y += 5
+	g.pdf.SetDrawColor(220, 220, 220)
+	g.pdf.Line(x, y, x+92, y)
+	g.pdf.SetDrawColor(0, 0, 0)
+
+	g.pdf.SetFont("Arial", "", 8)
+	g.pdf.SetTextColor(0, 0, 0)
+	for _, line := range invoice.TaxSummary {
+		g.pdf.SetXY(x, y+0.5)
+		g.pdf.CellFormat(widths[0], 5, truncateString(line.Name, 18), "", 0, "L", false, 0, "")
+		g.pdf.CellFormat(widths[1], 5, taxRateLabel(line), "", 0, "R", false, 0, "")
+		g.pdf.CellFormat(widths[2], 5, amounts.Format(line.Base), "", 0, "R", false, 0, "")
+		g.pdf.CellFormat(widths[3], 5, amounts.Format(line.Amount), "", 0, "R", false, 0, "")
+		y += 5
+	}
+	return y + 1
+}
+
+// drawLateFees lists the invoice's late fees below y, one dated line each with
+// its description and amount, and returns the y position below them. It
+// prints nothing for invoices without late fees.
+func (g *Generator) drawLateFees(x, y float64, invoice *models.Invoice, amounts amountFormat) float64 {
+	if len(invoice.LateFees) == 0 {
+		return y
+	}
+	widths := []float64{25, 130, 25}
+	y += 8
+
+	g.pdf.SetFont("Arial", "B", 8)
+	g.pdf.SetTextColor(120, 120, 120)
+	g.pdf.SetXY(x, y)
+	g.pdf.Cell(0, 5, "LATE FEES")
+	y += 5
+
+	g.pdf.SetXY(x, y)
+	for i, header := range []string{"Date", "Description", "Amount"} {
+		align := "L"
+		if i == 2 {
+			align = "R"
+		}
+		g.pdf.CellFormat(widths[i], 5, header, "", 0, align, false, 0, "")
+	}
+	y += 5
+	g.pdf.SetDrawColor(220, 220, 220)
+	g.pdf.Line(x, y, x+180, y)
+	g.pdf.SetDrawColor(0, 0, 0)
+
+	g.pdf.SetFont("Arial", "", 8)
+	g.pdf.SetTextColor(0, 0, 0)
+	for _, fee := range invoice.LateFees {
+		g.pdf.SetXY(x, y+0.5)
+		g.pdf.CellFormat(widths[0], 5, fee.Period.String(), "", 0, "L", false, 0, "")
+		g.pdf.CellFormat(widths[1], 5, truncateString(fee.Description, 90), "", 0, "L", false, 0, "")
+		g.pdf.CellFormat(widths[2], 5, amounts.Format(fee.Amount), "", 0, "R", false, 0, "")
+		y += 5
+	}
+	return y + 1
+}
+
+// drawBaseCurrencyFooter prints the total and tax converted to the business's
+// base currency at the stamped exchange rate along the bottom of the page, if
+// the invoice asks for it and is in another currency.
+func (g *Generator) drawBaseCurrencyFooter(invoice *models.Invoice) {
+	if !invoice.ShowBaseCurrency || invoice.BaseCurrency == invoice.Currency {
+		return
+	}
+	total, ok := fx.ToBase(invoice, invoice.Total)
+	if !ok {
+		return
+	}
+	base := newAmountFormat(g.pdf, invoice.BaseCurrency)
+
+	parts := []string{"Total " + base.Format(total)}
+	if tax, _ := fx.ToBase(invoice, invoice.TaxAmount); !tax.IsZero() {
+		parts = append(parts, "Tax "+base.Format(tax))
+	}
+	line := fmt.Sprintf("Equivalent in %s at 1 %s = %s %s", invoice.BaseCurrency, invoice.Currency,
+		strconv.FormatFloat(invoice.ExchangeRate, 'f', -1, 64), invoice.BaseCurrency)
+	if !invoice.ExchangeRateDate.IsZero() {
+		line += fmt.Sprintf(" (rates of %s)", invoice.ExchangeRateDate)
+	}
+	line += ": " + strings.Join(parts, "  |  ")
+
+	g.pdf.SetFont("Arial", "", 8)
+	g.pdf.SetTextColor(120, 120, 120)
+	g.pdf.SetXY(15, 270)
+	g.pdf.CellFormat(180, 4, line, "", 0, "C", false, 0, "")
+	g.pdf.SetTextColor(0, 0, 0)
+}
+
+// totalsRow is a label and formatted amount in the totals block.
+type totalsRow struct {
+	label string
+	value string
+}
+
+// settlementRows returns the late fees, payments and credits to list between
+// the total and the balance due, or nil if there are none. The late fees are
+// itemised by drawLateFees.
+func settlementRows(invoice *models.Invoice, amounts amountFormat) []totalsRow {
+	var rows []totalsRow
+	if !invoice.LateFeeAmount.IsZero() {
+		rows = append(rows, totalsRow{"Late Fees", amounts.Format(invoice.LateFeeAmount)})
+	}
+	if !invoice.AmountPaid.IsZero() {
+		rows = append(rows, totalsRow{"Amount Paid", "-" + amounts.Format(invoice.AmountPaid)})
+	}
+	if !invoice.CreditedAmount.IsZero() {
+		rows = append(rows, totalsRow{"Credited", "-" + amounts.Format(invoice.CreditedAmount)})
+	}
+	return rows
+}
+
+// identityLine returns the business's tax ID and registration number as a
+// single header line, or "" if neither is set.
+func identityLine(invoice *models.Invoice) string {
+	var parts []string
+	if invoice.BusinessTaxID != "" {
+		parts = append(parts, "Tax ID: "+invoice.BusinessTaxID)
+	}
+	if invoice.BusinessRegistrationNumber != "" {
+		parts = append(parts, "Reg. No: "+invoice.BusinessRegistrationNumber)
+	}
+	return strings.Join(parts, "  |  ")
+}
 
-	if symbol, ok := symbols[currency]; ok {
-		return symbol
+// paymentRows returns the payment terms and bank details to print under the
+// notes, or nil if there are none. Credit notes carry no payment instructions,
+// and estimates show their terms but no bank details.
+func paymentRows(invoice *models.Invoice, amounts amountFormat) []totalsRow {
+	if invoice.IsCreditNote() {
+		return nil
+	}
+
+	var rows []totalsRow
+	add := func(label, value string) {
+		if value != "" {
+			rows = append(rows, totalsRow{label, value})
+		}
+	}
+	if t, err := terms.Parse(invoice.PaymentTerms); err == nil {
+		add("Terms", t.Label())
+		if t.HasDiscount() && !invoice.InvoiceDate.IsZero() {
+			discount := amounts.Format(invoice.Total.Percent(t.DiscountPercent))
+			add("Early Payment", fmt.Sprintf("%s off if paid by %s", discount, t.DiscountDeadline(invoice.InvoiceDate)))
+		}
+	}
+	if invoice.IsQuote() {
+		return rows
+	}
+
+	bank := invoice.BusinessBank
+	add("Bank", bank.BankName)
+	add("Account Name", bank.AccountName)
+	add("Account Number", bank.AccountNumber)
+	add("IBAN", groupIBAN(bank.IBAN))
+	add("BIC/SWIFT", bank.BIC)
+	return rows
+}
+
+// drawPaymentRows prints label/value pairs starting at (x, y).
+func (g *Generator) drawPaymentRows(x, y float64, rows []totalsRow) {
+	for i, row := range rows {
+		g.pdf.SetXY(x, y+5*float64(i))
+		g.pdf.SetFont("Arial", "", 9)
+		g.pdf.SetTextColor(100, 100, 100)
+		g.pdf.Cell(30, 5, row.label)
+		g.pdf.SetTextColor(0, 0, 0)
+		g.pdf.Cell(0, 5, row.value)
+	}
+	g.pdf.SetTextColor(0, 0, 0)
+}
+
+// groupIBAN splits an IBAN into blocks of four characters for printing.
+func groupIBAN(iban string) string {
+	var groups []string
+	for len(iban) > 4 {
+		groups = append(groups, iban[:4])
+		iban = iban[4:]
+	}
+	if iban != "" {
+		groups = append(groups, iban)
+	}
+	return strings.Join(groups, " ")
+}
+
+// amountFormat prints amounts in an invoice's currency, with the currency's
+// number of decimal places and its symbol on the side the currency puts it.
+type amountFormat struct {
+	symbol string // encoded for the PDF's core fonts
+	after  bool
+}
+
+// newAmountFormat returns the format for a currency code. Symbols the core
+// fonts cannot print, such as ₹ or ₽, are replaced by the currency code.
+func newAmountFormat(pdf *gofpdf.Fpdf, code string) amountFormat {
+	c, ok := currency.Lookup(code)
+	if !ok {
+		return amountFormat{symbol: code}
+	}
+	encoded := pdf.UnicodeTranslatorFromDescriptor("")(c.Symbol)
+	if strings.Count(encoded, ".") != strings.Count(c.Symbol, ".") {
+		return amountFormat{symbol: c.Code, after: c.SymbolAfter}
+	}
+	return amountFormat{symbol: encoded, after: c.SymbolAfter}
+}
+
+// Format prints an amount, e.g. "$12.00", "-$12.00", "CHF 12.00", "¥1200" or
+// "12.00 kr". Symbols ending in a letter are separated from the amount by a
+// space.
+func (f amountFormat) Format(m money.Money) string {
+	switch {
+	case f.symbol == "":
+		return m.String()
+	case f.after:
+		return m.String() + " " + f.symbol
+	}
+	last, _ := utf8.DecodeLastRuneInString(f.symbol)
+	if unicode.IsSymbol(last) {
+		return m.Format(f.symbol)
 	}
-	return currency + " "
+	return m.Format(f.symbol + " ")
 }
 
 func truncateString(s string, maxLen int) string {
@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"bytes"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func sampleInvoice(docType models.DocumentType, code string) *models.Invoice {
+	invoice := &models.Invoice{
+		DocumentType:  docType,
+		InvoiceNumber: "INV-2026-00001",
+		InvoiceDate:   civil.New(2026, time.March, 1),
+		DueDate:       civil.New(2026, time.March, 31),
+		ValidUntil:    civil.New(2026, time.March, 31),
+		Currency:      code,
+		BusinessName:  "Acme Ltd",
+		ClientName:    "Globex",
+		Items: []models.LineItem{
+			{Description: "Design", Quantity: 2, Unit: "h", Rate: money.FromFloat(120, code), TaxRate: 20},
+			{Description: "Hosting", Quantity: 1, Rate: money.FromFloat(50, code), DiscountRate: 10},
+		},
+		Notes: "Thank you for your business",
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func TestGenerateInvoice_Templates(t *testing.T) {
+	for _, template := range append(Templates, "unknown") {
+		for _, docType := range []models.DocumentType{models.DocumentInvoice, models.DocumentCreditNote, models.DocumentQuote} {
+			for _, code := range []string{"USD", "JPY", "EUR"} {
+				invoice := sampleInvoice(docType, code)
+				invoice.SelectedTemplate = template
+
+				data, err := NewGenerator().GenerateInvoice(invoice)
+				if err != nil {
+					t.Fatalf("%s %s %s: GenerateInvoice failed: %v", template, docType, code, err)
+				}
+				if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.Contains(data[len(data)-16:], []byte("%%EOF")) {
+					t.Errorf("%s %s %s: output is not a complete PDF", template, docType, code)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateInvoice_ListsLateFees(t *testing.T) {
+	for _, template := range Templates {
+		invoice := sampleInvoice(models.DocumentInvoice, "USD")
+		invoice.SelectedTemplate = template
+		invoice.LateFees = []models.LateFee{
+			{Period: civil.New(2026, time.April, 1), Description: "Late fee: 1.5% of 230.00 USD overdue (from 2026-04-01)", Amount: money.New(345, "USD")},
+			{Period: civil.New(2026, time.May, 1), Description: "Late fee (from 2026-05-01)", Amount: money.New(2500, "USD")},
+		}
+		calc.Settle(invoice)
+
+		g := NewGenerator()
+		g.pdf.SetCompression(false)
+		data, err := g.GenerateInvoice(invoice)
+		if err != nil {
+			t.Fatalf("%s: GenerateInvoice failed: %v", template, err)
+		}
+		for _, text := range []string{"2026-04-01", "Late fee: 1.5% of 230.00 USD overdue", "3.45", "2026-05-01", "Late fee \\(from 2026-05-01\\)", "25.00"} {
+			if !bytes.Contains(data, []byte(text)) {
+				t.Errorf("%s: expected the PDF to show %q", template, text)
+			}
+		}
+	}
+}
+
+func TestDocumentHeadings(t *testing.T) {
+	tests := []struct {
+		invoice *models.Invoice
+		title   string
+		ref     string
+		due     string
+	}{
+		{&models.Invoice{InvoiceNumber: "INV-1", QuoteNumber: "EST-1"}, "INVOICE", "#INV-1  (from estimate #EST-1)", "Due Date"},
+		{&models.Invoice{DocumentType: models.DocumentCreditNote, InvoiceNumber: "CN-1", OriginalInvoiceNumber: "INV-1"}, "CREDIT NOTE", "#CN-1  (credit for invoice #INV-1)", "Due Date"},
+		{&models.Invoice{DocumentType: models.DocumentQuote, InvoiceNumber: "EST-1"}, "ESTIMATE", "#EST-1", "Valid Until"},
+		{&models.Invoice{QuoteNumber: "EST-1"}, "INVOICE", "Draft  (from estimate #EST-1)", "Due Date"},
+	}
+	for _, tt := range tests {
+		if got := documentTitle(tt.invoice); got != tt.title {
+			t.Errorf("documentTitle: got %q, want %q", got, tt.title)
+		}
+		if got := documentReference(tt.invoice); got != tt.ref {
+			t.Errorf("documentReference: got %q, want %q", got, tt.ref)
+		}
+		if _, due := documentDates(tt.invoice); due.label != tt.due {
+			t.Errorf("documentDates: got %q, want %q", due.label, tt.due)
+		}
+	}
+}
+
+func TestGroupIBAN(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"DE89":                   "DE89",
+		"DE89370400440532013000": "DE89 3704 0044 0532 0130 00",
+		"GB29NWBK60161331926819": "GB29 NWBK 6016 1331 9268 19",
+	}
+	for iban, want := range tests {
+		if got := groupIBAN(iban); got != want {
+			t.Errorf("groupIBAN(%q) = %q, want %q", iban, got, want)
+		}
+	}
+}
+
+func TestQuantityLabel(t *testing.T) {
+	tests := []struct {
+		item models.LineItem
+		want string
+	}{
+		{models.LineItem{Quantity: 3}, "3"},
+		{models.LineItem{Quantity: 1.5, Unit: "hours"}, "1.5 hours"},
+		{models.LineItem{Quantity: 0.25, Unit: "days"}, "0.25 days"},
+		{models.LineItem{Quantity: 2.125}, "2.125"},
+	}
+	for _, tt := range tests {
+		if got := quantityLabel(tt.item); got != tt.want {
+			t.Errorf("quantityLabel(%v) = %q, want %q", tt.item.Quantity, got, tt.want)
+		}
+	}
+}
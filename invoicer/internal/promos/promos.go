@@ -0,0 +1,71 @@
+package promos
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrExpired is returned when a promo code is used after its expiry date.
+	ErrExpired = errors.New("promo code has expired")
+
+	// ErrUsedUp is returned when a promo code has been used MaxUses times.
+	ErrUsedUp = errors.New("promo code has reached its usage limit")
+
+	// ErrCurrencyMismatch is returned when a fixed-amount promo code is used
+	// on an invoice in another currency.
+	ErrCurrencyMismatch = errors.New("promo code is for another currency")
+)
+
+// codePattern is the format of a normalized promo code.
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// Normalize returns the stored form of a promo code: trimmed and upper case.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateCode checks the format of a normalized promo code.
+func ValidateCode(code string) error {
+	if !codePattern.MatchString(code) {
+		return fmt.Errorf("code must be 3 to 32 letters, digits, dashes or underscores")
+	}
+	return nil
+}
+
+// Usable returns ErrExpired if the code expired before today, or ErrUsedUp if
+// it has no uses left.
+func Usable(p *models.PromoCode, today civil.Date) error {
+	if !p.ExpiresOn.IsZero() && p.ExpiresOn.Before(today) {
+		return fmt.Errorf("%s: %w", p.Code, ErrExpired)
+	}
+	if p.MaxUses > 0 && p.Uses >= p.MaxUses {
+		return fmt.Errorf("%s: %w", p.Code, ErrUsedUp)
+	}
+	return nil
+}
+
+// Apply replaces the invoice's discount with the promo code's. The discount
+// is labelled with the code unless it has a label of its own. A fixed-amount
+// code only applies to invoices in its currency; others return
+// ErrCurrencyMismatch and are left unchanged.
+func Apply(invoice *models.Invoice, p *models.PromoCode) error {
+	discount := p.Discount
+	if discount.Kind == models.DiscountFixed {
+		if p.Currency != invoice.Currency {
+			return fmt.Errorf("%s is for %s, not %s: %w", p.Code, p.Currency, invoice.Currency, ErrCurrencyMismatch)
+		}
+		discount.Amount = discount.Amount.WithCurrency(p.Currency)
+	}
+	if discount.Label == "" {
+		discount.Label = p.Code
+	}
+	invoice.PromoCode = p.Code
+	invoice.Discount = &discount
+	invoice.DiscountRate = 0
+	return nil
+}
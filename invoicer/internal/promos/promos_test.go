@@ -0,0 +1,84 @@
+package promos
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+)
+
+func TestValidateCode(t *testing.T) {
+	for _, code := range []string{"SPRING10", "VIP_2026", "BLACK-FRIDAY"} {
+		if err := ValidateCode(code); err != nil {
+			t.Errorf("expected %q to be valid, got %v", code, err)
+		}
+	}
+	for _, code := range []string{"", "AB", "SPRING 10", "spring10", "€10OFF"} {
+		if err := ValidateCode(code); err == nil {
+			t.Errorf("expected %q to be rejected", code)
+		}
+	}
+	if got := Normalize("  spring10 "); got != "SPRING10" {
+		t.Errorf("expected SPRING10, got %q", got)
+	}
+}
+
+func TestUsable(t *testing.T) {
+	code := &models.PromoCode{Code: "SPRING10", ExpiresOn: civil.MustParse("2026-04-30"), MaxUses: 2, Uses: 1}
+
+	if err := Usable(code, civil.MustParse("2026-04-30")); err != nil {
+		t.Errorf("expected the code to be usable on its expiry date, got %v", err)
+	}
+	if err := Usable(code, civil.MustParse("2026-05-01")); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired the day after, got %v", err)
+	}
+
+	code.Uses = 2
+	if err := Usable(code, civil.MustParse("2026-04-01")); !errors.Is(err, ErrUsedUp) {
+		t.Errorf("expected ErrUsedUp, got %v", err)
+	}
+
+	unlimited := &models.PromoCode{Code: "ALWAYS", Uses: 1000}
+	if err := Usable(unlimited, civil.MustParse("2030-01-01")); err != nil {
+		t.Errorf("expected a code without limits to be usable, got %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	invoice := &models.Invoice{DiscountRate: 5}
+	if err := Apply(invoice, &models.PromoCode{Code: "SPRING10", Discount: models.Discount{Kind: models.DiscountPercent, Rate: 10}}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if invoice.PromoCode != "SPRING10" || invoice.DiscountRate != 0 {
+		t.Errorf("expected the code to replace the discount rate, got %q / %v", invoice.PromoCode, invoice.DiscountRate)
+	}
+	if d := invoice.Discount; d == nil || d.Label != "SPRING10" || d.Rate != 10 {
+		t.Errorf("expected a 10%% discount labelled SPRING10, got %+v", d)
+	}
+}
+
+func TestApply_FixedAmountNeedsMatchingCurrency(t *testing.T) {
+	code := &models.PromoCode{
+		Code:     "TENOFF",
+		Discount: models.Discount{Kind: models.DiscountFixed, Amount: money.New(1000, "")},
+		Currency: "USD",
+	}
+
+	euros := &models.Invoice{Currency: "EUR", DiscountRate: 5}
+	if err := Apply(euros, code); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if euros.Discount != nil || euros.PromoCode != "" || euros.DiscountRate != 5 {
+		t.Errorf("expected a rejected code to leave the invoice unchanged, got %+v", euros)
+	}
+
+	dollars := &models.Invoice{Currency: "USD"}
+	if err := Apply(dollars, code); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if d := dollars.Discount; d == nil || d.Amount.String() != "10.00" || d.Amount.Currency() != "USD" {
+		t.Errorf("expected a USD 10.00 discount, got %+v", d)
+	}
+}
@@ -0,0 +1,89 @@
+package quotes
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/terms"
+	"time"
+)
+
+var (
+	// ErrNotAccepted is returned when converting a quote the client has not accepted.
+	ErrNotAccepted = errors.New("only accepted quotes can be converted to invoices")
+
+	// ErrAlreadyConverted is returned when converting a quote a second time.
+	ErrAlreadyConverted = errors.New("quote has already been converted to an invoice")
+
+	// ErrExpired is returned when accepting a quote after its validUntil date.
+	ErrExpired = errors.New("quote has expired")
+)
+
+// Validate checks the quote's validity date.
+func Validate(quote *models.Invoice) error {
+	if !quote.ValidUntil.IsZero() && quote.ValidUntil.Before(quote.InvoiceDate) {
+		return fmt.Errorf("validUntil must not be before the quote date")
+	}
+	return nil
+}
+
+// IsExpired reports whether the quote's validUntil date has passed at the given time.
+func IsExpired(quote *models.Invoice, at time.Time) bool {
+	return !quote.ValidUntil.IsZero() && civil.Of(at).After(quote.ValidUntil)
+}
+
+// Convert builds a draft invoice from an accepted quote. The invoice is dated
+// at; its due date follows the quote's payment terms or, without terms, keeps
+// the quote's distance between date and due date. It links back to the quote
+// and has no number yet.
+func Convert(quote *models.Invoice, at time.Time) (*models.Invoice, error) {
+	if err := checkConvertible(quote); err != nil {
+		return nil, err
+	}
+
+	invoice := quote.Clone()
+	invoice.ID = ""
+	invoice.UserID = ""
+	invoice.DocumentType = models.DocumentInvoice
+	invoice.InvoiceNumber = ""
+	invoice.ValidUntil = civil.Date{}
+	invoice.ConvertedInvoiceID = ""
+	invoice.QuoteID = quote.ID
+	invoice.QuoteNumber = quote.InvoiceNumber
+	invoice.Payments = nil
+	invoice.CreditNotes = nil
+
+	invoice.InvoiceDate = civil.Of(at)
+	invoice.DueDate = civil.Date{}
+	if !quote.DueDate.IsZero() && !quote.InvoiceDate.IsZero() {
+		invoice.DueDate = invoice.InvoiceDate.AddDays(quote.InvoiceDate.DaysUntil(quote.DueDate))
+	}
+	terms.Apply(invoice)
+
+	lifecycle.Init(invoice, at)
+	calc.Apply(invoice)
+	return invoice, nil
+}
+
+// MarkConverted records the invoice an accepted quote was converted into.
+func MarkConverted(quote, invoice *models.Invoice) error {
+	if err := checkConvertible(quote); err != nil {
+		return err
+	}
+	quote.ConvertedInvoiceID = invoice.ID
+	return nil
+}
+
+// checkConvertible ensures the quote is accepted and not yet converted.
+func checkConvertible(quote *models.Invoice) error {
+	if !quote.IsQuote() || lifecycle.Current(quote) != models.StatusAccepted {
+		return ErrNotAccepted
+	}
+	if quote.ConvertedInvoiceID != "" {
+		return fmt.Errorf("%w (invoice %s)", ErrAlreadyConverted, quote.ConvertedInvoiceID)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package quotes
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+// acceptedQuote returns an accepted quote for $200.00, dated 1 March 2026
+// with payment due 30 days later.
+func acceptedQuote() *models.Invoice {
+	quote := &models.Invoice{
+		ID:            "inv_1",
+		DocumentType:  models.DocumentQuote,
+		Status:        models.StatusAccepted,
+		InvoiceNumber: "EST-2026-00001",
+		InvoiceDate:   civil.MustParse("2026-03-01"),
+		DueDate:       civil.MustParse("2026-03-31"),
+		ValidUntil:    civil.MustParse("2026-03-15"),
+		BusinessName:  "Acme",
+		ClientName:    "Globex",
+		Currency:      "USD",
+		Items:         []models.LineItem{{Description: "Design", Quantity: 2, Rate: money.New(10000, "USD")}},
+	}
+	calc.Apply(quote)
+	return quote
+}
+
+func TestValidate(t *testing.T) {
+	quote := acceptedQuote()
+	if err := Validate(quote); err != nil {
+		t.Errorf("expected valid quote, got %v", err)
+	}
+
+	quote.ValidUntil = civil.MustParse("2026-02-28")
+	if err := Validate(quote); err == nil {
+		t.Error("expected validUntil before the quote date to be rejected")
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	quote := acceptedQuote()
+	if IsExpired(quote, time.Date(2026, time.March, 15, 23, 0, 0, 0, time.UTC)) {
+		t.Error("expected quote to be valid through its validUntil date")
+	}
+	if !IsExpired(quote, time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected quote to expire the day after validUntil")
+	}
+
+	quote.ValidUntil = civil.Date{}
+	if IsExpired(quote, time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected quote without validUntil never to expire")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	quote := acceptedQuote()
+	at := time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	invoice, err := Convert(quote, at)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if invoice.DocumentType != models.DocumentInvoice || invoice.Status != models.StatusDraft {
+		t.Errorf("expected a draft invoice, got %q / %q", invoice.DocumentType, invoice.Status)
+	}
+	if invoice.QuoteID != "inv_1" || invoice.QuoteNumber != "EST-2026-00001" {
+		t.Errorf("expected link back to the quote, got %q / %q", invoice.QuoteID, invoice.QuoteNumber)
+	}
+	if invoice.ID != "" || invoice.InvoiceNumber != "" || !invoice.ValidUntil.IsZero() {
+		t.Errorf("expected ID, number and validUntil to be cleared, got %q %q %q", invoice.ID, invoice.InvoiceNumber, invoice.ValidUntil)
+	}
+	if invoice.InvoiceDate.String() != "2026-03-10" || invoice.DueDate.String() != "2026-04-09" {
+		t.Errorf("expected dates shifted to the conversion date, got %s / %s", invoice.InvoiceDate, invoice.DueDate)
+	}
+	if invoice.Total != quote.Total {
+		t.Errorf("expected total %s, got %s", quote.Total, invoice.Total)
+	}
+
+	invoice.ID = "inv_2"
+	if err := MarkConverted(quote, invoice); err != nil {
+		t.Fatalf("MarkConverted failed: %v", err)
+	}
+	if quote.ConvertedInvoiceID != "inv_2" {
+		t.Errorf("expected quote to record the invoice, got %q", quote.ConvertedInvoiceID)
+	}
+	if _, err := Convert(quote, at); !errors.Is(err, ErrAlreadyConverted) {
+		t.Errorf("expected ErrAlreadyConverted, got %v", err)
+	}
+}
+
+func TestConvert_NotAccepted(t *testing.T) {
+	for _, status := range []models.InvoiceStatus{models.StatusDraft, models.StatusSent, models.StatusDeclined} {
+		quote := acceptedQuote()
+		quote.Status = status
+		if _, err := Convert(quote, time.Now()); !errors.Is(err, ErrNotAccepted) {
+			t.Errorf("%s: expected ErrNotAccepted, got %v", status, err)
+		}
+	}
+
+	invoice := acceptedQuote()
+	invoice.DocumentType = models.DocumentInvoice
+	if _, err := Convert(invoice, time.Now()); !errors.Is(err, ErrNotAccepted) {
+		t.Errorf("expected invoices not to convert, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package recurring
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/terms"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Validate checks the schedule's timing fields.
+func Validate(s *models.RecurringSchedule) error {
+	switch s.Cadence {
+	case models.CadenceWeekly, models.CadenceMonthly, models.CadenceQuarterly:
+	case models.CadenceCron:
+		if _, err := cron.ParseStandard(s.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression %q: %v", s.Cron, err)
+		}
+	default:
+		return fmt.Errorf("invalid cadence %q: must be %q, %q, %q or %q", s.Cadence,
+			models.CadenceWeekly, models.CadenceMonthly, models.CadenceQuarterly, models.CadenceCron)
+	}
+
+	if s.StartDate.IsZero() {
+		return fmt.Errorf("startDate is required")
+	}
+	if !s.EndDate.IsZero() && s.EndDate.Before(s.StartDate) {
+		return fmt.Errorf("endDate must not be before startDate")
+	}
+	if s.MaxOccurrences < 0 {
+		return fmt.Errorf("maxOccurrences must not be negative")
+	}
+	return nil
+}
+
+// Occurrence returns the date of the n-th occurrence, counting from 0, and
+// false if the schedule ends before it. Occurrences are derived from the start
+// date alone, so the same n always yields the same date.
+func Occurrence(s *models.RecurringSchedule, n int) (civil.Date, bool, error) {
+	if n < 0 || (s.MaxOccurrences > 0 && n >= s.MaxOccurrences) {
+		return civil.Date{}, false, nil
+	}
+	if s.StartDate.IsZero() {
+		return civil.Date{}, false, fmt.Errorf("schedule has no startDate")
+	}
+
+	var date civil.Date
+	switch s.Cadence {
+	case models.CadenceWeekly:
+		date = s.StartDate.AddDays(7 * n)
+	case models.CadenceMonthly:
+		date = s.StartDate.AddMonths(n)
+	case models.CadenceQuarterly:
+		date = s.StartDate.AddMonths(3 * n)
+	case models.CadenceCron:
+		var err error
+		if date, err = cronOccurrence(s.Cron, s.StartDate, n); err != nil {
+			return civil.Date{}, false, err
+		}
+	default:
+		return civil.Date{}, false, fmt.Errorf("invalid cadence %q", s.Cadence)
+	}
+
+	return date, within(s, n, date), nil
+}
+
+// within reports whether the schedule still has an n-th occurrence on date.
+func within(s *models.RecurringSchedule, n int, date civil.Date) bool {
+	if s.MaxOccurrences > 0 && n >= s.MaxOccurrences {
+		return false
+	}
+	return s.EndDate.IsZero() || !date.After(s.EndDate)
+}
+
+// BuildInvoice copies the schedule's base invoice for the occurrence on date.
+// The invoice date becomes the occurrence date. The due date follows the
+// payment terms or, without terms, keeps its distance from the invoice date
+// (or from the start date, if the base invoice has no invoice date).
+func BuildInvoice(s *models.RecurringSchedule, date civil.Date) *models.Invoice {
+	invoice := s.Template.Clone()
+	invoice.ID = ""
+	invoice.InvoiceNumber = ""
+	invoice.Status = ""
+	invoice.StatusHistory = nil
+	invoice.Payments = nil
+	invoice.CreditNotes = nil
+	invoice.RecurringID = s.ID
+	invoice.RecurringPeriod = date.String()
+
+	invoice.InvoiceDate = date
+	if due := s.Template.DueDate; !due.IsZero() {
+		base := s.Template.InvoiceDate
+		if base.IsZero() {
+			base = s.StartDate
+		}
+		invoice.DueDate = invoice.InvoiceDate.AddDays(base.DaysUntil(due))
+	}
+	terms.Apply(invoice)
+	return invoice
+}
+
+// cronOccurrence returns the n-th day, on or after start, on which the cron
+// expression fires. Expressions that fire several times a day count once per day.
+func cronOccurrence(expr string, start civil.Date, n int) (civil.Date, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return civil.Date{}, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+
+	next := start.Time().Add(-time.Nanosecond)
+	var day civil.Date
+	for i := 0; i <= n; i++ {
+		fire := schedule.Next(next)
+		if fire.IsZero() {
+			return civil.Date{}, fmt.Errorf("cron expression %q never fires", expr)
+		}
+		day = civil.New(fire.Year(), fire.Month(), fire.Day())
+		next = day.AddDays(1).Time().Add(-time.Nanosecond)
+	}
+	return day, nil
+}
+
+// NextRun returns the date of the schedule's next occurrence, the first it has
+// neither generated nor skipped, or the zero Date once the schedule has finished.
+func NextRun(s *models.RecurringSchedule) civil.Date {
+	date, ok, err := Occurrence(s, s.Occurrences+s.Skipped)
+	if err != nil || !ok {
+		return civil.Date{}
+	}
+	return date
+}
+
+// Advance moves NextRun on to the occurrence after it, once the schedule has
+// counted it in Occurrences or Skipped. A cron schedule carries on from the occurrence
+// just passed instead of walking its expression from the start date again.
+func Advance(s *models.RecurringSchedule) {
+	if s.Cadence != models.CadenceCron || s.NextRun.IsZero() {
+		s.NextRun = NextRun(s)
+		return
+	}
+	date, err := cronOccurrence(s.Cron, s.NextRun.AddDays(1), 0)
+	if err != nil || !within(s, s.Occurrences+s.Skipped, date) {
+		s.NextRun = civil.Date{}
+		return
+	}
+	s.NextRun = date
+}
+
+// Resume skips the occurrences dated before today, so that a schedule that is
+// unpaused does not bill the periods it was paused for. Skipped occurrences
+// still count towards MaxOccurrences.
+func Resume(s *models.RecurringSchedule, today civil.Date) {
+	for !s.NextRun.IsZero() && s.NextRun.Before(today) {
+		s.Skipped++
+		Advance(s)
+	}
+}
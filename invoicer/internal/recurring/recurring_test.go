@@ -0,0 +1,173 @@
+package recurring
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func occurrences(t *testing.T, s *models.RecurringSchedule, count int) []string {
+	t.Helper()
+	var dates []string
+	for n := 0; n < count; n++ {
+		date, ok, err := Occurrence(s, n)
+		if err != nil {
+			t.Fatalf("Occurrence(%d) failed: %v", n, err)
+		}
+		if !ok {
+			break
+		}
+		dates = append(dates, date.String())
+	}
+	return dates
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOccurrence_Cadences(t *testing.T) {
+	cases := []struct {
+		schedule models.RecurringSchedule
+		want     []string
+	}{
+		{
+			models.RecurringSchedule{Cadence: models.CadenceWeekly, StartDate: civil.MustParse("2026-01-01")},
+			[]string{"2026-01-01", "2026-01-08", "2026-01-15"},
+		},
+		{
+			// Month ends are clamped without drifting the day for later months
+			models.RecurringSchedule{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-31")},
+			[]string{"2026-01-31", "2026-02-28", "2026-03-31"},
+		},
+		{
+			models.RecurringSchedule{Cadence: models.CadenceQuarterly, StartDate: civil.MustParse("2025-11-15")},
+			[]string{"2025-11-15", "2026-02-15", "2026-05-15"},
+		},
+		{
+			// 09:00 on the first of each month
+			models.RecurringSchedule{Cadence: models.CadenceCron, Cron: "0 9 1 * *", StartDate: civil.MustParse("2026-01-02")},
+			[]string{"2026-02-01", "2026-03-01", "2026-04-01"},
+		},
+		{
+			// Several firings on the same day count once
+			models.RecurringSchedule{Cadence: models.CadenceCron, Cron: "*/30 * * * *", StartDate: civil.MustParse("2026-01-01")},
+			[]string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+	}
+	for _, c := range cases {
+		if got := occurrences(t, &c.schedule, 3); !equal(got, c.want) {
+			t.Errorf("%s %s: expected %v, got %v", c.schedule.Cadence, c.schedule.Cron, c.want, got)
+		}
+	}
+}
+
+func TestOccurrence_Limits(t *testing.T) {
+	byCount := &models.RecurringSchedule{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-10"), MaxOccurrences: 2}
+	if got := occurrences(t, byCount, 5); !equal(got, []string{"2026-01-10", "2026-02-10"}) {
+		t.Errorf("expected 2 occurrences, got %v", got)
+	}
+
+	byDate := &models.RecurringSchedule{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-10"), EndDate: civil.MustParse("2026-03-10")}
+	if got := occurrences(t, byDate, 5); !equal(got, []string{"2026-01-10", "2026-02-10", "2026-03-10"}) {
+		t.Errorf("expected end date to be inclusive, got %v", got)
+	}
+	byDate.Occurrences = 3
+	if next := NextRun(byDate); !next.IsZero() {
+		t.Errorf("expected finished schedule to have no next run, got %s", next)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	cases := []models.RecurringSchedule{
+		{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-31")},
+		{Cadence: models.CadenceCron, Cron: "0 9 * * 1-5", StartDate: civil.MustParse("2026-01-01")},
+		{Cadence: models.CadenceCron, Cron: "0 9 * * 1", StartDate: civil.MustParse("2026-01-01"), MaxOccurrences: 4},
+	}
+	for _, s := range cases {
+		want := occurrences(t, &s, 6)
+		var got []string
+		for s.NextRun = NextRun(&s); !s.NextRun.IsZero() && len(got) < 6; Advance(&s) {
+			got = append(got, s.NextRun.String())
+			s.Occurrences++
+		}
+		if !equal(got, want) {
+			t.Errorf("%s %s: expected %v, got %v", s.Cadence, s.Cron, want, got)
+		}
+	}
+
+	// A cron schedule continues from its next run, however many occurrences
+	// lie behind it.
+	mondays := &models.RecurringSchedule{Cadence: models.CadenceCron, Cron: "0 9 * * 1", StartDate: civil.MustParse("2000-01-03"),
+		Occurrences: 1366, NextRun: civil.MustParse("2026-03-02")}
+	if Advance(mondays); mondays.NextRun.String() != "2026-03-09" {
+		t.Errorf("expected the following Monday, got %s", mondays.NextRun)
+	}
+}
+
+func TestResume(t *testing.T) {
+	s := &models.RecurringSchedule{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-10"), MaxOccurrences: 6,
+		Occurrences: 2, LastRun: civil.MustParse("2026-02-10")}
+	s.NextRun = NextRun(s)
+
+	Resume(s, civil.MustParse("2026-05-10"))
+	if s.Skipped != 2 || s.NextRun.String() != "2026-05-10" {
+		t.Errorf("expected March and April to be skipped, got %d skipped and next %s", s.Skipped, s.NextRun)
+	}
+
+	Resume(s, civil.MustParse("2027-01-01"))
+	if s.Skipped != 4 || !s.NextRun.IsZero() {
+		t.Errorf("expected skipped periods to count towards the limit, got %d skipped and next %s", s.Skipped, s.NextRun)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []models.RecurringSchedule{
+		{Cadence: "daily", StartDate: civil.MustParse("2026-01-01")},
+		{Cadence: models.CadenceCron, Cron: "not a cron", StartDate: civil.MustParse("2026-01-01")},
+		{Cadence: models.CadenceMonthly},
+		{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-02-01"), EndDate: civil.MustParse("2026-01-01")},
+		{Cadence: models.CadenceMonthly, StartDate: civil.MustParse("2026-01-01"), MaxOccurrences: -1},
+	}
+	for _, s := range invalid {
+		if err := Validate(&s); err == nil {
+			t.Errorf("expected %+v to be invalid", s)
+		}
+	}
+}
+
+func TestBuildInvoice_ShiftsDates(t *testing.T) {
+	s := &models.RecurringSchedule{
+		ID:        "rec_1",
+		Cadence:   models.CadenceMonthly,
+		StartDate: civil.MustParse("2026-01-01"),
+		Template: models.Invoice{
+			InvoiceNumber: "IGNORED",
+			InvoiceDate:   civil.MustParse("2026-01-01"),
+			DueDate:       civil.MustParse("2026-01-15"),
+			ClientName:    "Globex",
+		},
+	}
+
+	invoice := BuildInvoice(s, civil.MustParse("2026-03-01"))
+	if invoice.InvoiceDate.String() != "2026-03-01" || invoice.DueDate.String() != "2026-03-15" {
+		t.Errorf("expected dates 2026-03-01 / 2026-03-15, got %s / %s", invoice.InvoiceDate, invoice.DueDate)
+	}
+	if invoice.InvoiceNumber != "" || invoice.RecurringID != "rec_1" || invoice.RecurringPeriod != "2026-03-01" {
+		t.Errorf("unexpected recurring fields: number %q, id %q, period %q", invoice.InvoiceNumber, invoice.RecurringID, invoice.RecurringPeriod)
+	}
+
+	s.Template.PaymentTerms = "eom"
+	invoice = BuildInvoice(s, civil.MustParse("2026-02-01"))
+	if invoice.DueDate.String() != "2026-02-28" {
+		t.Errorf("expected payment terms to set the due date 2026-02-28, got %s", invoice.DueDate)
+	}
+}
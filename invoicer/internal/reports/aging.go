@@ -0,0 +1,234 @@
+package reports
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Aging buckets, by days past the due date.
+const (
+	BucketCurrent = "current"
+	Bucket1To30   = "1-30"
+	Bucket31To60  = "31-60"
+	Bucket61To90  = "61-90"
+	BucketOver90  = "90+"
+)
+
+// AgingBuckets splits an outstanding balance by how long it is overdue.
+type AgingBuckets struct {
+	Current    money.Money `json:"current"` // not yet due
+	Days1To30  money.Money `json:"days1To30"`
+	Days31To60 money.Money `json:"days31To60"`
+	Days61To90 money.Money `json:"days61To90"`
+	Over90     money.Money `json:"over90"`
+	Total      money.Money `json:"total"`
+}
+
+// AgingRow is the receivables of one client in one currency.
+type AgingRow struct {
+	ClientID   string `json:"clientId,omitempty"`
+	ClientName string `json:"clientName"`
+	Currency   string `json:"currency"`
+	Invoices   int    `json:"invoices"`
+	AgingBuckets
+}
+
+// AgingTotal is the receivables of all clients in one currency.
+type AgingTotal struct {
+	Currency string `json:"currency"`
+	Invoices int    `json:"invoices"`
+	AgingBuckets
+}
+
+// AgingInvoice is one invoice with a balance outstanding.
+type AgingInvoice struct {
+	ID            string      `json:"id"`
+	InvoiceNumber string      `json:"invoiceNumber"`
+	ClientID      string      `json:"clientId,omitempty"`
+	ClientName    string      `json:"clientName"`
+	Currency      string      `json:"currency"`
+	InvoiceDate   civil.Date  `json:"invoiceDate"`
+	DueDate       civil.Date  `json:"dueDate"`
+	DaysPastDue   int         `json:"daysPastDue"`
+	Bucket        string      `json:"bucket"`
+	Balance       money.Money `json:"balance"`
+}
+
+// AgingReport is the accounts receivable aging on a given date.
+type AgingReport struct {
+	AsOf     civil.Date     `json:"asOf"`
+	Rows     []AgingRow     `json:"rows"`
+	Totals   []AgingTotal   `json:"totals"`
+	Invoices []AgingInvoice `json:"invoices"`
+}
+
+// Aging computes the receivables outstanding at the end of asOf from the
+// user's documents. An invoice counts if it is dated on or before asOf and
+// has not been voided; its balance is its total plus the late fees charged,
+// less the payments and credit notes recorded, on or before asOf, so past
+// dates give the aging as it was then. Invoices without a due date are due on their invoice date.
+func Aging(invoices []*models.Invoice, asOf civil.Date) *AgingReport {
+	report := &AgingReport{AsOf: asOf, Rows: []AgingRow{}, Totals: []AgingTotal{}, Invoices: []AgingInvoice{}}
+	rows := make(map[string]*AgingRow)
+	totals := make(map[string]*AgingTotal)
+
+	for _, invoice := range invoices {
+		if !issued(invoice) || invoice.IsCreditNote() || invoice.InvoiceDate.IsZero() || invoice.InvoiceDate.After(asOf) {
+			continue
+		}
+		balance := balanceOn(invoice, asOf)
+		if !balance.IsPositive() {
+			continue
+		}
+
+		due := invoice.DueDate
+		if due.IsZero() {
+			due = invoice.InvoiceDate
+		}
+		days := due.DaysUntil(asOf)
+		bucket := bucketFor(days)
+		if days < 0 {
+			days = 0
+		}
+		report.Invoices = append(report.Invoices, AgingInvoice{
+			ID:            invoice.ID,
+			InvoiceNumber: invoice.InvoiceNumber,
+			ClientID:      invoice.ClientID,
+			ClientName:    invoice.ClientName,
+			Currency:      invoice.Currency,
+			InvoiceDate:   invoice.InvoiceDate,
+			DueDate:       due,
+			DaysPastDue:   days,
+			Bucket:        bucket,
+			Balance:       balance,
+		})
+
+		k := client(invoice) + "|" + invoice.Currency
+		row, ok := rows[k]
+		if !ok {
+			row = &AgingRow{ClientID: invoice.ClientID, ClientName: invoice.ClientName, Currency: invoice.Currency, AgingBuckets: emptyBuckets(invoice.Currency)}
+			rows[k] = row
+		}
+		row.Invoices++
+		row.add(bucket, balance)
+
+		total, ok := totals[invoice.Currency]
+		if !ok {
+			total = &AgingTotal{Currency: invoice.Currency, AgingBuckets: emptyBuckets(invoice.Currency)}
+			totals[invoice.Currency] = total
+		}
+		total.Invoices++
+		total.add(bucket, balance)
+	}
+
+	for _, row := range rows {
+		report.Rows = append(report.Rows, *row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if an, bn := strings.ToLower(a.ClientName), strings.ToLower(b.ClientName); an != bn {
+			return an < bn
+		}
+		if a.ClientID != b.ClientID {
+			return a.ClientID < b.ClientID
+		}
+		return a.Currency < b.Currency
+	})
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		return report.Totals[i].Currency < report.Totals[j].Currency
+	})
+	sort.SliceStable(report.Invoices, func(i, j int) bool {
+		return report.Invoices[i].DaysPastDue > report.Invoices[j].DaysPastDue
+	})
+	return report
+}
+
+// balanceOn returns the invoice's balance at the end of the given day.
+func balanceOn(invoice *models.Invoice, on civil.Date) money.Money {
+	balance := amount(invoice, invoice.Total)
+	for _, p := range invoice.Payments {
+		if !p.Date.After(on) {
+			balance = balance.Sub(amount(invoice, p.Amount))
+		}
+	}
+	for _, cn := range invoice.CreditNotes {
+		if !civil.Of(cn.CreatedAt).After(on) {
+			balance = balance.Sub(amount(invoice, cn.Amount))
+		}
+	}
+	for _, fee := range invoice.LateFees {
+		if !civil.Of(fee.CreatedAt).After(on) {
+			balance = balance.Add(amount(invoice, fee.Amount))
+		}
+	}
+	return balance
+}
+
+// bucketFor returns the aging bucket for a number of days past due.
+func bucketFor(days int) string {
+	switch {
+	case days <= 0:
+		return BucketCurrent
+	case days <= 30:
+		return Bucket1To30
+	case days <= 60:
+		return Bucket31To60
+	case days <= 90:
+		return Bucket61To90
+	default:
+		return BucketOver90
+	}
+}
+
+func emptyBuckets(currency string) AgingBuckets {
+	zero := money.New(0, currency)
+	return AgingBuckets{Current: zero, Days1To30: zero, Days31To60: zero, Days61To90: zero, Over90: zero, Total: zero}
+}
+
+// add adds a balance to a bucket and to the total.
+func (b *AgingBuckets) add(bucket string, balance money.Money) {
+	switch bucket {
+	case BucketCurrent:
+		b.Current = b.Current.Add(balance)
+	case Bucket1To30:
+		b.Days1To30 = b.Days1To30.Add(balance)
+	case Bucket31To60:
+		b.Days31To60 = b.Days31To60.Add(balance)
+	case Bucket61To90:
+		b.Days61To90 = b.Days61To90.Add(balance)
+	default:
+		b.Over90 = b.Over90.Add(balance)
+	}
+	b.Total = b.Total.Add(balance)
+}
+
+// WriteCSV writes a row per client and currency followed by the
+// per-currency totals, which are labelled "Total" in the client name column.
+func (r *AgingReport) WriteCSV(w io.Writer) error {
+	header := []string{"client_id", "client_name", "currency", "invoices",
+		BucketCurrent, Bucket1To30, Bucket31To60, Bucket61To90, BucketOver90, "total"}
+	var records [][]string
+	for _, row := range r.Rows {
+		records = append(records, agingRecord(row.ClientID, row.ClientName, row.Currency, row.Invoices, row.AgingBuckets))
+	}
+	for _, total := range r.Totals {
+		records = append(records, agingRecord("", "Total", total.Currency, total.Invoices, total.AgingBuckets))
+	}
+	return writeCSV(w, header, records)
+}
+
+func agingRecord(clientID, clientName, currency string, invoices int, b AgingBuckets) []string {
+	return []string{
+		clientID, clientName, currency, strconv.Itoa(invoices),
+		b.Current.String(), b.Days1To30.String(), b.Days31To60.String(),
+		b.Days61To90.String(), b.Over90.String(), b.Total.String(),
+	}
+}
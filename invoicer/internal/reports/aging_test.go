@@ -0,0 +1,83 @@
+package reports
+
+import (
+	"bytes"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"strings"
+	"testing"
+	"time"
+)
+
+func due(invoice *models.Invoice, date string) *models.Invoice {
+	invoice.DueDate = civil.MustParse(date)
+	return invoice
+}
+
+func TestAging(t *testing.T) {
+	asOf := civil.MustParse("2026-06-30")
+
+	credited := due(document("inv_5", "cli_2", "Initech", "USD", "2026-04-01", 40000, 0), "2026-05-01")
+	credited.CreditNotes = []models.CreditNoteRef{{Amount: money.New(10000, "USD"), CreatedAt: time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)}}
+	laterPayment := due(document("inv_6", "cli_2", "Initech", "USD", "2026-01-10", 5000, 0), "2026-02-10")
+	laterPayment.Status = models.StatusPaid
+	laterPayment.Payments = []models.Payment{{Amount: money.New(5000, "USD"), Date: civil.MustParse("2026-07-02")}}
+
+	invoices := []*models.Invoice{
+		due(document("inv_1", "cli_1", "Globex", "USD", "2026-06-20", 10000, 0), "2026-07-20"),    // not yet due
+		due(document("inv_2", "cli_1", "Globex", "USD", "2026-05-01", 20000, 5000), "2026-06-15"), // 15 days
+		due(document("inv_3", "cli_1", "Globex", "USD", "2026-03-01", 30000, 0), "2026-03-31"),    // 91 days
+		due(document("inv_4", "cli_1", "Globex", "EUR", "2026-04-15", 7000, 0), "2026-05-01"),     // 60 days
+		credited,     // 60 days, 300.00 after the credit note
+		laterPayment, // 140 days; paid only after asOf
+		due(document("inv_7", "cli_1", "Globex", "USD", "2026-07-01", 10000, 0), "2026-07-31"),     // issued after asOf
+		due(document("inv_8", "cli_1", "Globex", "USD", "2026-05-01", 10000, 10000), "2026-05-31"), // paid
+	}
+
+	report := Aging(invoices, asOf)
+
+	var got []string
+	for _, row := range report.Rows {
+		got = append(got, strings.Join([]string{row.ClientName, row.Currency, row.Current.String(), row.Days1To30.String(),
+			row.Days31To60.String(), row.Days61To90.String(), row.Over90.String(), row.Total.String()}, " "))
+	}
+	want := []string{
+		"Globex EUR 0.00 0.00 70.00 0.00 0.00 70.00",
+		"Globex USD 100.00 150.00 0.00 0.00 300.00 550.00",
+		"Initech USD 0.00 0.00 300.00 0.00 50.00 350.00",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(report.Totals) != 2 || report.Totals[1].Total.String() != "900.00" || report.Totals[1].Invoices != 5 {
+		t.Errorf("unexpected totals: %+v", report.Totals)
+	}
+	if len(report.Invoices) != 6 || report.Invoices[0].ID != "inv_6" || report.Invoices[0].DaysPastDue != 140 || report.Invoices[0].Bucket != BucketOver90 {
+		t.Errorf("expected the oldest invoice first, got %+v", report.Invoices[0])
+	}
+}
+
+func TestBucketFor(t *testing.T) {
+	for days, want := range map[int]string{-5: BucketCurrent, 0: BucketCurrent, 1: Bucket1To30, 30: Bucket1To30,
+		31: Bucket31To60, 60: Bucket31To60, 61: Bucket61To90, 90: Bucket61To90, 91: BucketOver90} {
+		if got := bucketFor(days); got != want {
+			t.Errorf("bucketFor(%d) = %q, want %q", days, got, want)
+		}
+	}
+}
+
+func TestAgingReport_WriteCSV(t *testing.T) {
+	invoices := []*models.Invoice{due(document("inv_1", "cli_1", "Globex", "USD", "2026-05-01", 20000, 0), "2026-05-31")}
+	var buf bytes.Buffer
+	if err := Aging(invoices, civil.MustParse("2026-06-30")).WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "client_id,client_name,currency,invoices,current,1-30,31-60,61-90,90+,total\n" +
+		"cli_1,Globex,USD,1,0.00,200.00,0.00,0.00,0.00,200.00\n" +
+		",Total,USD,1,0.00,200.00,0.00,0.00,0.00,200.00\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
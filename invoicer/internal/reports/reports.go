@@ -0,0 +1,51 @@
+// Package reports computes finance reports from stored invoices: revenue by
+// month, client and currency, and accounts receivable aging. Amounts are
+// never summed across currencies; every row is in a single currency, with
+// base-currency equivalents at the rates stamped when documents were issued.
+package reports
+
+import (
+	"encoding/csv"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"io"
+	"strings"
+)
+
+// issued reports whether a document counts towards reports: invoices and
+// credit notes that have been issued and not voided. Invoices superseded by
+// a revision are void, so only the revision counts.
+func issued(invoice *models.Invoice) bool {
+	if invoice.IsQuote() {
+		return false
+	}
+	status := lifecycle.Current(invoice)
+	return status != models.StatusDraft && status != models.StatusVoid
+}
+
+// client identifies the client of an invoice: its saved client ID or, for
+// invoices without one, its name ignoring case.
+func client(invoice *models.Invoice) string {
+	if invoice.ClientID != "" {
+		return invoice.ClientID
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(invoice.ClientName))
+}
+
+// amount returns an amount of the invoice stamped with its currency.
+func amount(invoice *models.Invoice, m money.Money) money.Money {
+	return m.WithCurrency(invoice.Currency)
+}
+
+// writeCSV writes a header and rows as CSV.
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,183 @@
+package reports
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RevenueOptions selects the documents and grouping of a revenue report.
+type RevenueOptions struct {
+	From     civil.Date // first invoice date included; zero for no limit
+	To       civil.Date // last invoice date included; zero for no limit
+	ByMonth  bool
+	ByClient bool
+}
+
+// RevenueRow is the revenue of one group of documents in one currency.
+// Invoices are grouped by their invoice date; credit notes reduce the
+// revenue of the month they are dated in.
+type RevenueRow struct {
+	Month        string       `json:"month,omitempty"` // YYYY-MM
+	ClientID     string       `json:"clientId,omitempty"`
+	ClientName   string       `json:"clientName,omitempty"`
+	Currency     string       `json:"currency"`
+	Invoices     int          `json:"invoices"`
+	CreditNotes  int          `json:"creditNotes"`
+	Invoiced     money.Money  `json:"invoiced"`    // totals of the invoices, plus late fees charged on them
+	Credited     money.Money  `json:"credited"`    // totals of the credit notes, as a positive amount
+	Net          money.Money  `json:"net"`         // invoiced less credited
+	Paid         money.Money  `json:"paid"`        // payments recorded against the invoices
+	Outstanding  money.Money  `json:"outstanding"` // balance still due on the invoices
+	BaseCurrency string       `json:"baseCurrency,omitempty"`
+	BaseNet      *money.Money `json:"baseNet,omitempty"` // net in the base currency; absent unless every document has a stamped rate to the same base
+}
+
+// RevenueReport is revenue grouped by month and/or client and by currency,
+// with a total row per currency.
+type RevenueReport struct {
+	From   civil.Date   `json:"from"`
+	To     civil.Date   `json:"to"`
+	Rows   []RevenueRow `json:"rows"`
+	Totals []RevenueRow `json:"totals"`
+}
+
+// Revenue computes the revenue report from the user's documents. Drafts,
+// void invoices and quotes are left out.
+func Revenue(invoices []*models.Invoice, opts RevenueOptions) *RevenueReport {
+	var selected []*models.Invoice
+	for _, invoice := range invoices {
+		if !issued(invoice) || invoice.InvoiceDate.IsZero() {
+			continue
+		}
+		if !opts.From.IsZero() && invoice.InvoiceDate.Before(opts.From) {
+			continue
+		}
+		if !opts.To.IsZero() && invoice.InvoiceDate.After(opts.To) {
+			continue
+		}
+		selected = append(selected, invoice)
+	}
+
+	return &RevenueReport{
+		From:   opts.From,
+		To:     opts.To,
+		Rows:   revenueRows(selected, opts.ByMonth, opts.ByClient),
+		Totals: revenueRows(selected, false, false),
+	}
+}
+
+// revenueRows groups the documents by currency and, if asked, by month and client.
+func revenueRows(invoices []*models.Invoice, byMonth, byClient bool) []RevenueRow {
+	type group struct {
+		row    *RevenueRow
+		baseOK bool
+	}
+	groups := make(map[string]*group)
+	var order []*group
+
+	for _, invoice := range invoices {
+		row := RevenueRow{Currency: invoice.Currency}
+		if byMonth {
+			row.Month = invoice.InvoiceDate.String()[:7]
+		}
+		k := row.Month + "|" + row.Currency
+		if byClient {
+			row.ClientID = invoice.ClientID
+			row.ClientName = invoice.ClientName
+			k += "|" + client(invoice)
+		}
+
+		g, ok := groups[k]
+		if !ok {
+			zero := money.New(0, invoice.Currency)
+			row.Invoiced, row.Credited, row.Net, row.Paid, row.Outstanding = zero, zero, zero, zero, zero
+			row.BaseCurrency = invoice.BaseCurrency
+			g = &group{row: &row, baseOK: true}
+			groups[k] = g
+			order = append(order, g)
+		}
+
+		r := g.row
+		total := amount(invoice, invoice.Total).Add(amount(invoice, invoice.LateFeeAmount))
+		if invoice.IsCreditNote() {
+			r.CreditNotes++
+			r.Credited = r.Credited.Sub(total)
+		} else {
+			r.Invoices++
+			r.Invoiced = r.Invoiced.Add(total)
+			r.Paid = r.Paid.Add(amount(invoice, invoice.AmountPaid))
+			r.Outstanding = r.Outstanding.Add(amount(invoice, invoice.BalanceDue))
+		}
+		r.Net = r.Net.Add(total)
+
+		base, ok := fx.ToBase(invoice, total)
+		switch {
+		case !g.baseOK:
+		case !ok || invoice.BaseCurrency != r.BaseCurrency:
+			g.baseOK = false
+			r.BaseNet = nil
+		case r.BaseNet == nil:
+			r.BaseNet = &base
+		default:
+			sum := r.BaseNet.Add(base)
+			r.BaseNet = &sum
+		}
+	}
+
+	rows := make([]RevenueRow, 0, len(order))
+	for _, g := range order {
+		if !g.baseOK {
+			g.row.BaseCurrency = ""
+		}
+		rows = append(rows, *g.row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if an, bn := strings.ToLower(a.ClientName), strings.ToLower(b.ClientName); an != bn {
+			return an < bn
+		}
+		if a.ClientID != b.ClientID {
+			return a.ClientID < b.ClientID
+		}
+		return a.Currency < b.Currency
+	})
+	return rows
+}
+
+// WriteCSV writes the report's rows followed by its per-currency totals,
+// which are labelled "Total" in the month column.
+func (r *RevenueReport) WriteCSV(w io.Writer) error {
+	header := []string{"month", "client_id", "client_name", "currency", "invoices", "credit_notes",
+		"invoiced", "credited", "net", "paid", "outstanding", "base_currency", "base_net"}
+	var records [][]string
+	for _, row := range r.Rows {
+		records = append(records, revenueRecord(row, row.Month))
+	}
+	for _, row := range r.Totals {
+		records = append(records, revenueRecord(row, "Total"))
+	}
+	return writeCSV(w, header, records)
+}
+
+func revenueRecord(row RevenueRow, month string) []string {
+	baseNet := ""
+	if row.BaseNet != nil {
+		baseNet = row.BaseNet.String()
+	}
+	return []string{
+		month, row.ClientID, row.ClientName, row.Currency,
+		strconv.Itoa(row.Invoices), strconv.Itoa(row.CreditNotes),
+		row.Invoiced.String(), row.Credited.String(), row.Net.String(),
+		row.Paid.String(), row.Outstanding.String(),
+		row.BaseCurrency, baseNet,
+	}
+}
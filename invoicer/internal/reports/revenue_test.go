@@ -0,0 +1,109 @@
+package reports
+
+import (
+	"bytes"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"strings"
+	"testing"
+)
+
+// document returns an issued document of the given total, with the balance
+// due set from its payments.
+func document(id, clientID, clientName, currency, date string, total int64, paid int64) *models.Invoice {
+	invoice := &models.Invoice{
+		ID:          id,
+		ClientID:    clientID,
+		ClientName:  clientName,
+		Currency:    currency,
+		Status:      models.StatusIssued,
+		InvoiceDate: civil.MustParse(date),
+		Total:       money.New(total, currency),
+		AmountPaid:  money.New(paid, currency),
+		BalanceDue:  money.New(total-paid, currency),
+	}
+	if paid > 0 {
+		invoice.Payments = []models.Payment{{Amount: money.New(paid, currency), Date: civil.MustParse(date)}}
+	}
+	return invoice
+}
+
+func revenueFixture() []*models.Invoice {
+	creditNote := document("inv_4", "cli_1", "Globex", "USD", "2026-02-10", -5000, 0)
+	creditNote.DocumentType = models.DocumentCreditNote
+
+	eur := document("inv_5", "cli_1", "Globex", "EUR", "2026-02-15", 10000, 0)
+	eur.BaseCurrency, eur.ExchangeRate = "USD", 1.1
+
+	draft := document("inv_6", "cli_1", "Globex", "USD", "2026-02-20", 99999, 0)
+	draft.Status = models.StatusDraft
+	void := document("inv_7", "cli_1", "Globex", "USD", "2026-02-20", 99999, 0)
+	void.Status = models.StatusVoid
+	quote := document("inv_8", "cli_1", "Globex", "USD", "2026-02-20", 99999, 0)
+	quote.DocumentType = models.DocumentQuote
+
+	return []*models.Invoice{
+		document("inv_1", "cli_1", "Globex", "USD", "2026-01-05", 20000, 20000),
+		document("inv_2", "cli_1", "Globex", "USD", "2026-02-03", 30000, 10000),
+		document("inv_3", "", "Initech", "USD", "2026-02-04", 15000, 0),
+		creditNote, eur, draft, void, quote,
+	}
+}
+
+func TestRevenue_ByMonthAndClient(t *testing.T) {
+	report := Revenue(revenueFixture(), RevenueOptions{ByMonth: true, ByClient: true})
+
+	var got []string
+	for _, row := range report.Rows {
+		got = append(got, strings.Join([]string{row.Month, row.ClientName, row.Currency,
+			row.Invoiced.String(), row.Credited.String(), row.Net.String(), row.Paid.String(), row.Outstanding.String()}, " "))
+	}
+	want := []string{
+		"2026-01 Globex USD 200.00 0.00 200.00 200.00 0.00",
+		"2026-02 Globex EUR 100.00 0.00 100.00 0.00 100.00",
+		"2026-02 Globex USD 300.00 50.00 250.00 100.00 200.00",
+		"2026-02 Initech USD 150.00 0.00 150.00 0.00 150.00",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected rows:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	eur := report.Rows[1]
+	if eur.BaseCurrency != "USD" || eur.BaseNet == nil || eur.BaseNet.String() != "110.00" {
+		t.Errorf("expected a base net of USD 110.00 for the EUR row, got %q %v", eur.BaseCurrency, eur.BaseNet)
+	}
+	if report.Rows[2].BaseNet != nil {
+		t.Errorf("expected no base net for documents without a stamped rate, got %v", report.Rows[2].BaseNet)
+	}
+
+	if len(report.Totals) != 2 || report.Totals[1].Currency != "USD" || report.Totals[1].Net.String() != "600.00" || report.Totals[1].Invoices != 3 || report.Totals[1].CreditNotes != 1 {
+		t.Errorf("unexpected totals: %+v", report.Totals)
+	}
+}
+
+func TestRevenue_DateRangeAndGrouping(t *testing.T) {
+	report := Revenue(revenueFixture(), RevenueOptions{From: civil.MustParse("2026-02-01"), To: civil.MustParse("2026-02-10"), ByMonth: true})
+	if len(report.Rows) != 1 {
+		t.Fatalf("expected one row for February in USD, got %+v", report.Rows)
+	}
+	row := report.Rows[0]
+	if row.Month != "2026-02" || row.ClientName != "" || row.Net.String() != "400.00" || row.Invoices != 2 {
+		t.Errorf("unexpected row: %+v", row)
+	}
+}
+
+func TestRevenueReport_WriteCSV(t *testing.T) {
+	report := Revenue(revenueFixture()[:1], RevenueOptions{ByMonth: true, ByClient: true})
+	report.Rows[0].ClientName = "Globex, Inc."
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "month,client_id,client_name,currency,invoices,credit_notes,invoiced,credited,net,paid,outstanding,base_currency,base_net\n" +
+		"2026-01,cli_1,\"Globex, Inc.\",USD,1,0,200.00,0.00,200.00,200.00,0.00,,\n" +
+		"Total,,,USD,1,0,200.00,0.00,200.00,200.00,0.00,,\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,184 @@
+package reports
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Tax report periods.
+const (
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// TaxOptions selects the documents and periods of a tax report.
+type TaxOptions struct {
+	From   civil.Date // first invoice date included; zero for no limit
+	To     civil.Date // last invoice date included; zero for no limit
+	Period string     // PeriodMonth, PeriodQuarter or PeriodYear
+}
+
+// TaxRow is the taxable base and tax of one tax at one rate in one period,
+// net of credit notes.
+type TaxRow struct {
+	Period      string      `json:"period,omitempty"` // e.g. 2026-03, 2026-Q1 or 2026
+	Currency    string      `json:"currency"`
+	Name        string      `json:"name"`
+	Rate        float64     `json:"rate"`
+	Compound    bool        `json:"compound,omitempty"`
+	Invoices    int         `json:"invoices"`
+	CreditNotes int         `json:"creditNotes"`
+	Base        money.Money `json:"base"` // taxable amount
+	Amount      money.Money `json:"amount"`
+}
+
+// TaxReport is the tax charged per tax, rate and period, with a total row per
+// tax and rate over the whole range.
+type TaxReport struct {
+	From   civil.Date `json:"from"`
+	To     civil.Date `json:"to"`
+	Period string     `json:"period"`
+	Rows   []TaxRow   `json:"rows"`
+	Totals []TaxRow   `json:"totals"`
+}
+
+// Tax computes the tax report from the user's documents. Each document's tax
+// summary is computed by calc, as printed on its PDF, and converted to the
+// business's base currency at the rate stamped on issue. Documents without a
+// base currency or stamped rate are reported in their own currency. Credit
+// notes carry negative amounts and so reduce the tax of the period they are
+// dated in.
+func Tax(invoices []*models.Invoice, opts TaxOptions) *TaxReport {
+	var selected []*models.Invoice
+	for _, invoice := range invoices {
+		if !issued(invoice) || invoice.InvoiceDate.IsZero() {
+			continue
+		}
+		if !opts.From.IsZero() && invoice.InvoiceDate.Before(opts.From) {
+			continue
+		}
+		if !opts.To.IsZero() && invoice.InvoiceDate.After(opts.To) {
+			continue
+		}
+		selected = append(selected, invoice)
+	}
+
+	return &TaxReport{
+		From:   opts.From,
+		To:     opts.To,
+		Period: opts.Period,
+		Rows:   taxRows(selected, opts.Period),
+		Totals: taxRows(selected, ""),
+	}
+}
+
+// taxRows groups the documents' taxes by currency, name, rate and
+// compounding, and by period unless period is empty.
+func taxRows(invoices []*models.Invoice, period string) []TaxRow {
+	type key struct {
+		period, currency, name string
+		rate                   float64
+		compound               bool
+	}
+	groups := make(map[key]*TaxRow)
+	var order []key
+
+	for _, invoice := range invoices {
+		label := periodOf(invoice.InvoiceDate, period)
+		for _, line := range calc.Compute(invoice).TaxSummary {
+			base, amount, currency := inBusinessCurrency(invoice, line)
+			k := key{label, currency, line.Name, line.Rate, line.Compound}
+			row, ok := groups[k]
+			if !ok {
+				zero := money.New(0, currency)
+				row = &TaxRow{Period: label, Currency: currency, Name: line.Name, Rate: line.Rate, Compound: line.Compound, Base: zero, Amount: zero}
+				groups[k] = row
+				order = append(order, k)
+			}
+			if invoice.IsCreditNote() {
+				row.CreditNotes++
+			} else {
+				row.Invoices++
+			}
+			row.Base = row.Base.Add(base)
+			row.Amount = row.Amount.Add(amount)
+		}
+	}
+
+	rows := make([]TaxRow, 0, len(order))
+	for _, k := range order {
+		rows = append(rows, *groups[k])
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case a.Period != b.Period:
+			return a.Period < b.Period
+		case a.Currency != b.Currency:
+			return a.Currency < b.Currency
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		default:
+			return a.Rate < b.Rate
+		}
+	})
+	return rows
+}
+
+// inBusinessCurrency converts a tax line of the invoice to its base currency
+// at the stamped rate, or keeps it in the invoice's currency if it has none.
+func inBusinessCurrency(invoice *models.Invoice, line models.TaxLine) (money.Money, money.Money, string) {
+	base, amount := amount(invoice, line.Base), amount(invoice, line.Amount)
+	convertedBase, ok := fx.ToBase(invoice, base)
+	if !ok {
+		return base, amount, invoice.Currency
+	}
+	convertedAmount, _ := fx.ToBase(invoice, amount)
+	return convertedBase, convertedAmount, invoice.BaseCurrency
+}
+
+// periodOf returns the label of the period containing the date: 2026-03 for
+// months, 2026-Q1 for quarters and 2026 for years. An empty period gives "".
+func periodOf(date civil.Date, period string) string {
+	t := date.Time()
+	switch period {
+	case PeriodMonth:
+		return t.Format("2006-01")
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+	case PeriodYear:
+		return strconv.Itoa(t.Year())
+	default:
+		return ""
+	}
+}
+
+// WriteCSV writes the report's rows followed by the totals per tax and rate,
+// which are labelled "Total" in the period column.
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	header := []string{"period", "currency", "tax", "rate", "compound", "invoices", "credit_notes", "taxable_base", "tax_amount"}
+	var records [][]string
+	for _, row := range r.Rows {
+		records = append(records, taxRecord(row, row.Period))
+	}
+	for _, row := range r.Totals {
+		records = append(records, taxRecord(row, "Total"))
+	}
+	return writeCSV(w, header, records)
+}
+
+func taxRecord(row TaxRow, period string) []string {
+	return []string{
+		period, row.Currency, row.Name, strconv.FormatFloat(row.Rate, 'f', -1, 64),
+		strconv.FormatBool(row.Compound), strconv.Itoa(row.Invoices), strconv.Itoa(row.CreditNotes),
+		row.Base.String(), row.Amount.String(),
+	}
+}
@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"bytes"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"strings"
+	"testing"
+)
+
+// taxed returns an issued document with one line of the given quantity and
+// rate, charged the given taxes, with its totals applied.
+func taxed(id, currency, date string, quantity float64, rate int64, taxes ...models.Tax) *models.Invoice {
+	invoice := &models.Invoice{
+		ID:          id,
+		Currency:    currency,
+		Status:      models.StatusIssued,
+		InvoiceDate: civil.MustParse(date),
+		Items:       []models.LineItem{{Description: "Work", Quantity: quantity, Rate: money.New(rate, currency), Taxes: taxes}},
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func taxFixture() []*models.Invoice {
+	gst := []models.Tax{{Name: "CGST", Rate: 9}, {Name: "SGST", Rate: 9}}
+
+	creditNote := taxed("inv_3", "USD", "2026-02-10", -1, 20000, gst...)
+	creditNote.DocumentType = models.DocumentCreditNote
+
+	eur := taxed("inv_4", "EUR", "2026-04-01", 1, 10000, models.Tax{Name: "VAT", Rate: 20}, models.Tax{Name: "Eco levy", Rate: 2, Compound: true})
+	eur.BaseCurrency, eur.ExchangeRate = "USD", 1.1
+
+	draft := taxed("inv_5", "USD", "2026-02-20", 1, 99999, gst...)
+	draft.Status = models.StatusDraft
+
+	return []*models.Invoice{
+		taxed("inv_1", "USD", "2026-01-05", 1, 100000, gst...),
+		taxed("inv_2", "USD", "2026-02-03", 1, 50000, gst...),
+		creditNote, eur, draft,
+	}
+}
+
+func taxLines(rows []TaxRow) string {
+	var got []string
+	for _, row := range rows {
+		got = append(got, strings.Join([]string{row.Period, row.Currency, row.Name, row.Base.String(), row.Amount.String()}, " "))
+	}
+	return strings.Join(got, "\n")
+}
+
+func TestTax_ByMonthNetsCreditNotes(t *testing.T) {
+	report := Tax(taxFixture(), TaxOptions{Period: PeriodMonth})
+
+	want := strings.Join([]string{
+		"2026-01 USD CGST 1000.00 90.00",
+		"2026-01 USD SGST 1000.00 90.00",
+		"2026-02 USD CGST 300.00 27.00",
+		"2026-02 USD SGST 300.00 27.00",
+		"2026-04 USD Eco levy 132.00 2.64",
+		"2026-04 USD VAT 110.00 22.00",
+	}, "\n")
+	if got := taxLines(report.Rows); got != want {
+		t.Errorf("unexpected rows:\n%s\nwant:\n%s", got, want)
+	}
+	if row := report.Rows[2]; row.Invoices != 1 || row.CreditNotes != 1 {
+		t.Errorf("expected 1 invoice and 1 credit note in February, got %d and %d", row.Invoices, row.CreditNotes)
+	}
+
+	want = strings.Join([]string{
+		" USD CGST 1300.00 117.00",
+		" USD Eco levy 132.00 2.64",
+		" USD SGST 1300.00 117.00",
+		" USD VAT 110.00 22.00",
+	}, "\n")
+	if got := taxLines(report.Totals); got != want {
+		t.Errorf("unexpected totals:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTax_MatchesRenderedSummary(t *testing.T) {
+	invoice := taxFixture()[0]
+	report := Tax([]*models.Invoice{invoice}, TaxOptions{Period: PeriodYear})
+
+	if len(report.Rows) != len(invoice.TaxSummary) {
+		t.Fatalf("expected %d rows, got %d", len(invoice.TaxSummary), len(report.Rows))
+	}
+	for i, line := range invoice.TaxSummary {
+		row := report.Rows[i]
+		if row.Period != "2026" || row.Name != line.Name || row.Base.Cmp(line.Base) != 0 || row.Amount.Cmp(line.Amount) != 0 {
+			t.Errorf("row %d = %+v, want the invoice's tax summary %+v", i, row, line)
+		}
+	}
+}
+
+func TestTax_QuarterAndRange(t *testing.T) {
+	report := Tax(taxFixture(), TaxOptions{From: civil.MustParse("2026-02-01"), To: civil.MustParse("2026-03-31"), Period: PeriodQuarter})
+
+	want := strings.Join([]string{
+		"2026-Q1 USD CGST 300.00 27.00",
+		"2026-Q1 USD SGST 300.00 27.00",
+	}, "\n")
+	if got := taxLines(report.Rows); got != want {
+		t.Errorf("unexpected rows:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTax_WriteCSV(t *testing.T) {
+	report := Tax(taxFixture(), TaxOptions{To: civil.MustParse("2026-01-31"), Period: PeriodMonth})
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	want := "period,currency,tax,rate,compound,invoices,credit_notes,taxable_base,tax_amount\n" +
+		"2026-01,USD,CGST,9,false,1,0,1000.00,90.00\n" +
+		"2026-01,USD,SGST,9,false,1,0,1000.00,90.00\n" +
+		"Total,USD,CGST,9,false,1,0,1000.00,90.00\n" +
+		"Total,USD,SGST,9,false,1,0,1000.00,90.00\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,77 @@
+// Package revisions corrects issued invoices. An issued invoice is never
+// edited in place; instead a revision with the corrected content is issued
+// under the original number with a -R<n> suffix, and the invoice it replaces
+// is voided and linked to it.
+package revisions
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrNotRevisable is returned when revising an invoice that is not open, has
+// payments, credit notes or late fees recorded against it, or was already
+// revised.
+var ErrNotRevisable = errors.New("only issued invoices without payments, credit notes or late fees can be revised; drafts are edited instead")
+
+// Check reports whether the invoice can be replaced by a revision: it must be
+// open, with no payments, credit notes or late fees recorded, and not yet
+// superseded. Late fees were charged against the invoice as issued, so a
+// revision would otherwise drop them.
+func Check(previous *models.Invoice) error {
+	if !lifecycle.IsOpen(previous) || previous.SupersededByID != "" {
+		return ErrNotRevisable
+	}
+	if len(previous.Payments) > 0 || len(previous.CreditNotes) > 0 || len(previous.LateFees) > 0 {
+		return ErrNotRevisable
+	}
+	return nil
+}
+
+// Build turns the corrected content into the next revision of previous:
+// numbered after it, linked back to it and issued at the given time. The
+// revision's totals must already be computed.
+func Build(previous, revision *models.Invoice, at time.Time) error {
+	if err := Check(previous); err != nil {
+		return err
+	}
+
+	revision.DocumentType = models.DocumentInvoice
+	revision.Revision = previous.Revision + 1
+	revision.InvoiceNumber = Number(previous, revision.Revision)
+	revision.PreviousRevisionID = previous.ID
+	revision.PreviousRevisionNumber = previous.InvoiceNumber
+	revision.SupersededByID = ""
+	revision.SupersededByNumber = ""
+
+	lifecycle.Init(revision, at)
+	return lifecycle.Transition(revision, models.StatusIssued, at)
+}
+
+// Supersede voids the previous invoice and links it to the revision that
+// replaces it.
+func Supersede(previous, revision *models.Invoice, at time.Time) error {
+	if err := Check(previous); err != nil {
+		return err
+	}
+	if err := lifecycle.Transition(previous, models.StatusVoid, at); err != nil {
+		return err
+	}
+	previous.SupersededByID = revision.ID
+	previous.SupersededByNumber = revision.InvoiceNumber
+	return nil
+}
+
+// Number returns the number of revision n of an invoice: the original number
+// with a -R<n> suffix, e.g. INV-2026-00001-R2.
+func Number(previous *models.Invoice, n int) string {
+	base := previous.InvoiceNumber
+	if previous.Revision > 0 {
+		base = strings.TrimSuffix(base, fmt.Sprintf("-R%d", previous.Revision))
+	}
+	return fmt.Sprintf("%s-R%d", base, n)
+}
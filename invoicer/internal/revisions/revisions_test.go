@@ -0,0 +1,88 @@
+package revisions
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func issuedInvoice() *models.Invoice {
+	return &models.Invoice{
+		ID:            "inv_1",
+		InvoiceNumber: "INV-2026-00001",
+		Status:        models.StatusIssued,
+		StatusHistory: []models.StatusChange{{Status: models.StatusDraft}, {Status: models.StatusIssued}},
+	}
+}
+
+func TestBuildAndSupersede(t *testing.T) {
+	at := time.Date(2026, time.April, 2, 10, 0, 0, 0, time.UTC)
+	previous := issuedInvoice()
+
+	revision := &models.Invoice{ClientName: "Globex", SupersededByID: "inv_9"}
+	if err := Build(previous, revision, at); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if revision.InvoiceNumber != "INV-2026-00001-R1" || revision.Revision != 1 {
+		t.Errorf("expected revision 1 numbered INV-2026-00001-R1, got %d %q", revision.Revision, revision.InvoiceNumber)
+	}
+	if revision.PreviousRevisionID != "inv_1" || revision.PreviousRevisionNumber != "INV-2026-00001" || revision.SupersededByID != "" {
+		t.Errorf("unexpected revision links: %+v", revision)
+	}
+	if revision.Status != models.StatusIssued || len(revision.StatusHistory) != 2 {
+		t.Errorf("expected the revision to be issued, got %q with %d changes", revision.Status, len(revision.StatusHistory))
+	}
+
+	revision.ID = "inv_2"
+	if err := Supersede(previous, revision, at); err != nil {
+		t.Fatalf("Supersede failed: %v", err)
+	}
+	if previous.Status != models.StatusVoid || previous.SupersededByID != "inv_2" || previous.SupersededByNumber != "INV-2026-00001-R1" {
+		t.Errorf("expected the previous invoice to be voided and linked, got %+v", previous)
+	}
+	if err := Check(previous); !errors.Is(err, ErrNotRevisable) {
+		t.Errorf("expected a superseded invoice not to be revisable, got %v", err)
+	}
+
+	second := &models.Invoice{}
+	if err := Build(revision, second, at); err != nil {
+		t.Fatalf("Build of the second revision failed: %v", err)
+	}
+	if second.InvoiceNumber != "INV-2026-00001-R2" || second.Revision != 2 {
+		t.Errorf("expected revision 2 numbered INV-2026-00001-R2, got %d %q", second.Revision, second.InvoiceNumber)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	draft := issuedInvoice()
+	draft.Status = models.StatusDraft
+
+	paid := issuedInvoice()
+	paid.Payments = []models.Payment{{Amount: money.New(100, "USD")}}
+
+	credited := issuedInvoice()
+	credited.CreditNotes = []models.CreditNoteRef{{ID: "inv_2"}}
+
+	charged := issuedInvoice()
+	charged.LateFees = []models.LateFee{{Amount: money.New(2500, "USD")}}
+
+	creditNote := issuedInvoice()
+	creditNote.DocumentType = models.DocumentCreditNote
+
+	for name, invoice := range map[string]*models.Invoice{
+		"draft":       draft,
+		"paid":        paid,
+		"credited":    credited,
+		"late fees":   charged,
+		"credit note": creditNote,
+	} {
+		if err := Check(invoice); !errors.Is(err, ErrNotRevisable) {
+			t.Errorf("%s: expected ErrNotRevisable, got %v", name, err)
+		}
+	}
+	if err := Check(issuedInvoice()); err != nil {
+		t.Errorf("expected an issued invoice to be revisable, got %v", err)
+	}
+}
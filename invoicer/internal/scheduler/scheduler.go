@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"invoice-generator/invoicer/internal/audit"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/fx"
+	"invoice-generator/invoicer/internal/lifecycle"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/recurring"
+	"invoice-generator/invoicer/internal/snapshot"
+	"invoice-generator/invoicer/internal/store"
+	"log"
+	"time"
+)
+
+// Scheduler generates invoices from recurring schedules in the background.
+//
+// Every occurrence is identified by its schedule and date, and the invoice
+// store refuses a second invoice for the same pair. A run that is interrupted
+// between creating an invoice and recording the schedule's progress therefore
+// skips that occurrence the next time instead of billing it twice.
+type Scheduler struct {
+	schedules store.RecurringStore
+	invoices  store.InvoiceStore
+	numbers   numbering.Store
+	directory *directory.Directory
+	rates     store.ExchangeRateStore
+	snapshots store.SnapshotStore
+	interval  time.Duration
+}
+
+// New creates a scheduler that checks for due occurrences every interval.
+// Auto-issued invoices are stamped with exchange rates from rates and frozen
+// into snapshots, and every invoice created is recorded in its history.
+func New(schedules store.RecurringStore, invoices store.InvoiceStore, numbers numbering.Store, dir *directory.Directory, rates store.ExchangeRateStore, snapshots store.SnapshotStore, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		schedules: schedules,
+		invoices:  invoices,
+		numbers:   numbers,
+		directory: dir,
+		rates:     rates,
+		snapshots: snapshots,
+		interval:  interval,
+	}
+}
+
+// Run generates due invoices immediately and then on every tick until ctx is
+// cancelled. Missed occurrences (for example while the server was down) are
+// caught up on the next run.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if created, err := s.RunOnce(time.Now().UTC()); err != nil {
+			log.Printf("⚠️  Recurring invoices: %v", err)
+		} else if created > 0 {
+			log.Printf("🔁 Recurring invoices: generated %d invoice(s)", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce generates every occurrence dated on or before now that has not been
+// invoiced yet, and returns how many invoices it created. A failing schedule
+// does not stop the others; the first error is returned.
+func (s *Scheduler) RunOnce(now time.Time) (int, error) {
+	schedules, err := s.schedules.ListAll()
+	if err != nil {
+		return 0, err
+	}
+
+	today := civil.Of(now)
+	total := 0
+	var firstErr error
+	for _, schedule := range schedules {
+		created, err := s.process(schedule, today, now)
+		total += created
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return total, firstErr
+}
+
+// process generates the schedule's due occurrences in order, starting from
+// its NextRun.
+func (s *Scheduler) process(schedule *models.RecurringSchedule, today civil.Date, now time.Time) (int, error) {
+	if schedule.Paused {
+		return 0, nil
+	}
+
+	created := 0
+	for {
+		n, date := schedule.Occurrences+schedule.Skipped, schedule.NextRun
+		if date.IsZero() || date.After(today) {
+			return created, nil
+		}
+
+		err := s.generate(schedule, date, now)
+		var unresolved *directoryError
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, store.ErrDuplicateOccurrence):
+			// Already invoiced by an earlier run; only the progress was lost.
+		case errors.As(err, &unresolved):
+			// The period stays due and is retried on the next run, once the
+			// schedule has been updated.
+			log.Printf("⚠️  Recurring schedule %s: skipping %s: %v", schedule.ID, date, unresolved.err)
+			return created, nil
+		default:
+			return created, err
+		}
+
+		schedule, err = s.schedules.Update(schedule.UserID, schedule.ID, func(sc *models.RecurringSchedule) error {
+			if sc.Occurrences+sc.Skipped == n {
+				sc.Occurrences++
+				sc.LastRun = date
+				recurring.Advance(sc)
+			}
+			return nil
+		})
+		if err != nil {
+			return created, err
+		}
+	}
+}
+
+// generate creates the invoice for one occurrence. Auto-issued invoices get
+// the next number from the user's invoice sequence.
+func (s *Scheduler) generate(schedule *models.RecurringSchedule, date civil.Date, now time.Time) error {
+	invoice := recurring.BuildInvoice(schedule, date)
+
+	// Pick up the current client, business and catalog details. One that was
+	// deleted, or a catalog rate now in another currency, is not billed with
+	// the stale details copied into the schedule.
+	if err := s.directory.Apply(schedule.UserID, invoice); err != nil {
+		return &directoryError{err: err}
+	}
+	calc.Apply(invoice)
+
+	lifecycle.Init(invoice, now)
+	if schedule.AutoIssue {
+		if err := lifecycle.Transition(invoice, models.StatusIssued, now); err != nil {
+			return err
+		}
+		if err := fx.Stamp(invoice, s.rates, civil.Of(now)); err != nil {
+			return err
+		}
+	}
+
+	save := func() error {
+		var frozen *models.InvoiceSnapshot
+		var err error
+		if schedule.AutoIssue {
+			if frozen, err = snapshot.Freeze(invoice, now); err != nil {
+				return err
+			}
+		}
+		created, err := s.invoices.Create(schedule.UserID, invoice, audit.Journal(entry(models.AuditCreated, now)))
+		if err != nil || frozen == nil {
+			return err
+		}
+
+		// Without its snapshot the occurrence is removed again and retried
+		// on the next run.
+		frozen.InvoiceID, frozen.UserID = created.ID, created.UserID
+		if err := s.snapshots.Create(frozen); err != nil {
+			s.invoices.Delete(schedule.UserID, created.ID, nil, audit.Journal(entry(models.AuditDeleted, now)))
+			return err
+		}
+		return nil
+	}
+
+	// Drafts are numbered when they are issued.
+	if !schedule.AutoIssue {
+		return save()
+	}
+	_, err := s.numbers.Allocate(schedule.UserID, numbering.ScopeInvoice, date.Time(), func(number string) error {
+		invoice.InvoiceNumber = number
+		err := save()
+		if errors.Is(err, store.ErrDuplicateNumber) {
+			return numbering.ErrNumberTaken
+		}
+		return err
+	})
+	return err
+}
+
+// directoryError is returned by generate when the schedule's client, business
+// profile or catalog items can no longer be applied to its invoices.
+type directoryError struct {
+	err error
+}
+
+func (e *directoryError) Error() string { return e.err.Error() }
+
+func (e *directoryError) Unwrap() error { return e.err }
+
+// entry returns a history entry for a change the scheduler makes.
+func entry(action string, at time.Time) models.AuditEntry {
+	return models.AuditEntry{Action: action, Actor: models.ActorScheduler, At: at}
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/recurring"
+	"invoice-generator/invoicer/internal/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setup(t *testing.T, schedule *models.RecurringSchedule) (*Scheduler, *store.MemoryRecurringStore, *store.MemoryInvoiceStore, string) {
+	t.Helper()
+	schedules := store.NewMemoryRecurringStore()
+	invoices := store.NewMemoryInvoiceStore(store.NewMemoryAuditStore(), nil)
+
+	schedule.Template = models.Invoice{
+		BusinessName: "Acme",
+		ClientName:   "Globex",
+		Currency:     "USD",
+		Items:        []models.LineItem{{Description: "Retainer", Quantity: 1, Rate: money.New(150000, "USD")}},
+	}
+	schedule.NextRun = recurring.NextRun(schedule)
+	created, err := schedules.Create("user_1", schedule)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return New(schedules, invoices, numbering.NewMemoryStore(), directory.New(store.NewMemoryClientStore(), store.NewMemoryBusinessProfileStore(), store.NewMemoryCatalogStore()), store.NewMemoryExchangeRateStore(), store.NewMemorySnapshotStore(), time.Hour), schedules, invoices, created.ID
+}
+
+func TestRunOnce_CatchesUpAndNumbers(t *testing.T) {
+	s, schedules, invoices, id := setup(t, &models.RecurringSchedule{
+		Cadence:   models.CadenceMonthly,
+		StartDate: civil.MustParse("2026-01-05"),
+		AutoIssue: true,
+	})
+
+	created, err := s.RunOnce(time.Date(2026, time.March, 10, 8, 0, 0, 0, time.UTC))
+	if err != nil || created != 3 {
+		t.Fatalf("expected 3 invoices, got %d (err %v)", created, err)
+	}
+
+	list, _ := invoices.List("user_1")
+	seen := map[string]string{}
+	for _, inv := range list {
+		seen[inv.RecurringPeriod] = inv.InvoiceNumber
+		if inv.Status != models.StatusIssued || inv.Total.Minor() != 150000 {
+			t.Errorf("expected issued invoice of 1500.00, got %q and %s", inv.Status, inv.Total)
+		}
+		if inv.DocumentSHA256 == "" || inv.PDFSHA256 == "" {
+			t.Errorf("expected auto-issued invoice %s to be frozen", inv.InvoiceNumber)
+		}
+	}
+	if seen["2026-01-05"] != "INV-2026-00001" || seen["2026-03-05"] != "INV-2026-00003" {
+		t.Errorf("unexpected numbering per period: %v", seen)
+	}
+
+	schedule, _ := schedules.Get("user_1", id)
+	if schedule.Occurrences != 3 || schedule.LastRun.String() != "2026-03-05" || schedule.NextRun.String() != "2026-04-05" {
+		t.Errorf("unexpected progress: %d, last %s, next %s", schedule.Occurrences, schedule.LastRun, schedule.NextRun)
+	}
+
+	// Running again the same day generates nothing
+	if created, _ := s.RunOnce(time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC)); created != 0 {
+		t.Errorf("expected no new invoices, got %d", created)
+	}
+}
+
+func TestRunOnce_LostProgressDoesNotDoubleBill(t *testing.T) {
+	s, schedules, invoices, id := setup(t, &models.RecurringSchedule{
+		Cadence:   models.CadenceWeekly,
+		StartDate: civil.MustParse("2026-03-02"),
+	})
+	now := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	if created, _ := s.RunOnce(now); created != 2 {
+		t.Fatalf("expected 2 invoices, got %d", created)
+	}
+
+	// Simulate a crash after the invoices were saved but before progress was recorded
+	schedules.Update("user_1", id, func(sc *models.RecurringSchedule) error {
+		sc.Occurrences, sc.LastRun, sc.NextRun = 0, civil.Date{}, sc.StartDate
+		return nil
+	})
+
+	created, err := s.RunOnce(now)
+	if err != nil || created != 0 {
+		t.Fatalf("expected no new invoices, got %d (err %v)", created, err)
+	}
+	if list, _ := invoices.List("user_1"); len(list) != 2 {
+		t.Errorf("expected 2 invoices in total, got %d", len(list))
+	}
+	if schedule, _ := schedules.Get("user_1", id); schedule.Occurrences != 2 {
+		t.Errorf("expected progress to be restored to 2, got %d", schedule.Occurrences)
+	}
+}
+
+func TestRunOnce_SkipsPausedAndFinished(t *testing.T) {
+	s, _, invoices, _ := setup(t, &models.RecurringSchedule{
+		Cadence:   models.CadenceWeekly,
+		StartDate: civil.MustParse("2026-03-02"),
+		Paused:    true,
+	})
+	if created, _ := s.RunOnce(time.Date(2026, time.March, 30, 0, 0, 0, 0, time.UTC)); created != 0 {
+		t.Errorf("expected paused schedule to generate nothing, got %d", created)
+	}
+
+	s, _, invoices, _ = setup(t, &models.RecurringSchedule{
+		Cadence:        models.CadenceWeekly,
+		StartDate:      civil.MustParse("2026-03-02"),
+		MaxOccurrences: 2,
+	})
+	s.RunOnce(time.Date(2026, time.March, 30, 0, 0, 0, 0, time.UTC))
+	if list, _ := invoices.List("user_1"); len(list) != 2 {
+		t.Errorf("expected occurrence count to cap invoices at 2, got %d", len(list))
+	}
+	if list, _ := invoices.List("user_1"); list[0].Status != models.StatusDraft || list[0].InvoiceNumber != "" {
+		t.Errorf("expected unnumbered drafts without auto-issue, got %q numbered %q", list[0].Status, list[0].InvoiceNumber)
+	}
+}
+
+func TestRunOnce_HoldsPeriodsWithMissingReferences(t *testing.T) {
+	s, schedules, invoices, id := setup(t, &models.RecurringSchedule{
+		Cadence:   models.CadenceWeekly,
+		StartDate: civil.MustParse("2026-03-02"),
+	})
+	// The client was deleted after the schedule was saved.
+	schedules.Update("user_1", id, func(sc *models.RecurringSchedule) error {
+		sc.Template.ClientID = "cli_deleted"
+		return nil
+	})
+	now := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	if created, err := s.RunOnce(now); err != nil || created != 0 {
+		t.Fatalf("expected no invoices for a deleted client, got %d (err %v)", created, err)
+	}
+	if schedule, _ := schedules.Get("user_1", id); schedule.Occurrences != 0 || !schedule.LastRun.IsZero() {
+		t.Errorf("expected the period to stay due, got %d occurrences, last %s", schedule.Occurrences, schedule.LastRun)
+	}
+
+	schedules.Update("user_1", id, func(sc *models.RecurringSchedule) error {
+		sc.Template.ClientID = ""
+		return nil
+	})
+	if created, err := s.RunOnce(now); err != nil || created != 2 {
+		t.Fatalf("expected the held periods once the schedule is fixed, got %d (err %v)", created, err)
+	}
+	if list, _ := invoices.List("user_1"); len(list) != 2 {
+		t.Errorf("expected 2 invoices, got %d", len(list))
+	}
+}
+
+func TestRunOnce_ResumesAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoicer.db")
+	dir := directory.New(store.NewMemoryClientStore(), store.NewMemoryBusinessProfileStore(), store.NewMemoryCatalogStore())
+
+	// start opens the database as the server does on startup.
+	start := func() (*Scheduler, *store.SQLiteRecurringStore, *store.SQLiteInvoiceStore, func() error) {
+		db, err := store.OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("OpenSQLite failed: %v", err)
+		}
+		schedules, invoices := store.NewSQLiteRecurringStore(db), store.NewSQLiteInvoiceStore(db)
+		s := New(schedules, invoices, numbering.NewSQLiteStore(db), dir, store.NewMemoryExchangeRateStore(), store.NewSQLiteSnapshotStore(db), time.Hour)
+		return s, schedules, invoices, db.Close
+	}
+
+	s, schedules, _, stop := start()
+	schedule, err := schedules.Create("user_1", &models.RecurringSchedule{
+		Cadence:   models.CadenceWeekly,
+		StartDate: civil.MustParse("2026-03-02"),
+		NextRun:   civil.MustParse("2026-03-02"),
+		AutoIssue: true,
+		Template: models.Invoice{
+			BusinessName: "Acme",
+			ClientName:   "Globex",
+			Currency:     "USD",
+			Items:        []models.LineItem{{Description: "Retainer", Quantity: 1, Rate: money.New(150000, "USD")}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created, err := s.RunOnce(time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)); err != nil || created != 2 {
+		t.Fatalf("expected 2 invoices, got %d (err %v)", created, err)
+	}
+	// Lose the progress of the last occurrence, as if the server stopped
+	// after saving the invoice.
+	schedules.Update("user_1", schedule.ID, func(sc *models.RecurringSchedule) error {
+		sc.Occurrences, sc.LastRun, sc.NextRun = 1, sc.StartDate, civil.MustParse("2026-03-09")
+		return nil
+	})
+	stop()
+
+	s, _, invoices, stop := start()
+	defer stop()
+	created, err := s.RunOnce(time.Date(2026, time.March, 17, 0, 0, 0, 0, time.UTC))
+	if err != nil || created != 1 {
+		t.Fatalf("expected only the new occurrence after restart, got %d (err %v)", created, err)
+	}
+
+	list, _ := invoices.List("user_1")
+	numbers := map[string]string{}
+	for _, inv := range list {
+		numbers[inv.RecurringPeriod] = inv.InvoiceNumber
+	}
+	want := map[string]string{"2026-03-02": "INV-2026-00001", "2026-03-09": "INV-2026-00002", "2026-03-16": "INV-2026-00003"}
+	if len(list) != 3 || fmt.Sprint(numbers) != fmt.Sprint(want) {
+		t.Errorf("expected one invoice per week numbered in sequence, got %v", numbers)
+	}
+}
@@ -0,0 +1,120 @@
+// Package snapshot freezes invoices when they are issued. The canonical JSON
+// of the invoice and the exact PDF sent to the client are kept from then on,
+// each with a SHA-256 hash that proves it has not changed since.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/pdf"
+	"time"
+)
+
+// ErrTampered is returned when a frozen document or PDF no longer matches
+// the hash recorded when it was frozen.
+var ErrTampered = errors.New("frozen invoice does not match its hash")
+
+// Freeze renders the invoice and returns its snapshot, recording the hashes
+// of the document and PDF on the invoice. The snapshot takes the invoice's ID
+// and owner, so an invoice frozen before it is stored must have them set on
+// the snapshot afterwards.
+func Freeze(invoice *models.Invoice, at time.Time) (*models.InvoiceSnapshot, error) {
+	document, err := Canonical(invoice)
+	if err != nil {
+		return nil, err
+	}
+	pdfData, err := pdf.NewGenerator().GenerateInvoice(invoice)
+	if err != nil {
+		return nil, fmt.Errorf("rendering PDF: %w", err)
+	}
+
+	snapshot := &models.InvoiceSnapshot{
+		InvoiceID:      invoice.ID,
+		UserID:         invoice.UserID,
+		FrozenAt:       at,
+		Document:       document,
+		DocumentSHA256: Hash(document),
+		PDF:            pdfData,
+		PDFSHA256:      Hash(pdfData),
+	}
+	invoice.DocumentSHA256 = snapshot.DocumentSHA256
+	invoice.PDFSHA256 = snapshot.PDFSHA256
+	return snapshot, nil
+}
+
+// Canonical returns the invoice's canonical JSON: compact, with fields in
+// declaration order and map keys sorted. Storage metadata (ID, owner and
+// timestamps) and the hashes themselves are cleared, so the same content
+// always gives the same bytes.
+//
+// The ledger kept beside an issued invoice is cleared too: its status, the
+// payments, credit notes and late fees recorded against it, the balance they
+// leave and the link to a revision that supersedes it. These change after
+// issue without changing what was issued, so the canonical JSON of a stored
+// invoice stays equal to the frozen one for as long as it is kept.
+func Canonical(invoice *models.Invoice) ([]byte, error) {
+	c := invoice.Clone()
+	c.ID = ""
+	c.UserID = ""
+	c.CreatedAt = time.Time{}
+	c.UpdatedAt = time.Time{}
+	c.DocumentSHA256 = ""
+	c.PDFSHA256 = ""
+	c.Status = ""
+	c.StatusHistory = nil
+	c.Payments = nil
+	c.AmountPaid = money.Money{}
+	c.CreditNotes = nil
+	c.CreditedAmount = money.Money{}
+	c.LateFees = nil
+	c.LateFeeAmount = money.Money{}
+	c.BalanceDue = money.Money{}
+	c.SupersededByID = ""
+	c.SupersededByNumber = ""
+	return json.Marshal(c)
+}
+
+// HasLedger reports whether payments, credit notes or late fees have been
+// recorded against the invoice. Invoices are frozen before any are, so the
+// frozen PDF of an invoice without them is still current.
+func HasLedger(invoice *models.Invoice) bool {
+	return len(invoice.Payments) > 0 || len(invoice.CreditNotes) > 0 || len(invoice.LateFees) > 0
+}
+
+// Hash returns the hex-encoded SHA-256 hash of data.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Check verifies the snapshot and checks that the stored invoice still has
+// the content that was frozen.
+func Check(invoice *models.Invoice, snapshot *models.InvoiceSnapshot) error {
+	if err := Verify(snapshot); err != nil {
+		return err
+	}
+	document, err := Canonical(invoice)
+	if err != nil {
+		return err
+	}
+	if Hash(document) != snapshot.DocumentSHA256 || invoice.DocumentSHA256 != snapshot.DocumentSHA256 {
+		return fmt.Errorf("%w: stored invoice %s differs from its frozen content", ErrTampered, snapshot.InvoiceID)
+	}
+	return nil
+}
+
+// Verify checks the snapshot's document and PDF against their hashes.
+func Verify(snapshot *models.InvoiceSnapshot) error {
+	if Hash(snapshot.Document) != snapshot.DocumentSHA256 {
+		return fmt.Errorf("%w: document of invoice %s", ErrTampered, snapshot.InvoiceID)
+	}
+	if Hash(snapshot.PDF) != snapshot.PDFSHA256 {
+		return fmt.Errorf("%w: PDF of invoice %s", ErrTampered, snapshot.InvoiceID)
+	}
+	return nil
+}
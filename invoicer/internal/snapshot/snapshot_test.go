@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"invoice-generator/invoicer/internal/calc"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"testing"
+	"time"
+)
+
+func issuedInvoice() *models.Invoice {
+	invoice := &models.Invoice{
+		ID:            "inv_1",
+		UserID:        "user_1",
+		InvoiceNumber: "INV-2026-00001",
+		Status:        models.StatusIssued,
+		BusinessName:  "Acme",
+		ClientName:    "Globex",
+		Currency:      "USD",
+		Items: []models.LineItem{
+			{Description: "Design", Quantity: 2, Rate: money.New(10000, "USD")},
+		},
+	}
+	calc.Apply(invoice)
+	return invoice
+}
+
+func TestFreeze(t *testing.T) {
+	invoice := issuedInvoice()
+	at := time.Date(2026, time.April, 2, 10, 0, 0, 0, time.UTC)
+
+	snapshot, err := Freeze(invoice, at)
+	if err != nil {
+		t.Fatalf("Freeze failed: %v", err)
+	}
+	if snapshot.InvoiceID != "inv_1" || snapshot.UserID != "user_1" || !snapshot.FrozenAt.Equal(at) {
+		t.Errorf("unexpected snapshot metadata: %+v", snapshot)
+	}
+	if !bytes.HasPrefix(snapshot.PDF, []byte("%PDF")) {
+		t.Errorf("expected the snapshot to hold a PDF, got %q", snapshot.PDF[:8])
+	}
+	if len(snapshot.DocumentSHA256) != 64 || snapshot.DocumentSHA256 != Hash(snapshot.Document) {
+		t.Errorf("document hash %q does not match the document", snapshot.DocumentSHA256)
+	}
+	if invoice.DocumentSHA256 != snapshot.DocumentSHA256 || invoice.PDFSHA256 != snapshot.PDFSHA256 {
+		t.Errorf("expected the hashes to be recorded on the invoice, got %q and %q", invoice.DocumentSHA256, invoice.PDFSHA256)
+	}
+	if err := Verify(snapshot); err != nil {
+		t.Errorf("expected a fresh snapshot to verify, got %v", err)
+	}
+
+	snapshot.PDF[len(snapshot.PDF)-1] ^= 1
+	if err := Verify(snapshot); !errors.Is(err, ErrTampered) {
+		t.Errorf("expected ErrTampered for a modified PDF, got %v", err)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	a := issuedInvoice()
+	b := issuedInvoice()
+	b.ID, b.UserID = "inv_2", "user_2"
+	b.CreatedAt = time.Now()
+	b.DocumentSHA256 = "abc"
+
+	docA, err := Canonical(a)
+	if err != nil {
+		t.Fatalf("Canonical failed: %v", err)
+	}
+	docB, _ := Canonical(b)
+	if !bytes.Equal(docA, docB) {
+		t.Errorf("expected storage metadata to be left out:\n%s\n%s", docA, docB)
+	}
+	if b.ID != "inv_2" || b.DocumentSHA256 != "abc" {
+		t.Error("expected Canonical not to modify the invoice")
+	}
+
+	b.Items[0].Quantity = 3
+	if docB, _ = Canonical(b); bytes.Equal(docA, docB) {
+		t.Error("expected a content change to change the canonical JSON")
+	}
+}
+
+func TestCanonical_IgnoresLedger(t *testing.T) {
+	invoice := issuedInvoice()
+	frozen, _ := Canonical(invoice)
+
+	at := time.Date(2026, time.May, 2, 10, 0, 0, 0, time.UTC)
+	invoice.Status = models.StatusPartiallyPaid
+	invoice.StatusHistory = append(invoice.StatusHistory, models.StatusChange{Status: models.StatusPartiallyPaid, At: at})
+	invoice.Payments = []models.Payment{{ID: "pay_1", Amount: money.New(5000, "USD"), CreatedAt: at}}
+	invoice.LateFees = []models.LateFee{{Description: "Late fee", Amount: money.New(2500, "USD"), CreatedAt: at}}
+	invoice.CreditNotes = []models.CreditNoteRef{{ID: "inv_2", Amount: money.New(1000, "USD")}}
+	calc.Apply(invoice)
+
+	if current, _ := Canonical(invoice); !bytes.Equal(frozen, current) {
+		t.Errorf("expected payments, credits, late fees and status to leave the canonical JSON unchanged:\n%s\n%s", frozen, current)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	invoice := issuedInvoice()
+	snapshot, err := Freeze(invoice, time.Now())
+	if err != nil {
+		t.Fatalf("Freeze failed: %v", err)
+	}
+	invoice.Payments = []models.Payment{{Amount: money.New(5000, "USD")}}
+	calc.Apply(invoice)
+	if err := Check(invoice, snapshot); err != nil {
+		t.Errorf("expected an invoice with a payment to match its frozen content, got %v", err)
+	}
+	if !HasLedger(invoice) {
+		t.Error("expected the payment to count as a ledger entry")
+	}
+
+	invoice.Items = append(invoice.Items, models.LineItem{Description: "Extra", Quantity: 1, Rate: money.New(2500, "USD")})
+	calc.Apply(invoice)
+	if err := Check(invoice, snapshot); !errors.Is(err, ErrTampered) {
+		t.Errorf("expected ErrTampered for an invoice whose lines changed after it was frozen, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+)
+
+// AuditStore persists the append-only change history of invoices and quotes.
+// It has no way to change or remove an entry once appended.
+type AuditStore interface {
+	// Append stores a new entry, assigning its ID, and returns the stored copy.
+	Append(entry *models.AuditEntry) (*models.AuditEntry, error)
+
+	// List returns the history of the user's invoice, oldest entry first.
+	List(userID, invoiceID string) ([]*models.AuditEntry, error)
+}
+
+// MemoryAuditStore is a thread-safe in-memory AuditStore.
+type MemoryAuditStore struct {
+	mu      sync.RWMutex
+	entries map[string][]*models.AuditEntry // keyed by invoice ID
+	nextID  int
+}
+
+// NewMemoryAuditStore creates an empty in-memory audit store.
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{
+		entries: make(map[string][]*models.AuditEntry),
+	}
+}
+
+// Append stores a copy of the entry.
+func (s *MemoryAuditStore) Append(entry *models.AuditEntry) (*models.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	stored := entry.Clone()
+	stored.ID = fmt.Sprintf("aud_%d", s.nextID)
+
+	s.entries[stored.InvoiceID] = append(s.entries[stored.InvoiceID], stored)
+	return stored.Clone(), nil
+}
+
+// List returns copies of the history of the user's invoice, oldest entry
+// first. Invoices without history, and other users' invoices, have none.
+func (s *MemoryAuditStore) List(userID, invoiceID string) ([]*models.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.AuditEntry, 0, len(s.entries[invoiceID]))
+	for _, entry := range s.entries[invoiceID] {
+		if entry.UserID == userID {
+			result = append(result, entry.Clone())
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"encoding/json"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestAuditStore_AppendAndList(t *testing.T) {
+	forEachAuditStore(t, func(t *testing.T, s AuditStore) {
+
+		entry := &models.AuditEntry{
+			UserID:    "user_1",
+			InvoiceID: "inv_1",
+			Action:    models.AuditCreated,
+			Changes:   []models.FieldChange{{Field: "clientName", New: json.RawMessage(`"Globex"`)}},
+		}
+		first, _ := s.Append(entry)
+		s.Append(&models.AuditEntry{UserID: "user_1", InvoiceID: "inv_1", Action: models.AuditUpdated})
+		s.Append(&models.AuditEntry{UserID: "user_1", InvoiceID: "inv_2", Action: models.AuditCreated})
+
+		if first.ID != "aud_1" {
+			t.Errorf("expected ID aud_1, got %q", first.ID)
+		}
+
+		list, _ := s.List("user_1", "inv_1")
+		if len(list) != 2 || list[0].Action != models.AuditCreated || list[1].Action != models.AuditUpdated {
+			t.Fatalf("expected inv_1's entries in order, got %+v", list)
+		}
+		if other, _ := s.List("user_2", "inv_1"); len(other) != 0 {
+			t.Errorf("expected no entries for another user, got %d", len(other))
+		}
+
+		// Neither the appended entry nor listed copies share state with the store.
+		entry.Changes[0].New[1] = 'X'
+		list[0].Changes[0].Field = "changed"
+		again, _ := s.List("user_1", "inv_1")
+		if change := again[0].Changes[0]; change.Field != "clientName" || string(change.New) != `"Globex"` {
+			t.Errorf("expected the stored entry to be unchanged, got %s = %s", change.Field, change.New)
+		}
+	})
+}
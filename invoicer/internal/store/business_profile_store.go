@@ -0,0 +1,144 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBusinessProfileNotFound is returned when a business profile does not exist or belongs to another user.
+var ErrBusinessProfileNotFound = errors.New("business profile not found")
+
+// BusinessProfileStore persists the user's business profiles (trading entities).
+type BusinessProfileStore interface {
+	// Create stores a new profile for the user and returns the stored copy.
+	Create(userID string, profile *models.BusinessProfile) (*models.BusinessProfile, error)
+
+	// Get returns the user's profile with the given ID.
+	Get(userID, id string) (*models.BusinessProfile, error)
+
+	// List returns all of the user's profiles, sorted by name.
+	List(userID string) ([]*models.BusinessProfile, error)
+
+	// Update replaces the user's profile and returns the stored copy.
+	Update(userID, id string, profile *models.BusinessProfile) (*models.BusinessProfile, error)
+
+	// Delete removes the user's profile. Invoices keep the details they copied.
+	Delete(userID, id string) error
+}
+
+// MemoryBusinessProfileStore is a thread-safe in-memory BusinessProfileStore.
+type MemoryBusinessProfileStore struct {
+	mu       sync.RWMutex
+	profiles map[string]*models.BusinessProfile // keyed by profile ID
+	nextID   int
+}
+
+// NewMemoryBusinessProfileStore creates an empty in-memory business profile store.
+func NewMemoryBusinessProfileStore() *MemoryBusinessProfileStore {
+	return &MemoryBusinessProfileStore{
+		profiles: make(map[string]*models.BusinessProfile),
+	}
+}
+
+// Create stores a new profile for the user.
+func (s *MemoryBusinessProfileStore) Create(userID string, profile *models.BusinessProfile) (*models.BusinessProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := profile.Clone()
+	stored.ID = fmt.Sprintf("biz_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	s.profiles[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's profile with the given ID.
+func (s *MemoryBusinessProfileStore) Get(userID, id string) (*models.BusinessProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return profile.Clone(), nil
+}
+
+// List returns all of the user's profiles, sorted by name.
+func (s *MemoryBusinessProfileStore) List(userID string) ([]*models.BusinessProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.BusinessProfile, 0)
+	for _, profile := range s.profiles {
+		if profile.UserID == userID {
+			result = append(result, profile.Clone())
+		}
+	}
+
+	sortProfiles(result)
+	return result, nil
+}
+
+// Update replaces the user's profile.
+func (s *MemoryBusinessProfileStore) Update(userID, id string, profile *models.BusinessProfile) (*models.BusinessProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := profile.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	s.profiles[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's profile.
+func (s *MemoryBusinessProfileStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(userID, id); err != nil {
+		return err
+	}
+	delete(s.profiles, id)
+	return nil
+}
+
+// lookup finds a profile owned by userID. Callers must hold the lock.
+func (s *MemoryBusinessProfileStore) lookup(userID, id string) (*models.BusinessProfile, error) {
+	profile, exists := s.profiles[id]
+	if !exists || profile.UserID != userID {
+		return nil, ErrBusinessProfileNotFound
+	}
+	return profile, nil
+}
+
+// sortProfiles orders profiles by name, ignoring case.
+func sortProfiles(profiles []*models.BusinessProfile) {
+	sort.Slice(profiles, func(i, j int) bool {
+		a, b := strings.ToLower(profiles[i].Name), strings.ToLower(profiles[j].Name)
+		if a == b {
+			return profiles[i].ID < profiles[j].ID
+		}
+		return a < b
+	})
+}
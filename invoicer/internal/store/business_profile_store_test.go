@@ -0,0 +1,44 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestBusinessProfileStore_CRUD(t *testing.T) {
+	forEachBusinessProfileStore(t, func(t *testing.T, s BusinessProfileStore) {
+		acme, _ := s.Create("user_1", &models.BusinessProfile{Name: "Acme Ltd", TaxID: "GB123456789"})
+		s.Create("user_1", &models.BusinessProfile{Name: "acme consulting"})
+		s.Create("user_2", &models.BusinessProfile{Name: "Initech"})
+
+		list, _ := s.List("user_1")
+		if len(list) != 2 || list[0].Name != "acme consulting" || list[1].Name != "Acme Ltd" {
+			t.Fatalf("expected user_1's profiles sorted by name, got %+v", list)
+		}
+
+		if _, err := s.Get("user_2", acme.ID); !errors.Is(err, ErrBusinessProfileNotFound) {
+			t.Errorf("expected ErrBusinessProfileNotFound for another user's profile, got %v", err)
+		}
+
+		policy := &models.LateFeePolicy{Kind: models.LateFeePercent, Rate: 1.5, GraceDays: 7}
+		updated, err := s.Update("user_1", acme.ID, &models.BusinessProfile{Name: "Acme Ltd", LateFee: policy})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		policy.Rate = 99
+		if got, _ := s.Get("user_1", acme.ID); got.LateFee == nil || got.LateFee.Rate != 1.5 || !got.CreatedAt.Equal(acme.CreatedAt) {
+			t.Errorf("expected the stored policy to be a copy, got %+v", got.LateFee)
+		}
+		if updated.ID != acme.ID || updated.TaxID != "" {
+			t.Errorf("expected Update to replace the profile, got %+v", updated)
+		}
+
+		if err := s.Delete("user_1", acme.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", acme.ID); !errors.Is(err, ErrBusinessProfileNotFound) {
+			t.Errorf("expected ErrBusinessProfileNotFound after delete, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCatalogItemNotFound is returned when a catalog item does not exist or belongs to another user.
+	ErrCatalogItemNotFound = errors.New("catalog item not found")
+
+	// ErrDuplicateSKU is returned when another of the user's catalog items already has the SKU.
+	ErrDuplicateSKU = errors.New("SKU already in use")
+)
+
+// CatalogStore persists the user's product and service catalog.
+type CatalogStore interface {
+	// Create stores a new catalog item for the user and returns the stored copy.
+	Create(userID string, item *models.CatalogItem) (*models.CatalogItem, error)
+
+	// Get returns the user's catalog item with the given ID.
+	Get(userID, id string) (*models.CatalogItem, error)
+
+	// List returns all of the user's catalog items, sorted by name.
+	List(userID string) ([]*models.CatalogItem, error)
+
+	// Update replaces the user's catalog item and returns the stored copy.
+	Update(userID, id string, item *models.CatalogItem) (*models.CatalogItem, error)
+
+	// Delete removes the user's catalog item. Invoices keep the details they copied.
+	Delete(userID, id string) error
+}
+
+// MemoryCatalogStore is a thread-safe in-memory CatalogStore.
+type MemoryCatalogStore struct {
+	mu     sync.RWMutex
+	items  map[string]*models.CatalogItem // keyed by item ID
+	nextID int
+}
+
+// NewMemoryCatalogStore creates an empty in-memory catalog store.
+func NewMemoryCatalogStore() *MemoryCatalogStore {
+	return &MemoryCatalogStore{
+		items: make(map[string]*models.CatalogItem),
+	}
+}
+
+// Create stores a new catalog item for the user.
+func (s *MemoryCatalogStore) Create(userID string, item *models.CatalogItem) (*models.CatalogItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.skuTaken(userID, item.SKU, "") {
+		return nil, ErrDuplicateSKU
+	}
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := item.Clone()
+	stored.ID = fmt.Sprintf("cat_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	s.items[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's catalog item with the given ID.
+func (s *MemoryCatalogStore) Get(userID, id string) (*models.CatalogItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return item.Clone(), nil
+}
+
+// List returns all of the user's catalog items, sorted by name.
+func (s *MemoryCatalogStore) List(userID string) ([]*models.CatalogItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.CatalogItem, 0)
+	for _, item := range s.items {
+		if item.UserID == userID {
+			result = append(result, item.Clone())
+		}
+	}
+
+	sortCatalog(result)
+	return result, nil
+}
+
+// Update replaces the user's catalog item.
+func (s *MemoryCatalogStore) Update(userID, id string, item *models.CatalogItem) (*models.CatalogItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.skuTaken(userID, item.SKU, id) {
+		return nil, ErrDuplicateSKU
+	}
+
+	updated := item.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	s.items[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's catalog item.
+func (s *MemoryCatalogStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(userID, id); err != nil {
+		return err
+	}
+	delete(s.items, id)
+	return nil
+}
+
+// lookup finds a catalog item owned by userID. Callers must hold the lock.
+func (s *MemoryCatalogStore) lookup(userID, id string) (*models.CatalogItem, error) {
+	item, exists := s.items[id]
+	if !exists || item.UserID != userID {
+		return nil, ErrCatalogItemNotFound
+	}
+	return item, nil
+}
+
+// skuTaken reports whether another of the user's items (other than exceptID)
+// has the SKU, ignoring case. Callers must hold the lock.
+func (s *MemoryCatalogStore) skuTaken(userID, sku, exceptID string) bool {
+	if sku == "" {
+		return false
+	}
+	for _, item := range s.items {
+		if item.UserID == userID && item.ID != exceptID && strings.EqualFold(item.SKU, sku) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortCatalog orders catalog items by name, ignoring case.
+func sortCatalog(items []*models.CatalogItem) {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := strings.ToLower(items[i].Name), strings.ToLower(items[j].Name)
+		if a == b {
+			return items[i].ID < items[j].ID
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestCatalogStore_CRUD(t *testing.T) {
+	forEachCatalogStore(t, func(t *testing.T, s CatalogStore) {
+		consulting, _ := s.Create("user_1", &models.CatalogItem{Name: "Consulting", SKU: "CONS-1"})
+		s.Create("user_1", &models.CatalogItem{Name: "audit"})
+		s.Create("user_2", &models.CatalogItem{Name: "Hosting", SKU: "CONS-1"})
+
+		list, _ := s.List("user_1")
+		if len(list) != 2 || list[0].Name != "audit" || list[1].Name != "Consulting" {
+			t.Fatalf("expected user_1's items sorted by name, got %+v", list)
+		}
+
+		if _, err := s.Get("user_2", consulting.ID); !errors.Is(err, ErrCatalogItemNotFound) {
+			t.Errorf("expected ErrCatalogItemNotFound for another user's item, got %v", err)
+		}
+
+		if _, err := s.Create("user_1", &models.CatalogItem{Name: "Dup", SKU: "cons-1"}); !errors.Is(err, ErrDuplicateSKU) {
+			t.Errorf("expected ErrDuplicateSKU for a reused SKU, got %v", err)
+		}
+
+		updated, err := s.Update("user_1", consulting.ID, &models.CatalogItem{Name: "Consulting", SKU: "CONS-1", Unit: "hour"})
+		if err != nil {
+			t.Fatalf("Update keeping its own SKU failed: %v", err)
+		}
+		if updated.ID != consulting.ID || !updated.CreatedAt.Equal(consulting.CreatedAt) || updated.Unit != "hour" {
+			t.Errorf("unexpected updated item: %+v", updated)
+		}
+
+		if err := s.Delete("user_1", consulting.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", consulting.ID); !errors.Is(err, ErrCatalogItemNotFound) {
+			t.Errorf("expected ErrCatalogItemNotFound after delete, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClientNotFound is returned when a client does not exist or belongs to another user.
+var ErrClientNotFound = errors.New("client not found")
+
+// ClientStore persists the user's client directory.
+type ClientStore interface {
+	// Create stores a new client for the user and returns the stored copy.
+	Create(userID string, client *models.Client) (*models.Client, error)
+
+	// Get returns the user's client with the given ID.
+	Get(userID, id string) (*models.Client, error)
+
+	// List returns all of the user's clients, sorted by name.
+	List(userID string) ([]*models.Client, error)
+
+	// Update replaces the user's client and returns the stored copy.
+	Update(userID, id string, client *models.Client) (*models.Client, error)
+
+	// Delete removes the user's client. Invoices keep the details they copied.
+	Delete(userID, id string) error
+}
+
+// MemoryClientStore is a thread-safe in-memory ClientStore.
+type MemoryClientStore struct {
+	mu      sync.RWMutex
+	clients map[string]*models.Client // keyed by client ID
+	nextID  int
+}
+
+// NewMemoryClientStore creates an empty in-memory client store.
+func NewMemoryClientStore() *MemoryClientStore {
+	return &MemoryClientStore{
+		clients: make(map[string]*models.Client),
+	}
+}
+
+// Create stores a new client for the user.
+func (s *MemoryClientStore) Create(userID string, client *models.Client) (*models.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := client.Clone()
+	stored.ID = fmt.Sprintf("cli_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	s.clients[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's client with the given ID.
+func (s *MemoryClientStore) Get(userID, id string) (*models.Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	client, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return client.Clone(), nil
+}
+
+// List returns all of the user's clients, sorted by name.
+func (s *MemoryClientStore) List(userID string) ([]*models.Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.Client, 0)
+	for _, client := range s.clients {
+		if client.UserID == userID {
+			result = append(result, client.Clone())
+		}
+	}
+
+	sortClients(result)
+	return result, nil
+}
+
+// Update replaces the user's client.
+func (s *MemoryClientStore) Update(userID, id string, client *models.Client) (*models.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := client.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	s.clients[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's client.
+func (s *MemoryClientStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(userID, id); err != nil {
+		return err
+	}
+	delete(s.clients, id)
+	return nil
+}
+
+// lookup finds a client owned by userID. Callers must hold the lock.
+func (s *MemoryClientStore) lookup(userID, id string) (*models.Client, error) {
+	client, exists := s.clients[id]
+	if !exists || client.UserID != userID {
+		return nil, ErrClientNotFound
+	}
+	return client, nil
+}
+
+// sortClients orders clients by name, ignoring case.
+func sortClients(clients []*models.Client) {
+	sort.Slice(clients, func(i, j int) bool {
+		a, b := strings.ToLower(clients[i].Name), strings.ToLower(clients[j].Name)
+		if a == b {
+			return clients[i].ID < clients[j].ID
+		}
+		return a < b
+	})
+}
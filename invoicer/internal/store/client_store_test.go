@@ -0,0 +1,39 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestClientStore_CRUD(t *testing.T) {
+	forEachClientStore(t, func(t *testing.T, s ClientStore) {
+		globex, _ := s.Create("user_1", &models.Client{Name: "Globex", Email: "ap@globex.example"})
+		s.Create("user_1", &models.Client{Name: "acme"})
+		s.Create("user_2", &models.Client{Name: "Initech"})
+
+		list, _ := s.List("user_1")
+		if len(list) != 2 || list[0].Name != "acme" || list[1].Name != "Globex" {
+			t.Fatalf("expected user_1's clients sorted by name, got %+v", list)
+		}
+
+		if _, err := s.Get("user_2", globex.ID); !errors.Is(err, ErrClientNotFound) {
+			t.Errorf("expected ErrClientNotFound for another user's client, got %v", err)
+		}
+
+		updated, err := s.Update("user_1", globex.ID, &models.Client{Name: "Globex Corp", Currency: "EUR"})
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if updated.ID != globex.ID || !updated.CreatedAt.Equal(globex.CreatedAt) || updated.Currency != "EUR" {
+			t.Errorf("unexpected updated client: %+v", updated)
+		}
+
+		if err := s.Delete("user_1", globex.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", globex.ID); !errors.Is(err, ErrClientNotFound) {
+			t.Errorf("expected ErrClientNotFound after delete, got %v", err)
+		}
+	})
+}
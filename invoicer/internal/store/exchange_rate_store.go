@@ -0,0 +1,60 @@
+package store
+
+import (
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"sync"
+)
+
+// ExchangeRateStore persists the exchange rate table shared by all users.
+type ExchangeRateStore interface {
+	// Put stores a day's rates, replacing any rates for the same date and base.
+	Put(rates *models.ExchangeRates) error
+
+	// List returns every day's rates, oldest first.
+	List() ([]*models.ExchangeRates, error)
+}
+
+// MemoryExchangeRateStore is a thread-safe in-memory ExchangeRateStore.
+type MemoryExchangeRateStore struct {
+	mu    sync.RWMutex
+	rates []*models.ExchangeRates // sorted by date, then base
+}
+
+// NewMemoryExchangeRateStore creates an empty in-memory exchange rate store.
+func NewMemoryExchangeRateStore() *MemoryExchangeRateStore {
+	return &MemoryExchangeRateStore{}
+}
+
+// Put stores a copy of the rates, replacing any for the same date and base.
+func (s *MemoryExchangeRateStore) Put(rates *models.ExchangeRates) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := rates.Clone()
+	// Find the first entry at or after (date, base).
+	i := sort.Search(len(s.rates), func(i int) bool {
+		r := s.rates[i]
+		return r.Date.After(stored.Date) || !r.Date.Before(stored.Date) && r.Base >= stored.Base
+	})
+	if i < len(s.rates) && s.rates[i].Date.String() == stored.Date.String() && s.rates[i].Base == stored.Base {
+		s.rates[i] = stored
+		return nil
+	}
+	s.rates = append(s.rates, nil)
+	copy(s.rates[i+1:], s.rates[i:])
+	s.rates[i] = stored
+	return nil
+}
+
+// List returns copies of every day's rates, oldest first.
+func (s *MemoryExchangeRateStore) List() ([]*models.ExchangeRates, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.ExchangeRates, len(s.rates))
+	for i, r := range s.rates {
+		result[i] = r.Clone()
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestExchangeRateStore_PutAndList(t *testing.T) {
+	forEachExchangeRateStore(t, func(t *testing.T, s ExchangeRateStore) {
+		s.Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"INR": 90}})
+		s.Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-01"), Base: "USD", Rates: map[string]float64{"INR": 83}})
+		s.Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-01"), Base: "EUR", Rates: map[string]float64{"INR": 89}})
+
+		// Replaces the rates for the same date and base.
+		replacement := &models.ExchangeRates{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"INR": 91}}
+		s.Put(replacement)
+		replacement.Rates["INR"] = 0
+
+		list, _ := s.List()
+		if len(list) != 3 {
+			t.Fatalf("expected 3 days of rates, got %d", len(list))
+		}
+		want := []string{"2026-03-01 EUR", "2026-03-01 USD", "2026-03-02 EUR"}
+		for i, r := range list {
+			if got := r.Date.String() + " " + r.Base; got != want[i] {
+				t.Errorf("list[%d]: expected %s, got %s", i, want[i], got)
+			}
+		}
+		if list[2].Rates["INR"] != 91 {
+			t.Errorf("expected the replaced rate 91, got %v", list[2].Rates["INR"])
+		}
+
+		list[0].Rates["INR"] = 1
+		if again, _ := s.List(); again[0].Rates["INR"] != 89 {
+			t.Error("expected List to return copies")
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/promos"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvoiceNotFound is returned when an invoice does not exist or belongs to another user.
+	ErrInvoiceNotFound = errors.New("invoice not found")
+
+	// ErrDuplicateNumber is returned when the user already has an invoice with the same number.
+	ErrDuplicateNumber = errors.New("invoice number already in use")
+
+	// ErrDuplicateOccurrence is returned when a recurring schedule has already
+	// generated an invoice for the same period.
+	ErrDuplicateOccurrence = errors.New("recurring period already invoiced")
+)
+
+// Journal returns the history entry for a change to an invoice: before is
+// nil for a new invoice and after is nil for a deleted one. Invoice stores
+// append the entry in the same write as the change, so neither is saved
+// without the other. A nil Journal records no history. It must not modify
+// the invoices.
+type Journal func(before, after *models.Invoice) (*models.AuditEntry, error)
+
+// entry returns the journal's entry for the change, or nil for a nil Journal.
+func (j Journal) entry(before, after *models.Invoice) (*models.AuditEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+	return j(before, after)
+}
+
+// newPromoCodes returns the promo codes that invoice lists in
+// RedeemedPromoCodes but counted does not. Invoice stores count one use of
+// each in the same write as the invoice, so a code is never used by an
+// invoice that was not saved.
+func newPromoCodes(invoice *models.Invoice, counted []string) []string {
+	var codes []string
+	for _, code := range invoice.RedeemedPromoCodes {
+		if !slices.Contains(counted, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// revisedPromoCodes returns the promo codes already counted for the invoice a
+// revision replaces, which the revision carries over: the codes it used and
+// the code it kept from a quote.
+func revisedPromoCodes(previous *models.Invoice) []string {
+	if previous == nil {
+		return nil
+	}
+	return append(slices.Clone(previous.RedeemedPromoCodes), previous.PromoCode)
+}
+
+// usableToday is the check a promo code must pass to be counted.
+func usableToday(p *models.PromoCode) error {
+	return promos.Usable(p, civil.Of(time.Now().UTC()))
+}
+
+// InvoiceStore persists invoices. Every operation is scoped to the owning user,
+// so an invoice is only visible to the user who created it. Changes are
+// recorded in the invoice's history by the journal passed with them, and the
+// promo codes an invoice newly lists in RedeemedPromoCodes are counted as
+// used when it is saved; a code that is no longer usable fails the write.
+type InvoiceStore interface {
+	// Create stores a new invoice for the user and returns the stored copy.
+	// Invoice numbers must be unique per user, as must the recurring schedule
+	// and period of generated invoices. A revision's codes already counted for
+	// the invoice it revises are not counted again.
+	Create(userID string, invoice *models.Invoice, journal Journal) (*models.Invoice, error)
+
+	// Get returns the user's invoice with the given ID.
+	Get(userID, id string) (*models.Invoice, error)
+
+	// List returns all of the user's invoices, newest first.
+	List(userID string) ([]*models.Invoice, error)
+
+	// ListAll returns every user's invoices. It is used by the overdue job.
+	ListAll() ([]*models.Invoice, error)
+
+	// Update loads the invoice, passes a copy to fn and stores the result if fn
+	// returns nil. The read-modify-write happens atomically.
+	Update(userID, id string, fn func(invoice *models.Invoice) error, journal Journal) (*models.Invoice, error)
+
+	// Delete removes the user's invoice with the given ID. If check is not nil
+	// it is called with the stored invoice first, and a non-nil result aborts
+	// the deletion.
+	Delete(userID, id string, check func(invoice *models.Invoice) error, journal Journal) error
+}
+
+// MemoryInvoiceStore is a thread-safe in-memory InvoiceStore.
+type MemoryInvoiceStore struct {
+	mu         sync.RWMutex
+	invoices   map[string]*models.Invoice // keyed by invoice ID
+	nextID     int
+	history    AuditStore
+	promoCodes PromoCodeStore
+}
+
+// NewMemoryInvoiceStore creates an empty in-memory invoice store that records
+// history in history and counts the uses of promo codes in promoCodes. With a
+// nil history, journal entries are discarded; with nil promoCodes, uses are
+// not counted.
+func NewMemoryInvoiceStore(history AuditStore, promoCodes PromoCodeStore) *MemoryInvoiceStore {
+	return &MemoryInvoiceStore{
+		invoices:   make(map[string]*models.Invoice),
+		history:    history,
+		promoCodes: promoCodes,
+	}
+}
+
+// Create stores a new invoice for the user.
+func (s *MemoryInvoiceStore) Create(userID string, invoice *models.Invoice, journal Journal) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.numberTaken(userID, invoice.InvoiceNumber, "") {
+		return nil, ErrDuplicateNumber
+	}
+	if s.occurrenceTaken(userID, invoice.RecurringID, invoice.RecurringPeriod) {
+		return nil, ErrDuplicateOccurrence
+	}
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := invoice.Clone()
+	stored.ID = fmt.Sprintf("inv_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	var previous *models.Invoice
+	if stored.PreviousRevisionID != "" {
+		previous, _ = s.lookup(userID, stored.PreviousRevisionID)
+	}
+	if err := s.save(userID, journal, nil, stored, revisedPromoCodes(previous)); err != nil {
+		s.nextID--
+		return nil, err
+	}
+	s.invoices[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's invoice with the given ID.
+func (s *MemoryInvoiceStore) Get(userID, id string) (*models.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	invoice, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return invoice.Clone(), nil
+}
+
+// List returns all of the user's invoices, newest first.
+func (s *MemoryInvoiceStore) List(userID string) ([]*models.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.Invoice, 0)
+	for _, invoice := range s.invoices {
+		if invoice.UserID == userID {
+			result = append(result, invoice.Clone())
+		}
+	}
+	sortInvoices(result)
+	return result, nil
+}
+
+// ListAll returns every user's invoices, newest first.
+func (s *MemoryInvoiceStore) ListAll() ([]*models.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.Invoice, 0, len(s.invoices))
+	for _, invoice := range s.invoices {
+		result = append(result, invoice.Clone())
+	}
+	sortInvoices(result)
+	return result, nil
+}
+
+// sortInvoices orders invoices newest first.
+func sortInvoices(invoices []*models.Invoice) {
+	sort.Slice(invoices, func(i, j int) bool {
+		if invoices[i].CreatedAt.Equal(invoices[j].CreatedAt) {
+			return invoices[i].ID > invoices[j].ID
+		}
+		return invoices[i].CreatedAt.After(invoices[j].CreatedAt)
+	})
+}
+
+// Update atomically applies fn to a copy of the user's invoice and stores the result.
+// Identity fields (ID, owner, creation time) cannot be changed by fn.
+func (s *MemoryInvoiceStore) Update(userID, id string, fn func(invoice *models.Invoice) error, journal Journal) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := existing.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+
+	if s.numberTaken(userID, updated.InvoiceNumber, id) {
+		return nil, ErrDuplicateNumber
+	}
+
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	if err := s.save(userID, journal, existing, updated, existing.RedeemedPromoCodes); err != nil {
+		return nil, err
+	}
+	s.invoices[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's invoice with the given ID once check (if any) passes.
+func (s *MemoryInvoiceStore) Delete(userID, id string, check func(invoice *models.Invoice) error, journal Journal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(existing.Clone()); err != nil {
+			return err
+		}
+	}
+	if err := s.record(journal, existing, nil); err != nil {
+		return err
+	}
+	delete(s.invoices, id)
+	return nil
+}
+
+// save counts the promo codes after newly uses and records the change in the
+// journal before it is applied, giving the uses back if either fails, so a
+// failure leaves the invoice and the codes unchanged. Callers must hold the lock.
+func (s *MemoryInvoiceStore) save(userID string, journal Journal, before, after *models.Invoice, counted []string) error {
+	var redeemed []string
+	release := func() {
+		for _, code := range redeemed {
+			s.promoCodes.Release(userID, code)
+		}
+	}
+	if s.promoCodes != nil {
+		for _, code := range newPromoCodes(after, counted) {
+			if _, err := s.promoCodes.Redeem(userID, code, usableToday); err != nil {
+				release()
+				return err
+			}
+			redeemed = append(redeemed, code)
+		}
+	}
+	if err := s.record(journal, before, after); err != nil {
+		release()
+		return err
+	}
+	return nil
+}
+
+// record appends the journal's entry for a change before it is applied, so a
+// failure leaves the invoice unchanged. Callers must hold the lock.
+func (s *MemoryInvoiceStore) record(journal Journal, before, after *models.Invoice) error {
+	entry, err := journal.entry(before, after)
+	if err != nil || entry == nil || s.history == nil {
+		return err
+	}
+	_, err = s.history.Append(entry)
+	return err
+}
+
+// lookup finds an invoice owned by userID. Callers must hold the lock.
+func (s *MemoryInvoiceStore) lookup(userID, id string) (*models.Invoice, error) {
+	invoice, exists := s.invoices[id]
+	if !exists || invoice.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// numberTaken reports whether another of the user's invoices (other than
+// excludeID) already uses number. Callers must hold the lock.
+func (s *MemoryInvoiceStore) numberTaken(userID, number, excludeID string) bool {
+	if number == "" {
+		return false
+	}
+	for id, invoice := range s.invoices {
+		if id != excludeID && invoice.UserID == userID && invoice.InvoiceNumber == number {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrenceTaken reports whether the user already has an invoice generated by
+// the recurring schedule for period. Callers must hold the lock.
+func (s *MemoryInvoiceStore) occurrenceTaken(userID, recurringID, period string) bool {
+	if recurringID == "" {
+		return false
+	}
+	for _, invoice := range s.invoices {
+		if invoice.UserID == userID && invoice.RecurringID == recurringID && invoice.RecurringPeriod == period {
+			return true
+		}
+	}
+	return false
+}
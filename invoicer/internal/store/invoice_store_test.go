@@ -0,0 +1,303 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"invoice-generator/invoicer/internal/promos"
+	"testing"
+)
+
+func newTestInvoice(number string) *models.Invoice {
+	return &models.Invoice{
+		InvoiceNumber: number,
+		BusinessName:  "Acme Corp",
+		ClientName:    "Globex",
+		Items: []models.LineItem{
+			{Description: "Consulting", Quantity: 2, Rate: money.New(10000, "USD"), Amount: money.New(20000, "USD")},
+		},
+		Subtotal: money.New(20000, "USD"),
+		Total:    money.New(20000, "USD"),
+		Currency: "USD",
+	}
+}
+
+func TestInvoiceStore_CreateAndGet(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+
+		created, err := s.Create("user_1", newTestInvoice("INV-001"), nil)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if created.ID == "" {
+			t.Fatal("expected non-empty invoice ID")
+		}
+		if created.UserID != "user_1" {
+			t.Errorf("expected UserID 'user_1', got %q", created.UserID)
+		}
+		if created.CreatedAt.IsZero() {
+			t.Error("expected CreatedAt to be set")
+		}
+
+		found, err := s.Get("user_1", created.ID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if found.InvoiceNumber != "INV-001" {
+			t.Errorf("expected invoice number 'INV-001', got %q", found.InvoiceNumber)
+		}
+
+		// Mutating the returned copy must not affect the stored invoice
+		found.Items[0].Description = "Changed"
+		again, _ := s.Get("user_1", created.ID)
+		if again.Items[0].Description != "Consulting" {
+			t.Error("stored invoice was modified through a returned copy")
+		}
+	})
+}
+
+func TestInvoiceStore_ScopedToUser(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+
+		created, _ := s.Create("user_1", newTestInvoice("INV-001"), nil)
+		s.Create("user_2", newTestInvoice("INV-002"), nil)
+
+		if _, err := s.Get("user_2", created.ID); !errors.Is(err, ErrInvoiceNotFound) {
+			t.Errorf("expected ErrInvoiceNotFound for another user's invoice, got %v", err)
+		}
+
+		list, err := s.List("user_1")
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(list) != 1 || list[0].ID != created.ID {
+			t.Errorf("expected only user_1's invoice, got %d invoices", len(list))
+		}
+
+		if err := s.Delete("user_2", created.ID, nil, nil); !errors.Is(err, ErrInvoiceNotFound) {
+			t.Errorf("expected ErrInvoiceNotFound when deleting another user's invoice, got %v", err)
+		}
+	})
+}
+
+func TestInvoiceStore_Update(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+		created, _ := s.Create("user_1", newTestInvoice("INV-001"), nil)
+
+		updated, err := s.Update("user_1", created.ID, func(inv *models.Invoice) error {
+			inv.ClientName = "Initech"
+			inv.ID = "tampered"
+			return nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if updated.ClientName != "Initech" {
+			t.Errorf("expected client name 'Initech', got %q", updated.ClientName)
+		}
+		if updated.ID != created.ID {
+			t.Errorf("expected ID to be preserved, got %q", updated.ID)
+		}
+
+		// A failing update leaves the stored invoice untouched
+		_, err = s.Update("user_1", created.ID, func(inv *models.Invoice) error {
+			inv.ClientName = "Umbrella"
+			return errors.New("rejected")
+		}, nil)
+		if err == nil {
+			t.Fatal("expected error from rejected update")
+		}
+		found, _ := s.Get("user_1", created.ID)
+		if found.ClientName != "Initech" {
+			t.Errorf("expected client name 'Initech' after rejected update, got %q", found.ClientName)
+		}
+	})
+}
+
+func TestInvoiceStore_Delete(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+		created, _ := s.Create("user_1", newTestInvoice("INV-001"), nil)
+
+		// A failing check keeps the invoice
+		if err := s.Delete("user_1", created.ID, func(*models.Invoice) error { return errors.New("locked") }, nil); err == nil {
+			t.Fatal("expected failing check to abort Delete")
+		}
+		if _, err := s.Get("user_1", created.ID); err != nil {
+			t.Fatalf("expected invoice to survive aborted delete, got %v", err)
+		}
+
+		if err := s.Delete("user_1", created.ID, nil, nil); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", created.ID); !errors.Is(err, ErrInvoiceNotFound) {
+			t.Errorf("expected ErrInvoiceNotFound after delete, got %v", err)
+		}
+	})
+}
+
+func TestInvoiceStore_DuplicateNumber(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+		first, _ := s.Create("user_1", newTestInvoice("INV-001"), nil)
+		second, _ := s.Create("user_1", newTestInvoice("INV-002"), nil)
+
+		if _, err := s.Create("user_1", newTestInvoice("INV-001"), nil); !errors.Is(err, ErrDuplicateNumber) {
+			t.Errorf("expected ErrDuplicateNumber on create, got %v", err)
+		}
+
+		// Numbers are unique per user, not globally
+		if _, err := s.Create("user_2", newTestInvoice("INV-001"), nil); err != nil {
+			t.Errorf("expected another user to reuse the number, got %v", err)
+		}
+
+		_, err := s.Update("user_1", second.ID, func(inv *models.Invoice) error {
+			inv.InvoiceNumber = first.InvoiceNumber
+			return nil
+		}, nil)
+		if !errors.Is(err, ErrDuplicateNumber) {
+			t.Errorf("expected ErrDuplicateNumber on update, got %v", err)
+		}
+
+		// Keeping its own number is not a conflict
+		if _, err := s.Update("user_1", first.ID, func(inv *models.Invoice) error { return nil }, nil); err != nil {
+			t.Errorf("expected update keeping the same number to succeed, got %v", err)
+		}
+	})
+}
+
+func TestInvoiceStore_JournalIsWrittenWithTheChange(t *testing.T) {
+	backends := map[string]func(t *testing.T) (InvoiceStore, AuditStore){
+		"memory": func(t *testing.T) (InvoiceStore, AuditStore) {
+			history := NewMemoryAuditStore()
+			return NewMemoryInvoiceStore(history, nil), history
+		},
+		"sqlite": func(t *testing.T) (InvoiceStore, AuditStore) {
+			db := openTestDB(t)
+			return NewSQLiteInvoiceStore(db), NewSQLiteAuditStore(db)
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			s, history := open(t)
+			journal := func(action string) Journal {
+				return func(before, after *models.Invoice) (*models.AuditEntry, error) {
+					invoice := after
+					if invoice == nil {
+						invoice = before
+					}
+					return &models.AuditEntry{UserID: invoice.UserID, InvoiceID: invoice.ID, Action: action}, nil
+				}
+			}
+			failing := func(before, after *models.Invoice) (*models.AuditEntry, error) {
+				return nil, errors.New("journal failed")
+			}
+
+			created, err := s.Create("user_1", newTestInvoice("INV-001"), journal(models.AuditCreated))
+			if err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			if _, err := s.Create("user_1", newTestInvoice("INV-002"), failing); err == nil {
+				t.Error("expected Create to fail with its journal")
+			}
+			if list, _ := s.List("user_1"); len(list) != 1 {
+				t.Errorf("expected the failed create not to be saved, got %d invoices", len(list))
+			}
+
+			_, err = s.Update("user_1", created.ID, func(inv *models.Invoice) error {
+				inv.ClientName = "Initech"
+				return nil
+			}, failing)
+			if err == nil {
+				t.Error("expected Update to fail with its journal")
+			}
+			if found, _ := s.Get("user_1", created.ID); found.ClientName != "Globex" {
+				t.Errorf("expected the failed update not to be saved, got client %q", found.ClientName)
+			}
+
+			if err := s.Delete("user_1", created.ID, nil, failing); err == nil {
+				t.Error("expected Delete to fail with its journal")
+			}
+			if err := s.Delete("user_1", created.ID, nil, journal(models.AuditDeleted)); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+
+			entries, _ := history.List("user_1", created.ID)
+			if len(entries) != 2 || entries[0].Action != models.AuditCreated || entries[1].Action != models.AuditDeleted {
+				t.Errorf("expected created and deleted entries only, got %+v", entries)
+			}
+		})
+	}
+}
+
+func TestInvoiceStore_CountsPromoCodesWithTheInvoice(t *testing.T) {
+	backends := map[string]func(t *testing.T) (InvoiceStore, PromoCodeStore){
+		"memory": func(t *testing.T) (InvoiceStore, PromoCodeStore) {
+			codes := NewMemoryPromoCodeStore()
+			return NewMemoryInvoiceStore(nil, codes), codes
+		},
+		"sqlite": func(t *testing.T) (InvoiceStore, PromoCodeStore) {
+			db := openTestDB(t)
+			return NewSQLiteInvoiceStore(db), NewSQLitePromoCodeStore(db)
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			s, codes := open(t)
+			codes.Create("user_1", &models.PromoCode{Code: "TWICE", MaxUses: 2})
+			uses := func() int {
+				code, _ := codes.GetByCode("user_1", "TWICE")
+				return code.Uses
+			}
+			failing := func(before, after *models.Invoice) (*models.AuditEntry, error) {
+				return nil, errors.New("journal failed")
+			}
+
+			invoice := newTestInvoice("INV-001")
+			invoice.PromoCode, invoice.RedeemedPromoCodes = "TWICE", []string{"TWICE"}
+			if _, err := s.Create("user_1", invoice, failing); err == nil {
+				t.Fatal("expected Create to fail with its journal")
+			}
+			if uses() != 0 {
+				t.Errorf("expected no use for an invoice that was not saved, got %d", uses())
+			}
+
+			created, err := s.Create("user_1", invoice, nil)
+			if err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			if _, err := s.Update("user_1", created.ID, func(inv *models.Invoice) error {
+				inv.ClientName = "Initech"
+				return nil
+			}, nil); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			if uses() != 1 {
+				t.Errorf("expected the code to be counted once for the invoice, got %d", uses())
+			}
+
+			// A revision carries the code over without using it again
+			revision := newTestInvoice("INV-001-R1")
+			revision.PreviousRevisionID = created.ID
+			revision.PromoCode, revision.RedeemedPromoCodes = "TWICE", []string{"TWICE"}
+			if _, err := s.Create("user_1", revision, nil); err != nil {
+				t.Fatalf("Create of the revision failed: %v", err)
+			}
+			if uses() != 1 {
+				t.Errorf("expected the revision not to count the code again, got %d", uses())
+			}
+
+			second := newTestInvoice("INV-002")
+			second.PromoCode, second.RedeemedPromoCodes = "TWICE", []string{"TWICE"}
+			if _, err := s.Create("user_1", second, nil); err != nil {
+				t.Fatalf("Create of a second invoice failed: %v", err)
+			}
+			third := newTestInvoice("INV-003")
+			third.PromoCode, third.RedeemedPromoCodes = "TWICE", []string{"TWICE"}
+			if _, err := s.Create("user_1", third, nil); !errors.Is(err, promos.ErrUsedUp) {
+				t.Errorf("expected ErrUsedUp once the code is used up, got %v", err)
+			}
+			if list, _ := s.List("user_1"); len(list) != 3 || uses() != 2 {
+				t.Errorf("expected the third invoice not to be saved, got %d invoices and %d uses", len(list), uses())
+			}
+		})
+	}
+}
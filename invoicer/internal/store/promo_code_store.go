@@ -0,0 +1,215 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPromoCodeNotFound is returned when a promo code does not exist or belongs to another user.
+	ErrPromoCodeNotFound = errors.New("promo code not found")
+
+	// ErrDuplicatePromoCode is returned when another of the user's promo codes already has the code.
+	ErrDuplicatePromoCode = errors.New("promo code already in use")
+)
+
+// PromoCodeStore persists the user's promo codes.
+type PromoCodeStore interface {
+	// Create stores a new promo code for the user and returns the stored copy.
+	Create(userID string, code *models.PromoCode) (*models.PromoCode, error)
+
+	// Get returns the user's promo code with the given ID.
+	Get(userID, id string) (*models.PromoCode, error)
+
+	// GetByCode returns the user's promo code with the given code, ignoring case.
+	GetByCode(userID, code string) (*models.PromoCode, error)
+
+	// List returns all of the user's promo codes, sorted by code.
+	List(userID string) ([]*models.PromoCode, error)
+
+	// Update replaces the user's promo code, keeping its use count, and
+	// returns the stored copy.
+	Update(userID, id string, code *models.PromoCode) (*models.PromoCode, error)
+
+	// Delete removes the user's promo code. Invoices keep the discount they copied.
+	Delete(userID, id string) error
+
+	// Redeem atomically calls check with the user's promo code and, if it
+	// returns nil, counts one use of the code. It returns the code as redeemed.
+	Redeem(userID, code string, check func(*models.PromoCode) error) (*models.PromoCode, error)
+
+	// Release gives back one use of the user's promo code, undoing a Redeem
+	// whose document could not be saved.
+	Release(userID, code string) error
+}
+
+// MemoryPromoCodeStore is a thread-safe in-memory PromoCodeStore.
+type MemoryPromoCodeStore struct {
+	mu     sync.RWMutex
+	codes  map[string]*models.PromoCode // keyed by promo code ID
+	nextID int
+}
+
+// NewMemoryPromoCodeStore creates an empty in-memory promo code store.
+func NewMemoryPromoCodeStore() *MemoryPromoCodeStore {
+	return &MemoryPromoCodeStore{
+		codes: make(map[string]*models.PromoCode),
+	}
+}
+
+// Create stores a new promo code for the user with no uses.
+func (s *MemoryPromoCodeStore) Create(userID string, code *models.PromoCode) (*models.PromoCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findByCode(userID, code.Code, "") != nil {
+		return nil, ErrDuplicatePromoCode
+	}
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := code.Clone()
+	stored.ID = fmt.Sprintf("promo_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.Uses = 0
+
+	s.codes[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's promo code with the given ID.
+func (s *MemoryPromoCodeStore) Get(userID, id string) (*models.PromoCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	code, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return code.Clone(), nil
+}
+
+// GetByCode returns the user's promo code with the given code, ignoring case.
+func (s *MemoryPromoCodeStore) GetByCode(userID, code string) (*models.PromoCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := s.findByCode(userID, code, "")
+	if found == nil {
+		return nil, ErrPromoCodeNotFound
+	}
+	return found.Clone(), nil
+}
+
+// List returns all of the user's promo codes, sorted by code.
+func (s *MemoryPromoCodeStore) List(userID string) ([]*models.PromoCode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.PromoCode, 0)
+	for _, code := range s.codes {
+		if code.UserID == userID {
+			result = append(result, code.Clone())
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result, nil
+}
+
+// Update replaces the user's promo code, keeping its use count.
+func (s *MemoryPromoCodeStore) Update(userID, id string, code *models.PromoCode) (*models.PromoCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.findByCode(userID, code.Code, id) != nil {
+		return nil, ErrDuplicatePromoCode
+	}
+
+	updated := code.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+	updated.Uses = existing.Uses
+
+	s.codes[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's promo code.
+func (s *MemoryPromoCodeStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(userID, id); err != nil {
+		return err
+	}
+	delete(s.codes, id)
+	return nil
+}
+
+// Redeem checks the user's promo code and counts one use of it.
+func (s *MemoryPromoCodeStore) Redeem(userID, code string, check func(*models.PromoCode) error) (*models.PromoCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.findByCode(userID, code, "")
+	if found == nil {
+		return nil, ErrPromoCodeNotFound
+	}
+	if err := check(found.Clone()); err != nil {
+		return nil, err
+	}
+	found.Uses++
+	return found.Clone(), nil
+}
+
+// Release gives back one use of the user's promo code.
+func (s *MemoryPromoCodeStore) Release(userID, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.findByCode(userID, code, "")
+	if found == nil {
+		return ErrPromoCodeNotFound
+	}
+	if found.Uses > 0 {
+		found.Uses--
+	}
+	return nil
+}
+
+// lookup finds a promo code owned by userID. Callers must hold the lock.
+func (s *MemoryPromoCodeStore) lookup(userID, id string) (*models.PromoCode, error) {
+	code, exists := s.codes[id]
+	if !exists || code.UserID != userID {
+		return nil, ErrPromoCodeNotFound
+	}
+	return code, nil
+}
+
+// findByCode returns the user's promo code (other than exceptID) with the
+// code, ignoring case, or nil. Callers must hold the lock.
+func (s *MemoryPromoCodeStore) findByCode(userID, code, exceptID string) *models.PromoCode {
+	for _, p := range s.codes {
+		if p.UserID == userID && p.ID != exceptID && strings.EqualFold(p.Code, code) {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+	"testing"
+)
+
+func TestPromoCodeStore_CRUD(t *testing.T) {
+	forEachPromoCodeStore(t, func(t *testing.T, s PromoCodeStore) {
+		spring, _ := s.Create("user_1", &models.PromoCode{Code: "SPRING10", Uses: 99})
+		s.Create("user_1", &models.PromoCode{Code: "AUTUMN"})
+		s.Create("user_2", &models.PromoCode{Code: "SPRING10"})
+
+		if spring.Uses != 0 {
+			t.Errorf("expected a new code to start without uses, got %d", spring.Uses)
+		}
+
+		list, _ := s.List("user_1")
+		if len(list) != 2 || list[0].Code != "AUTUMN" || list[1].Code != "SPRING10" {
+			t.Fatalf("expected user_1's codes sorted by code, got %+v", list)
+		}
+
+		if got, err := s.GetByCode("user_1", "spring10"); err != nil || got.ID != spring.ID {
+			t.Errorf("expected lookup by code to ignore case, got %+v (err %v)", got, err)
+		}
+		if _, err := s.Get("user_2", spring.ID); !errors.Is(err, ErrPromoCodeNotFound) {
+			t.Errorf("expected ErrPromoCodeNotFound for another user's code, got %v", err)
+		}
+		if _, err := s.Create("user_1", &models.PromoCode{Code: "Spring10"}); !errors.Is(err, ErrDuplicatePromoCode) {
+			t.Errorf("expected ErrDuplicatePromoCode for a reused code, got %v", err)
+		}
+
+		s.Redeem("user_1", "SPRING10", func(*models.PromoCode) error { return nil })
+		updated, err := s.Update("user_1", spring.ID, &models.PromoCode{Code: "SPRING10", MaxUses: 5})
+		if err != nil {
+			t.Fatalf("Update keeping its own code failed: %v", err)
+		}
+		if updated.MaxUses != 5 || updated.Uses != 1 || !updated.CreatedAt.Equal(spring.CreatedAt) {
+			t.Errorf("expected the update to keep the use count, got %+v", updated)
+		}
+
+		if err := s.Delete("user_1", spring.ID); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.GetByCode("user_1", "SPRING10"); !errors.Is(err, ErrPromoCodeNotFound) {
+			t.Errorf("expected ErrPromoCodeNotFound after delete, got %v", err)
+		}
+	})
+}
+
+func TestPromoCodeStore_RedeemIsAtomic(t *testing.T) {
+	forEachPromoCodeStore(t, func(t *testing.T, s PromoCodeStore) {
+		s.Create("user_1", &models.PromoCode{Code: "FIRST5", MaxUses: 5})
+
+		usedUp := errors.New("used up")
+		check := func(p *models.PromoCode) error {
+			if p.Uses >= p.MaxUses {
+				return usedUp
+			}
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		redeemed := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := s.Redeem("user_1", "first5", check); err == nil {
+					mu.Lock()
+					redeemed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		code, _ := s.GetByCode("user_1", "FIRST5")
+		if redeemed != 5 || code.Uses != 5 {
+			t.Errorf("expected exactly 5 redemptions, got %d (uses %d)", redeemed, code.Uses)
+		}
+	})
+}
+
+func TestPromoCodeStore_Release(t *testing.T) {
+	forEachPromoCodeStore(t, func(t *testing.T, s PromoCodeStore) {
+		s.Create("user_1", &models.PromoCode{Code: "ONCE", MaxUses: 1})
+		s.Redeem("user_1", "ONCE", func(*models.PromoCode) error { return nil })
+
+		if err := s.Release("user_1", "once"); err != nil {
+			t.Fatalf("Release failed: %v", err)
+		}
+		if err := s.Release("user_1", "ONCE"); err != nil {
+			t.Fatalf("Release of an unused code failed: %v", err)
+		}
+		if code, _ := s.GetByCode("user_1", "ONCE"); code.Uses != 0 {
+			t.Errorf("expected the use to be given back once, got %d uses", code.Uses)
+		}
+		if err := s.Release("user_2", "ONCE"); !errors.Is(err, ErrPromoCodeNotFound) {
+			t.Errorf("expected ErrPromoCodeNotFound for another user's code, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrScheduleNotFound is returned when a recurring schedule does not exist or belongs to another user.
+var ErrScheduleNotFound = errors.New("recurring schedule not found")
+
+// RecurringStore persists recurring invoice schedules, scoped to the owning user.
+type RecurringStore interface {
+	// Create stores a new schedule for the user and returns the stored copy.
+	Create(userID string, schedule *models.RecurringSchedule) (*models.RecurringSchedule, error)
+
+	// Get returns the user's schedule with the given ID.
+	Get(userID, id string) (*models.RecurringSchedule, error)
+
+	// List returns all of the user's schedules, newest first.
+	List(userID string) ([]*models.RecurringSchedule, error)
+
+	// ListAll returns every user's schedules. It is used by the scheduler.
+	ListAll() ([]*models.RecurringSchedule, error)
+
+	// Update loads the schedule, passes a copy to fn and stores the result if
+	// fn returns nil. The read-modify-write happens atomically.
+	Update(userID, id string, fn func(schedule *models.RecurringSchedule) error) (*models.RecurringSchedule, error)
+
+	// Delete removes the user's schedule. Invoices it generated are kept.
+	Delete(userID, id string) error
+}
+
+// MemoryRecurringStore is a thread-safe in-memory RecurringStore.
+type MemoryRecurringStore struct {
+	mu        sync.RWMutex
+	schedules map[string]*models.RecurringSchedule // keyed by schedule ID
+	nextID    int
+}
+
+// NewMemoryRecurringStore creates an empty in-memory schedule store.
+func NewMemoryRecurringStore() *MemoryRecurringStore {
+	return &MemoryRecurringStore{
+		schedules: make(map[string]*models.RecurringSchedule),
+	}
+}
+
+// Create stores a new schedule for the user.
+func (s *MemoryRecurringStore) Create(userID string, schedule *models.RecurringSchedule) (*models.RecurringSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := time.Now().UTC()
+
+	stored := schedule.Clone()
+	stored.ID = fmt.Sprintf("rec_%d", s.nextID)
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	s.schedules[stored.ID] = stored
+	return stored.Clone(), nil
+}
+
+// Get returns the user's schedule with the given ID.
+func (s *MemoryRecurringStore) Get(userID, id string) (*models.RecurringSchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedule, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Clone(), nil
+}
+
+// List returns all of the user's schedules, newest first.
+func (s *MemoryRecurringStore) List(userID string) ([]*models.RecurringSchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.RecurringSchedule, 0)
+	for _, schedule := range s.schedules {
+		if schedule.UserID == userID {
+			result = append(result, schedule.Clone())
+		}
+	}
+	sortSchedules(result)
+	return result, nil
+}
+
+// ListAll returns every user's schedules.
+func (s *MemoryRecurringStore) ListAll() ([]*models.RecurringSchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.RecurringSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		result = append(result, schedule.Clone())
+	}
+	sortSchedules(result)
+	return result, nil
+}
+
+// Update atomically applies fn to a copy of the user's schedule and stores the result.
+func (s *MemoryRecurringStore) Update(userID, id string, fn func(schedule *models.RecurringSchedule) error) (*models.RecurringSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := existing.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	s.schedules[id] = updated
+	return updated.Clone(), nil
+}
+
+// Delete removes the user's schedule.
+func (s *MemoryRecurringStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.lookup(userID, id); err != nil {
+		return err
+	}
+	delete(s.schedules, id)
+	return nil
+}
+
+// lookup finds a schedule owned by userID. Callers must hold the lock.
+func (s *MemoryRecurringStore) lookup(userID, id string) (*models.RecurringSchedule, error) {
+	schedule, exists := s.schedules[id]
+	if !exists || schedule.UserID != userID {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+func sortSchedules(schedules []*models.RecurringSchedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].CreatedAt.Equal(schedules[j].CreatedAt) {
+			return schedules[i].ID > schedules[j].ID
+		}
+		return schedules[i].CreatedAt.After(schedules[j].CreatedAt)
+	})
+}
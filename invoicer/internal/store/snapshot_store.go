@@ -0,0 +1,78 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when an invoice has no frozen snapshot
+	// or belongs to another user.
+	ErrSnapshotNotFound = errors.New("invoice has not been issued")
+
+	// ErrSnapshotExists is returned when freezing an invoice a second time.
+	ErrSnapshotExists = errors.New("invoice is already frozen")
+)
+
+// SnapshotStore persists the snapshots of issued invoices. A snapshot cannot
+// be replaced, and is only removed together with a document whose creation
+// is rolled back.
+type SnapshotStore interface {
+	// Create stores the snapshot of an invoice that has none yet.
+	Create(snapshot *models.InvoiceSnapshot) error
+
+	// Get returns the snapshot of the user's invoice.
+	Get(userID, invoiceID string) (*models.InvoiceSnapshot, error)
+
+	// Delete removes the snapshot of the user's invoice, if it has one.
+	Delete(userID, invoiceID string) error
+}
+
+// MemorySnapshotStore is a thread-safe in-memory SnapshotStore.
+type MemorySnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]*models.InvoiceSnapshot // keyed by invoice ID
+}
+
+// NewMemorySnapshotStore creates an empty in-memory snapshot store.
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{
+		snapshots: make(map[string]*models.InvoiceSnapshot),
+	}
+}
+
+// Create stores a copy of the snapshot.
+func (s *MemorySnapshotStore) Create(snapshot *models.InvoiceSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.snapshots[snapshot.InvoiceID]; ok {
+		return ErrSnapshotExists
+	}
+	s.snapshots[snapshot.InvoiceID] = snapshot.Clone()
+	return nil
+}
+
+// Get returns a copy of the snapshot of the user's invoice.
+func (s *MemorySnapshotStore) Get(userID, invoiceID string) (*models.InvoiceSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.snapshots[invoiceID]
+	if !ok || snapshot.UserID != userID {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot.Clone(), nil
+}
+
+// Delete removes the snapshot of the user's invoice, if it has one.
+func (s *MemorySnapshotStore) Delete(userID, invoiceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if snapshot, ok := s.snapshots[invoiceID]; ok && snapshot.UserID == userID {
+		delete(s.snapshots, invoiceID)
+	}
+	return nil
+}
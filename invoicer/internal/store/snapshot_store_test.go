@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	forEachSnapshotStore(t, func(t *testing.T, s SnapshotStore) {
+
+		snapshot := &models.InvoiceSnapshot{InvoiceID: "inv_1", UserID: "user_1", Document: []byte(`{}`), PDF: []byte("%PDF")}
+		if err := s.Create(snapshot); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := s.Create(&models.InvoiceSnapshot{InvoiceID: "inv_1", UserID: "user_1"}); !errors.Is(err, ErrSnapshotExists) {
+			t.Errorf("expected ErrSnapshotExists when freezing twice, got %v", err)
+		}
+
+		snapshot.PDF[0] = 'X'
+		got, err := s.Get("user_1", "inv_1")
+		if err != nil || string(got.PDF) != "%PDF" {
+			t.Fatalf("expected the stored PDF to be unchanged, got %q (err %v)", got.PDF, err)
+		}
+		got.Document[0] = 'X'
+		if again, _ := s.Get("user_1", "inv_1"); string(again.Document) != `{}` {
+			t.Errorf("expected Get to return a copy, got %q", again.Document)
+		}
+
+		if _, err := s.Get("user_2", "inv_1"); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("expected ErrSnapshotNotFound for another user's invoice, got %v", err)
+		}
+		if _, err := s.Get("user_1", "inv_2"); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("expected ErrSnapshotNotFound for an invoice without a snapshot, got %v", err)
+		}
+
+		if err := s.Delete("user_2", "inv_1"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", "inv_1"); err != nil {
+			t.Errorf("expected another user's Delete to keep the snapshot, got %v", err)
+		}
+		if err := s.Delete("user_1", "inv_1"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := s.Get("user_1", "inv_1"); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("expected ErrSnapshotNotFound after Delete, got %v", err)
+		}
+		if err := s.Create(&models.InvoiceSnapshot{InvoiceID: "inv_1", UserID: "user_1"}); err != nil {
+			t.Errorf("expected a deleted snapshot to be replaceable, got %v", err)
+		}
+	})
+}
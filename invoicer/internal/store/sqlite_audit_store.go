@@ -0,0 +1,80 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+)
+
+// SQLiteAuditStore is an AuditStore backed by a database opened with OpenSQLite.
+// SQLiteInvoiceStore appends to the same table in the transactions of the
+// changes it records.
+type SQLiteAuditStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuditStore creates an audit store on db.
+func NewSQLiteAuditStore(db *sql.DB) *SQLiteAuditStore {
+	return &SQLiteAuditStore{db: db}
+}
+
+// Append stores a copy of the entry.
+func (s *SQLiteAuditStore) Append(entry *models.AuditEntry) (*models.AuditEntry, error) {
+	var stored *models.AuditEntry
+	err := inTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		stored, err = appendEntry(tx, entry)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// appendEntry inserts a copy of the entry in tx, assigning its ID.
+func appendEntry(tx *sql.Tx, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	seq, id, err := nextID(tx, "audit_entries", "aud")
+	if err != nil {
+		return nil, err
+	}
+	stored := entry.Clone()
+	stored.ID = id
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO audit_entries (seq, id, user_id, invoice_id, data) VALUES (?, ?, ?, ?, ?)`,
+		seq, stored.ID, stored.UserID, stored.InvoiceID, data); err != nil {
+		return nil, fmt.Errorf("failed to insert history entry: %w", err)
+	}
+	return stored, nil
+}
+
+// List returns the history of the user's invoice, oldest entry first.
+func (s *SQLiteAuditStore) List(userID, invoiceID string) ([]*models.AuditEntry, error) {
+	rows, err := s.db.Query(`SELECT data FROM audit_entries WHERE invoice_id = ? AND user_id = ? ORDER BY seq`, invoiceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.AuditEntry, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan history entry: %w", err)
+		}
+		var entry models.AuditEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode history entry: %w", err)
+		}
+		result = append(result, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+	"time"
+)
+
+// SQLiteBusinessProfileStore is a BusinessProfileStore backed by a database opened with OpenSQLite.
+type SQLiteBusinessProfileStore struct {
+	mu sync.Mutex // serialises read-modify-writes
+	db *sql.DB
+}
+
+// NewSQLiteBusinessProfileStore creates a business profile store on db.
+func NewSQLiteBusinessProfileStore(db *sql.DB) *SQLiteBusinessProfileStore {
+	return &SQLiteBusinessProfileStore{db: db}
+}
+
+// Create stores a new profile for the user.
+func (s *SQLiteBusinessProfileStore) Create(userID string, profile *models.BusinessProfile) (*models.BusinessProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq, id, err := nextID(s.db, "business_profiles", "biz")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := profile.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO business_profiles (seq, id, user_id, data) VALUES (?, ?, ?, ?)`,
+		seq, stored.ID, userID, data); err != nil {
+		return nil, fmt.Errorf("failed to insert profile: %w", err)
+	}
+	return stored, nil
+}
+
+// Get returns the user's profile with the given ID.
+func (s *SQLiteBusinessProfileStore) Get(userID, id string) (*models.BusinessProfile, error) {
+	return s.lookup(userID, id)
+}
+
+// List returns all of the user's profiles, sorted by name.
+func (s *SQLiteBusinessProfileStore) List(userID string) ([]*models.BusinessProfile, error) {
+	rows, err := s.db.Query(`SELECT data FROM business_profiles WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profiles: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.BusinessProfile, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		profile, err := decodeProfile(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query profiles: %w", err)
+	}
+	sortProfiles(result)
+	return result, nil
+}
+
+// Update replaces the user's profile.
+func (s *SQLiteBusinessProfileStore) Update(userID, id string, profile *models.BusinessProfile) (*models.BusinessProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := profile.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if _, err := s.db.Exec(`UPDATE business_profiles SET data = ? WHERE id = ?`, data, id); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes the user's profile.
+func (s *SQLiteBusinessProfileStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM business_profiles WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrBusinessProfileNotFound
+	}
+	return nil
+}
+
+// lookup loads a profile owned by userID.
+func (s *SQLiteBusinessProfileStore) lookup(userID, id string) (*models.BusinessProfile, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM business_profiles WHERE id = ? AND user_id = ?`, id, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBusinessProfileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profile: %w", err)
+	}
+	return decodeProfile(data)
+}
+
+// decodeProfile decodes a stored profile.
+func decodeProfile(data []byte) (*models.BusinessProfile, error) {
+	var profile models.BusinessProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLiteCatalogStore is a CatalogStore backed by a database opened with OpenSQLite.
+type SQLiteCatalogStore struct {
+	mu sync.Mutex // serialises read-modify-writes and SKU checks
+	db *sql.DB
+}
+
+// NewSQLiteCatalogStore creates a catalog store on db.
+func NewSQLiteCatalogStore(db *sql.DB) *SQLiteCatalogStore {
+	return &SQLiteCatalogStore{db: db}
+}
+
+// Create stores a new catalog item for the user.
+func (s *SQLiteCatalogStore) Create(userID string, item *models.CatalogItem) (*models.CatalogItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taken, err := s.skuTaken(userID, item.SKU, ""); err != nil || taken {
+		return nil, orErr(err, ErrDuplicateSKU)
+	}
+
+	seq, id, err := nextID(s.db, "catalog_items", "cat")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := item.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode catalog item: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO catalog_items (seq, id, user_id, sku, data) VALUES (?, ?, ?, ?, ?)`,
+		seq, stored.ID, userID, strings.ToLower(stored.SKU), data); err != nil {
+		return nil, fmt.Errorf("failed to insert catalog item: %w", err)
+	}
+	return stored, nil
+}
+
+// Get returns the user's catalog item with the given ID.
+func (s *SQLiteCatalogStore) Get(userID, id string) (*models.CatalogItem, error) {
+	return s.lookup(userID, id)
+}
+
+// List returns all of the user's catalog items, sorted by name.
+func (s *SQLiteCatalogStore) List(userID string) ([]*models.CatalogItem, error) {
+	rows, err := s.db.Query(`SELECT data FROM catalog_items WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query catalog items: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.CatalogItem, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan catalog item: %w", err)
+		}
+		item, err := decodeCatalogItem(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query catalog items: %w", err)
+	}
+	sortCatalog(result)
+	return result, nil
+}
+
+// Update replaces the user's catalog item.
+func (s *SQLiteCatalogStore) Update(userID, id string, item *models.CatalogItem) (*models.CatalogItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if taken, err := s.skuTaken(userID, item.SKU, id); err != nil || taken {
+		return nil, orErr(err, ErrDuplicateSKU)
+	}
+
+	updated := item.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode catalog item: %w", err)
+	}
+	if _, err := s.db.Exec(`UPDATE catalog_items SET sku = ?, data = ? WHERE id = ?`,
+		strings.ToLower(updated.SKU), data, id); err != nil {
+		return nil, fmt.Errorf("failed to update catalog item: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes the user's catalog item.
+func (s *SQLiteCatalogStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM catalog_items WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete catalog item: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrCatalogItemNotFound
+	}
+	return nil
+}
+
+// lookup loads a catalog item owned by userID.
+func (s *SQLiteCatalogStore) lookup(userID, id string) (*models.CatalogItem, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM catalog_items WHERE id = ? AND user_id = ?`, id, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCatalogItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query catalog item: %w", err)
+	}
+	return decodeCatalogItem(data)
+}
+
+// skuTaken reports whether another of the user's items (other than
+// excludeID) has the SKU, ignoring case. Callers must hold the lock.
+func (s *SQLiteCatalogStore) skuTaken(userID, sku, excludeID string) (bool, error) {
+	if sku == "" {
+		return false, nil
+	}
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM catalog_items WHERE user_id = ? AND sku = ? AND id <> ?`,
+		userID, strings.ToLower(sku), excludeID).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to query catalog SKUs: %w", err)
+	}
+	return n > 0, nil
+}
+
+// decodeCatalogItem decodes a stored catalog item.
+func decodeCatalogItem(data []byte) (*models.CatalogItem, error) {
+	var item models.CatalogItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog item: %w", err)
+	}
+	return &item, nil
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+	"time"
+)
+
+// SQLiteClientStore is a ClientStore backed by a database opened with OpenSQLite.
+type SQLiteClientStore struct {
+	mu sync.Mutex // serialises read-modify-writes
+	db *sql.DB
+}
+
+// NewSQLiteClientStore creates a client store on db.
+func NewSQLiteClientStore(db *sql.DB) *SQLiteClientStore {
+	return &SQLiteClientStore{db: db}
+}
+
+// Create stores a new client for the user.
+func (s *SQLiteClientStore) Create(userID string, client *models.Client) (*models.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq, id, err := nextID(s.db, "clients", "cli")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := client.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode client: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO clients (seq, id, user_id, data) VALUES (?, ?, ?, ?)`,
+		seq, stored.ID, userID, data); err != nil {
+		return nil, fmt.Errorf("failed to insert client: %w", err)
+	}
+	return stored, nil
+}
+
+// Get returns the user's client with the given ID.
+func (s *SQLiteClientStore) Get(userID, id string) (*models.Client, error) {
+	return s.lookup(userID, id)
+}
+
+// List returns all of the user's clients, sorted by name.
+func (s *SQLiteClientStore) List(userID string) ([]*models.Client, error) {
+	rows, err := s.db.Query(`SELECT data FROM clients WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.Client, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan client: %w", err)
+		}
+		client, err := decodeClient(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+	sortClients(result)
+	return result, nil
+}
+
+// Update replaces the user's client.
+func (s *SQLiteClientStore) Update(userID, id string, client *models.Client) (*models.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := client.Clone()
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode client: %w", err)
+	}
+	if _, err := s.db.Exec(`UPDATE clients SET data = ? WHERE id = ?`, data, id); err != nil {
+		return nil, fmt.Errorf("failed to update client: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes the user's client.
+func (s *SQLiteClientStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM clients WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrClientNotFound
+	}
+	return nil
+}
+
+// lookup loads a client owned by userID.
+func (s *SQLiteClientStore) lookup(userID, id string) (*models.Client, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM clients WHERE id = ? AND user_id = ?`, id, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrClientNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client: %w", err)
+	}
+	return decodeClient(data)
+}
+
+// decodeClient decodes a stored client.
+func decodeClient(data []byte) (*models.Client, error) {
+	var client models.Client
+	if err := json.Unmarshal(data, &client); err != nil {
+		return nil, fmt.Errorf("failed to decode client: %w", err)
+	}
+	return &client, nil
+}
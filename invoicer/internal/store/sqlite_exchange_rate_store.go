@@ -0,0 +1,59 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+)
+
+// SQLiteExchangeRateStore is an ExchangeRateStore backed by a database opened
+// with OpenSQLite.
+type SQLiteExchangeRateStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteExchangeRateStore creates an exchange rate store on db.
+func NewSQLiteExchangeRateStore(db *sql.DB) *SQLiteExchangeRateStore {
+	return &SQLiteExchangeRateStore{db: db}
+}
+
+// Put stores the rates, replacing any for the same date and base.
+func (s *SQLiteExchangeRateStore) Put(rates *models.ExchangeRates) error {
+	data, err := json.Marshal(rates)
+	if err != nil {
+		return fmt.Errorf("failed to encode exchange rates: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO exchange_rates (date, base, data) VALUES (?, ?, ?)
+		ON CONFLICT (date, base) DO UPDATE SET data = excluded.data`,
+		rates.Date.String(), rates.Base, data); err != nil {
+		return fmt.Errorf("failed to store exchange rates: %w", err)
+	}
+	return nil
+}
+
+// List returns every day's rates, oldest first.
+func (s *SQLiteExchangeRateStore) List() ([]*models.ExchangeRates, error) {
+	rows, err := s.db.Query(`SELECT data FROM exchange_rates ORDER BY date, base`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.ExchangeRates, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rates: %w", err)
+		}
+		var rates models.ExchangeRates
+		if err := json.Unmarshal(data, &rates); err != nil {
+			return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+		}
+		result = append(result, &rates)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+	"time"
+)
+
+// SQLiteInvoiceStore is an InvoiceStore backed by a database opened with
+// OpenSQLite. Journal entries are appended to the audit_entries table read by
+// SQLiteAuditStore, and promo code uses are counted in the promo_codes table
+// of SQLitePromoCodeStore, in the transaction of the change they belong to.
+type SQLiteInvoiceStore struct {
+	mu sync.Mutex // serialises read-modify-writes
+	db *sql.DB
+}
+
+// NewSQLiteInvoiceStore creates an invoice store on db.
+func NewSQLiteInvoiceStore(db *sql.DB) *SQLiteInvoiceStore {
+	return &SQLiteInvoiceStore{db: db}
+}
+
+// Create stores a new invoice for the user.
+func (s *SQLiteInvoiceStore) Create(userID string, invoice *models.Invoice, journal Journal) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taken, err := s.numberTaken(userID, invoice.InvoiceNumber, ""); err != nil || taken {
+		return nil, orErr(err, ErrDuplicateNumber)
+	}
+	if invoice.RecurringID != "" {
+		var n int
+		err := s.db.QueryRow(`SELECT COUNT(*) FROM invoices WHERE user_id = ? AND recurring_id = ? AND recurring_period = ?`,
+			userID, invoice.RecurringID, invoice.RecurringPeriod).Scan(&n)
+		if err != nil || n > 0 {
+			return nil, orErr(err, ErrDuplicateOccurrence)
+		}
+	}
+
+	seq, id, err := nextID(s.db, "invoices", "inv")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := invoice.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	var previous *models.Invoice
+	if stored.PreviousRevisionID != "" {
+		if previous, err = s.lookup(userID, stored.PreviousRevisionID); err != nil && !errors.Is(err, ErrInvoiceNotFound) {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode invoice: %w", err)
+	}
+	err = s.write(journal, nil, stored, revisedPromoCodes(previous), func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO invoices (seq, id, user_id, number, recurring_id, recurring_period, data) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			seq, stored.ID, userID, stored.InvoiceNumber, stored.RecurringID, stored.RecurringPeriod, data)
+		if err != nil {
+			return fmt.Errorf("failed to insert invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// Get returns the user's invoice with the given ID.
+func (s *SQLiteInvoiceStore) Get(userID, id string) (*models.Invoice, error) {
+	return s.lookup(userID, id)
+}
+
+// List returns all of the user's invoices, newest first.
+func (s *SQLiteInvoiceStore) List(userID string) ([]*models.Invoice, error) {
+	return s.query(`SELECT data FROM invoices WHERE user_id = ?`, userID)
+}
+
+// ListAll returns every user's invoices, newest first.
+func (s *SQLiteInvoiceStore) ListAll() ([]*models.Invoice, error) {
+	return s.query(`SELECT data FROM invoices`)
+}
+
+// Update atomically applies fn to a copy of the user's invoice and stores the result.
+// Identity fields (ID, owner, creation time) cannot be changed by fn.
+func (s *SQLiteInvoiceStore) Update(userID, id string, fn func(invoice *models.Invoice) error, journal Journal) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := existing.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+
+	if taken, err := s.numberTaken(userID, updated.InvoiceNumber, id); err != nil || taken {
+		return nil, orErr(err, ErrDuplicateNumber)
+	}
+
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode invoice: %w", err)
+	}
+	err = s.write(journal, existing, updated, existing.RedeemedPromoCodes, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE invoices SET number = ?, recurring_id = ?, recurring_period = ?, data = ? WHERE id = ?`,
+			updated.InvoiceNumber, updated.RecurringID, updated.RecurringPeriod, data, id)
+		if err != nil {
+			return fmt.Errorf("failed to update invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes the user's invoice with the given ID once check (if any) passes.
+func (s *SQLiteInvoiceStore) Delete(userID, id string, check func(invoice *models.Invoice) error, journal Journal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(existing); err != nil {
+			return err
+		}
+	}
+	return s.write(journal, existing, nil, nil, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM invoices WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete invoice: %w", err)
+		}
+		return nil
+	})
+}
+
+// write runs change, counts the promo codes after newly uses and appends the
+// journal's entry for it in one transaction. The entry is built first: the
+// journal must not run inside the transaction, which holds the database's
+// only connection.
+func (s *SQLiteInvoiceStore) write(journal Journal, before, after *models.Invoice, counted []string, change func(tx *sql.Tx) error) error {
+	entry, err := journal.entry(before, after)
+	if err != nil {
+		return err
+	}
+	return inTx(s.db, func(tx *sql.Tx) error {
+		if err := change(tx); err != nil {
+			return err
+		}
+		if after != nil {
+			for _, code := range newPromoCodes(after, counted) {
+				if _, err := redeemPromoCode(tx, after.UserID, code, usableToday); err != nil {
+					return err
+				}
+			}
+		}
+		if entry != nil {
+			if _, err := appendEntry(tx, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lookup loads an invoice owned by userID.
+func (s *SQLiteInvoiceStore) lookup(userID, id string) (*models.Invoice, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM invoices WHERE id = ? AND user_id = ?`, id, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoice: %w", err)
+	}
+	return decodeInvoice(data)
+}
+
+// query loads the invoices selected by query, newest first.
+func (s *SQLiteInvoiceStore) query(query string, args ...any) ([]*models.Invoice, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.Invoice, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoice, err := decodeInvoice(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	sortInvoices(result)
+	return result, nil
+}
+
+// numberTaken reports whether another of the user's invoices (other than
+// excludeID) already uses number.
+func (s *SQLiteInvoiceStore) numberTaken(userID, number, excludeID string) (bool, error) {
+	if number == "" {
+		return false, nil
+	}
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM invoices WHERE user_id = ? AND number = ? AND id <> ?`,
+		userID, number, excludeID).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to query invoice numbers: %w", err)
+	}
+	return n > 0, nil
+}
+
+// decodeInvoice decodes a stored invoice and stamps its amounts with its currency.
+func decodeInvoice(data []byte) (*models.Invoice, error) {
+	var invoice models.Invoice
+	if err := json.Unmarshal(data, &invoice); err != nil {
+		return nil, fmt.Errorf("failed to decode invoice: %w", err)
+	}
+	invoice.StampCurrency()
+	return &invoice, nil
+}
+
+// orErr returns err if it is not nil and otherwise fallback.
+func orErr(err, fallback error) error {
+	if err != nil {
+		return err
+	}
+	return fallback
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLitePromoCodeStore is a PromoCodeStore backed by a database opened with
+// OpenSQLite. SQLiteInvoiceStore counts the uses of the codes in the same
+// database, in the transaction that saves the invoice.
+type SQLitePromoCodeStore struct {
+	mu sync.Mutex // serialises code checks
+	db *sql.DB
+}
+
+// NewSQLitePromoCodeStore creates a promo code store on db.
+func NewSQLitePromoCodeStore(db *sql.DB) *SQLitePromoCodeStore {
+	return &SQLitePromoCodeStore{db: db}
+}
+
+// Create stores a new promo code for the user with no uses.
+func (s *SQLitePromoCodeStore) Create(userID string, code *models.PromoCode) (*models.PromoCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taken, err := s.codeTaken(userID, code.Code, ""); err != nil || taken {
+		return nil, orErr(err, ErrDuplicatePromoCode)
+	}
+
+	seq, id, err := nextID(s.db, "promo_codes", "promo")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := code.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.Uses = 0
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode promo code: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO promo_codes (seq, id, user_id, code, data) VALUES (?, ?, ?, ?, ?)`,
+		seq, stored.ID, userID, strings.ToUpper(stored.Code), data); err != nil {
+		return nil, fmt.Errorf("failed to insert promo code: %w", err)
+	}
+	return stored, nil
+}
+
+// Get returns the user's promo code with the given ID.
+func (s *SQLitePromoCodeStore) Get(userID, id string) (*models.PromoCode, error) {
+	return lookupPromoCode(s.db, `SELECT data FROM promo_codes WHERE id = ? AND user_id = ?`, id, userID)
+}
+
+// GetByCode returns the user's promo code with the given code, ignoring case.
+func (s *SQLitePromoCodeStore) GetByCode(userID, code string) (*models.PromoCode, error) {
+	return lookupPromoCode(s.db, `SELECT data FROM promo_codes WHERE user_id = ? AND code = ?`, userID, strings.ToUpper(code))
+}
+
+// List returns all of the user's promo codes, sorted by code.
+func (s *SQLitePromoCodeStore) List(userID string) ([]*models.PromoCode, error) {
+	rows, err := s.db.Query(`SELECT data FROM promo_codes WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promo codes: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.PromoCode, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan promo code: %w", err)
+		}
+		code, err := decodePromoCode(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query promo codes: %w", err)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result, nil
+}
+
+// Update replaces the user's promo code, keeping its use count. The code is
+// read and written in one transaction, so a use counted by an invoice saved
+// in the meantime is not lost.
+func (s *SQLitePromoCodeStore) Update(userID, id string, code *models.PromoCode) (*models.PromoCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taken, err := s.codeTaken(userID, code.Code, id); err != nil || taken {
+		return nil, orErr(err, ErrDuplicatePromoCode)
+	}
+
+	var updated *models.PromoCode
+	err := inTx(s.db, func(tx *sql.Tx) error {
+		existing, err := lookupPromoCode(tx, `SELECT data FROM promo_codes WHERE id = ? AND user_id = ?`, id, userID)
+		if err != nil {
+			return err
+		}
+
+		updated = code.Clone()
+		updated.ID = existing.ID
+		updated.UserID = existing.UserID
+		updated.CreatedAt = existing.CreatedAt
+		updated.UpdatedAt = time.Now().UTC()
+		updated.Uses = existing.Uses
+		return savePromoCode(tx, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes the user's promo code.
+func (s *SQLitePromoCodeStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM promo_codes WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete promo code: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrPromoCodeNotFound
+	}
+	return nil
+}
+
+// Redeem checks the user's promo code and counts one use of it.
+func (s *SQLitePromoCodeStore) Redeem(userID, code string, check func(*models.PromoCode) error) (*models.PromoCode, error) {
+	var redeemed *models.PromoCode
+	err := inTx(s.db, func(tx *sql.Tx) error {
+		var err error
+		redeemed, err = redeemPromoCode(tx, userID, code, check)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redeemed, nil
+}
+
+// Release gives back one use of the user's promo code.
+func (s *SQLitePromoCodeStore) Release(userID, code string) error {
+	return inTx(s.db, func(tx *sql.Tx) error {
+		found, err := lookupPromoCode(tx, `SELECT data FROM promo_codes WHERE user_id = ? AND code = ?`, userID, strings.ToUpper(code))
+		if err != nil {
+			return err
+		}
+		if found.Uses == 0 {
+			return nil
+		}
+		found.Uses--
+		return savePromoCode(tx, found)
+	})
+}
+
+// redeemPromoCode calls check with the user's promo code and, if it returns
+// nil, counts one use of the code in tx. It returns the code as redeemed.
+func redeemPromoCode(tx *sql.Tx, userID, code string, check func(*models.PromoCode) error) (*models.PromoCode, error) {
+	found, err := lookupPromoCode(tx, `SELECT data FROM promo_codes WHERE user_id = ? AND code = ?`, userID, strings.ToUpper(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := check(found.Clone()); err != nil {
+		return nil, err
+	}
+	found.Uses++
+	if err := savePromoCode(tx, found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// savePromoCode writes an existing promo code in tx.
+func savePromoCode(tx *sql.Tx, code *models.PromoCode) error {
+	data, err := json.Marshal(code)
+	if err != nil {
+		return fmt.Errorf("failed to encode promo code: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE promo_codes SET code = ?, data = ? WHERE id = ?`,
+		strings.ToUpper(code.Code), data, code.ID); err != nil {
+		return fmt.Errorf("failed to update promo code: %w", err)
+	}
+	return nil
+}
+
+// lookupPromoCode loads the promo code selected by query.
+func lookupPromoCode(db queryRower, query string, args ...any) (*models.PromoCode, error) {
+	var data []byte
+	err := db.QueryRow(query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promo code: %w", err)
+	}
+	return decodePromoCode(data)
+}
+
+// codeTaken reports whether another of the user's promo codes (other than
+// excludeID) has the code, ignoring case. Callers must hold the lock.
+func (s *SQLitePromoCodeStore) codeTaken(userID, code, excludeID string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM promo_codes WHERE user_id = ? AND code = ? AND id <> ?`,
+		userID, strings.ToUpper(code), excludeID).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to query promo codes: %w", err)
+	}
+	return n > 0, nil
+}
+
+// decodePromoCode decodes a stored promo code.
+func decodePromoCode(data []byte) (*models.PromoCode, error) {
+	var code models.PromoCode
+	if err := json.Unmarshal(data, &code); err != nil {
+		return nil, fmt.Errorf("failed to decode promo code: %w", err)
+	}
+	return &code, nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"sync"
+	"time"
+)
+
+// SQLiteRecurringStore is a RecurringStore backed by a database opened with OpenSQLite.
+type SQLiteRecurringStore struct {
+	mu sync.Mutex // serialises read-modify-writes
+	db *sql.DB
+}
+
+// NewSQLiteRecurringStore creates a schedule store on db.
+func NewSQLiteRecurringStore(db *sql.DB) *SQLiteRecurringStore {
+	return &SQLiteRecurringStore{db: db}
+}
+
+// Create stores a new schedule for the user.
+func (s *SQLiteRecurringStore) Create(userID string, schedule *models.RecurringSchedule) (*models.RecurringSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq, id, err := nextID(s.db, "recurring_schedules", "rec")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	stored := schedule.Clone()
+	stored.ID = id
+	stored.UserID = userID
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schedule: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO recurring_schedules (seq, id, user_id, data) VALUES (?, ?, ?, ?)`,
+		seq, stored.ID, userID, data); err != nil {
+		return nil, fmt.Errorf("failed to insert schedule: %w", err)
+	}
+	return stored, nil
+}
+
+// Get returns the user's schedule with the given ID.
+func (s *SQLiteRecurringStore) Get(userID, id string) (*models.RecurringSchedule, error) {
+	return s.lookup(userID, id)
+}
+
+// List returns all of the user's schedules, newest first.
+func (s *SQLiteRecurringStore) List(userID string) ([]*models.RecurringSchedule, error) {
+	return s.query(`SELECT data FROM recurring_schedules WHERE user_id = ?`, userID)
+}
+
+// ListAll returns every user's schedules.
+func (s *SQLiteRecurringStore) ListAll() ([]*models.RecurringSchedule, error) {
+	return s.query(`SELECT data FROM recurring_schedules`)
+}
+
+// Update atomically applies fn to a copy of the user's schedule and stores the result.
+func (s *SQLiteRecurringStore) Update(userID, id string, fn func(schedule *models.RecurringSchedule) error) (*models.RecurringSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.lookup(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := existing.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+
+	updated.ID = existing.ID
+	updated.UserID = existing.UserID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schedule: %w", err)
+	}
+	if _, err := s.db.Exec(`UPDATE recurring_schedules SET data = ? WHERE id = ?`, data, id); err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes the user's schedule.
+func (s *SQLiteRecurringStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.db.Exec(`DELETE FROM recurring_schedules WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// lookup loads a schedule owned by userID.
+func (s *SQLiteRecurringStore) lookup(userID, id string) (*models.RecurringSchedule, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM recurring_schedules WHERE id = ? AND user_id = ?`, id, userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedule: %w", err)
+	}
+	return decodeSchedule(data)
+}
+
+// query loads the schedules selected by query, newest first.
+func (s *SQLiteRecurringStore) query(query string, args ...any) ([]*models.RecurringSchedule, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.RecurringSchedule, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedule, err := decodeSchedule(data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	sortSchedules(result)
+	return result, nil
+}
+
+// decodeSchedule decodes a stored schedule and stamps its template's amounts.
+func decodeSchedule(data []byte) (*models.RecurringSchedule, error) {
+	var schedule models.RecurringSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to decode schedule: %w", err)
+	}
+	schedule.Template.StampCurrency()
+	return &schedule, nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/models"
+	"strings"
+	"time"
+)
+
+// SQLiteSnapshotStore is a SnapshotStore backed by a database opened with OpenSQLite.
+type SQLiteSnapshotStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteSnapshotStore creates a snapshot store on db.
+func NewSQLiteSnapshotStore(db *sql.DB) *SQLiteSnapshotStore {
+	return &SQLiteSnapshotStore{db: db}
+}
+
+// Create stores the snapshot of an invoice that has none yet.
+func (s *SQLiteSnapshotStore) Create(snapshot *models.InvoiceSnapshot) error {
+	_, err := s.db.Exec(`INSERT INTO invoice_snapshots (invoice_id, user_id, frozen_at, document, document_sha256, pdf, pdf_sha256) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.InvoiceID, snapshot.UserID, snapshot.FrozenAt.UTC().Format(time.RFC3339Nano),
+		nonNil(snapshot.Document), snapshot.DocumentSHA256, nonNil(snapshot.PDF), snapshot.PDFSHA256)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return ErrSnapshotExists
+		}
+		return fmt.Errorf("failed to insert snapshot: %w", err)
+	}
+	return nil
+}
+
+// Get returns the snapshot of the user's invoice.
+func (s *SQLiteSnapshotStore) Get(userID, invoiceID string) (*models.InvoiceSnapshot, error) {
+	snapshot := models.InvoiceSnapshot{InvoiceID: invoiceID, UserID: userID}
+	var frozenAt string
+	err := s.db.QueryRow(`SELECT frozen_at, document, document_sha256, pdf, pdf_sha256 FROM invoice_snapshots WHERE invoice_id = ? AND user_id = ?`,
+		invoiceID, userID).Scan(&frozenAt, &snapshot.Document, &snapshot.DocumentSHA256, &snapshot.PDF, &snapshot.PDFSHA256)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshot: %w", err)
+	}
+	if snapshot.FrozenAt, err = time.Parse(time.RFC3339Nano, frozenAt); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot time: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Delete removes the snapshot of the user's invoice, if it has one.
+func (s *SQLiteSnapshotStore) Delete(userID, invoiceID string) error {
+	if _, err := s.db.Exec(`DELETE FROM invoice_snapshots WHERE invoice_id = ? AND user_id = ?`, invoiceID, userID); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// nonNil returns b, or an empty slice if b is nil, which would be stored as NULL.
+func nonNil(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// migrations are applied in order by OpenSQLite. Each entry is run exactly
+// once; never edit an entry that has shipped — append a new one instead.
+//
+// Records are stored as their JSON encoding in a data column. The other
+// columns exist to be looked up and to enforce uniqueness.
+var migrations = []string{
+	// 1: invoices
+	`CREATE TABLE invoices (
+		seq              INTEGER PRIMARY KEY AUTOINCREMENT,
+		id               TEXT NOT NULL UNIQUE,
+		user_id          TEXT NOT NULL,
+		number           TEXT NOT NULL DEFAULT '',
+		recurring_id     TEXT NOT NULL DEFAULT '',
+		recurring_period TEXT NOT NULL DEFAULT '',
+		data             TEXT NOT NULL
+	);
+	CREATE INDEX invoices_user ON invoices (user_id);
+	CREATE UNIQUE INDEX invoices_number ON invoices (user_id, number) WHERE number <> '';
+	CREATE UNIQUE INDEX invoices_occurrence ON invoices (user_id, recurring_id, recurring_period) WHERE recurring_id <> ''`,
+
+	// 2: recurring schedules
+	`CREATE TABLE recurring_schedules (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		id      TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		data    TEXT NOT NULL
+	)`,
+
+	// 3: numbering schemes and counters, used by numbering.SQLiteStore
+	`CREATE TABLE number_schemes (
+		user_id TEXT NOT NULL,
+		scope   TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		reset   TEXT NOT NULL,
+		PRIMARY KEY (user_id, scope)
+	);
+	CREATE TABLE number_counters (
+		user_id TEXT NOT NULL,
+		scope   TEXT NOT NULL,
+		period  TEXT NOT NULL,
+		value   INTEGER NOT NULL,
+		PRIMARY KEY (user_id, scope, period)
+	)`,
+
+	// 4: invoice snapshots
+	`CREATE TABLE invoice_snapshots (
+		invoice_id      TEXT PRIMARY KEY,
+		user_id         TEXT NOT NULL,
+		frozen_at       TEXT NOT NULL,
+		document        BLOB NOT NULL,
+		document_sha256 TEXT NOT NULL,
+		pdf             BLOB NOT NULL,
+		pdf_sha256      TEXT NOT NULL
+	)`,
+
+	// 5: audit history
+	`CREATE TABLE audit_entries (
+		seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+		id         TEXT NOT NULL UNIQUE,
+		user_id    TEXT NOT NULL,
+		invoice_id TEXT NOT NULL,
+		data       TEXT NOT NULL
+	);
+	CREATE INDEX audit_entries_invoice ON audit_entries (invoice_id)`,
+
+	// 6: clients
+	`CREATE TABLE clients (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		id      TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		data    TEXT NOT NULL
+	);
+	CREATE INDEX clients_user ON clients (user_id)`,
+
+	// 7: business profiles
+	`CREATE TABLE business_profiles (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		id      TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		data    TEXT NOT NULL
+	);
+	CREATE INDEX business_profiles_user ON business_profiles (user_id)`,
+
+	// 8: catalog items; sku is lowercased, as SKUs are unique ignoring case
+	`CREATE TABLE catalog_items (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		id      TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		sku     TEXT NOT NULL DEFAULT '',
+		data    TEXT NOT NULL
+	);
+	CREATE INDEX catalog_items_user ON catalog_items (user_id);
+	CREATE UNIQUE INDEX catalog_items_sku ON catalog_items (user_id, sku) WHERE sku <> ''`,
+
+	// 9: promo codes; code is uppercased, as codes are unique ignoring case
+	`CREATE TABLE promo_codes (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		id      TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		code    TEXT NOT NULL,
+		data    TEXT NOT NULL
+	);
+	CREATE INDEX promo_codes_user ON promo_codes (user_id);
+	CREATE UNIQUE INDEX promo_codes_code ON promo_codes (user_id, code)`,
+
+	// 10: exchange rates, shared by all users; date is YYYY-MM-DD, so it sorts
+	`CREATE TABLE exchange_rates (
+		date TEXT NOT NULL,
+		base TEXT NOT NULL,
+		data TEXT NOT NULL,
+		PRIMARY KEY (date, base)
+	)`,
+}
+
+// OpenSQLite opens (or creates) the SQLite database at path and applies any
+// pending schema migrations. The returned database is shared by the SQLite
+// stores of this package and numbering.SQLiteStore; the caller closes it.
+//
+// The migrations are tracked in their own table, so the database file can be
+// shared with the user store.
+func OpenSQLite(path string) (*sql.DB, error) {
+	// Another connection (such as the user store's) may be writing to the
+	// same file; wait for it instead of failing with "database is locked".
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialising access through one
+	// connection avoids "database is locked" errors under concurrent requests.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrate applies pending migrations, recording each applied version in store_migrations.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS store_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create store_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM store_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO store_migrations (version, applied_at) VALUES (?, ?)`,
+			version, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// nextID returns the ID the next row inserted into table will get: prefix
+// followed by the table's next sequence number. Sequence numbers are never
+// reused, even after a delete. Callers must hold their store's lock, or run
+// in the transaction that inserts the row, until the row is inserted.
+func nextID(db queryRower, table, prefix string) (int64, string, error) {
+	var seq int64
+	err := db.QueryRow(`SELECT seq FROM sqlite_sequence WHERE name = ?`, table).Scan(&seq)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("failed to allocate %s ID: %w", prefix, err)
+	}
+	seq++
+	return seq, fmt.Sprintf("%s_%d", prefix, seq), nil
+}
+
+// inTx runs fn in a transaction, committing it if fn returns nil. fn must only
+// use tx: the database has a single connection, which tx holds.
+func inTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"invoice-generator/invoicer/internal/money"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB opens a fresh SQLite database that is closed when the test ends.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// forEachInvoiceStore runs fn against every InvoiceStore backend.
+func forEachInvoiceStore(t *testing.T, fn func(t *testing.T, s InvoiceStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryInvoiceStore(nil, nil)) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteInvoiceStore(openTestDB(t))) })
+}
+
+// forEachSnapshotStore runs fn against every SnapshotStore backend.
+func forEachSnapshotStore(t *testing.T, fn func(t *testing.T, s SnapshotStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemorySnapshotStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteSnapshotStore(openTestDB(t))) })
+}
+
+// forEachAuditStore runs fn against every AuditStore backend.
+func forEachAuditStore(t *testing.T, fn func(t *testing.T, s AuditStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryAuditStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteAuditStore(openTestDB(t))) })
+}
+
+// forEachClientStore runs fn against every ClientStore backend.
+func forEachClientStore(t *testing.T, fn func(t *testing.T, s ClientStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryClientStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteClientStore(openTestDB(t))) })
+}
+
+// forEachBusinessProfileStore runs fn against every BusinessProfileStore backend.
+func forEachBusinessProfileStore(t *testing.T, fn func(t *testing.T, s BusinessProfileStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryBusinessProfileStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteBusinessProfileStore(openTestDB(t))) })
+}
+
+// forEachCatalogStore runs fn against every CatalogStore backend.
+func forEachCatalogStore(t *testing.T, fn func(t *testing.T, s CatalogStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryCatalogStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteCatalogStore(openTestDB(t))) })
+}
+
+// forEachPromoCodeStore runs fn against every PromoCodeStore backend.
+func forEachPromoCodeStore(t *testing.T, fn func(t *testing.T, s PromoCodeStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryPromoCodeStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLitePromoCodeStore(openTestDB(t))) })
+}
+
+// forEachExchangeRateStore runs fn against every ExchangeRateStore backend.
+func forEachExchangeRateStore(t *testing.T, fn func(t *testing.T, s ExchangeRateStore)) {
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryExchangeRateStore()) })
+	t.Run("sqlite", func(t *testing.T) { fn(t, NewSQLiteExchangeRateStore(openTestDB(t))) })
+}
+
+func TestInvoiceStore_DuplicateOccurrence(t *testing.T) {
+	forEachInvoiceStore(t, func(t *testing.T, s InvoiceStore) {
+		generated := newTestInvoice("INV-001")
+		generated.RecurringID, generated.RecurringPeriod = "rec_1", "2026-03-01"
+		if _, err := s.Create("user_1", generated, nil); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		again := newTestInvoice("INV-002")
+		again.RecurringID, again.RecurringPeriod = "rec_1", "2026-03-01"
+		if _, err := s.Create("user_1", again, nil); !errors.Is(err, ErrDuplicateOccurrence) {
+			t.Errorf("expected ErrDuplicateOccurrence, got %v", err)
+		}
+
+		again.RecurringPeriod = "2026-04-01"
+		if _, err := s.Create("user_1", again, nil); err != nil {
+			t.Errorf("expected the next period to be accepted, got %v", err)
+		}
+	})
+}
+
+func TestSQLiteStores_PersistAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "persist.db")
+
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	created, err := NewSQLiteInvoiceStore(db).Create("user_1", newTestInvoice("INV-001"), nil)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	NewSQLiteInvoiceStore(db).Delete("user_1", created.ID, nil, nil)
+	kept, _ := NewSQLiteInvoiceStore(db).Create("user_1", newTestInvoice("INV-002"), nil)
+	schedule, _ := NewSQLiteRecurringStore(db).Create("user_1", &models.RecurringSchedule{Name: "Retainer", Template: *newTestInvoice("")})
+	NewSQLiteAuditStore(db).Append(&models.AuditEntry{UserID: "user_1", InvoiceID: kept.ID, Action: models.AuditCreated})
+	NewSQLiteSnapshotStore(db).Create(&models.InvoiceSnapshot{InvoiceID: kept.ID, UserID: "user_1", Document: []byte(`{}`), PDF: []byte("%PDF")})
+	client, _ := NewSQLiteClientStore(db).Create("user_1", &models.Client{Name: "Globex"})
+	profile, _ := NewSQLiteBusinessProfileStore(db).Create("user_1", &models.BusinessProfile{Name: "Acme",
+		LateFee: &models.LateFeePolicy{Kind: models.LateFeeFlat, Amount: money.New(2500, "USD"), Currency: "USD"}})
+	item, _ := NewSQLiteCatalogStore(db).Create("user_1", &models.CatalogItem{Name: "Consulting", SKU: "CONS-1",
+		Rate: money.New(12050, "EUR"), Currency: "EUR"})
+	promo, _ := NewSQLitePromoCodeStore(db).Create("user_1", &models.PromoCode{Code: "SPRING10", MaxUses: 5})
+	NewSQLitePromoCodeStore(db).Redeem("user_1", "SPRING10", func(*models.PromoCode) error { return nil })
+	NewSQLiteExchangeRateStore(db).Put(&models.ExchangeRates{Date: civil.MustParse("2026-03-02"), Base: "EUR", Rates: map[string]float64{"INR": 90.5}})
+	db.Close()
+
+	// Reopening runs migrations again; they must be a no-op on an up-to-date schema
+	db, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer db.Close()
+
+	invoices := NewSQLiteInvoiceStore(db)
+	found, err := invoices.Get("user_1", kept.ID)
+	if err != nil {
+		t.Fatalf("Get after reopen failed: %v", err)
+	}
+	if found.Total.String() != "200.00" || found.Total.Currency() != "USD" || !found.CreatedAt.Equal(kept.CreatedAt) {
+		t.Errorf("expected the stored invoice back, got total %s %s created %v", found.Total, found.Total.Currency(), found.CreatedAt)
+	}
+	next, _ := invoices.Create("user_1", newTestInvoice("INV-003"), nil)
+	if next.ID == created.ID || next.ID == kept.ID {
+		t.Errorf("expected a fresh ID after reopen, got reused %q", next.ID)
+	}
+	if _, err := invoices.Create("user_1", newTestInvoice("INV-002"), nil); !errors.Is(err, ErrDuplicateNumber) {
+		t.Errorf("expected ErrDuplicateNumber after reopen, got %v", err)
+	}
+
+	if got, err := NewSQLiteRecurringStore(db).Get("user_1", schedule.ID); err != nil || got.Name != "Retainer" {
+		t.Errorf("expected the schedule back, got %+v (err %v)", got, err)
+	}
+	if history, _ := NewSQLiteAuditStore(db).List("user_1", kept.ID); len(history) != 1 {
+		t.Errorf("expected 1 history entry, got %d", len(history))
+	}
+	if snapshot, err := NewSQLiteSnapshotStore(db).Get("user_1", kept.ID); err != nil || string(snapshot.PDF) != "%PDF" {
+		t.Errorf("expected the snapshot back, got err %v", err)
+	}
+	if got, err := NewSQLiteClientStore(db).Get("user_1", client.ID); err != nil || got.Name != "Globex" {
+		t.Errorf("expected the client back, got %+v (err %v)", got, err)
+	}
+	got, err := NewSQLiteBusinessProfileStore(db).Get("user_1", profile.ID)
+	if err != nil || got.LateFee == nil || got.LateFee.Amount.WithCurrency(got.LateFee.Currency) != money.New(2500, "USD") {
+		t.Errorf("expected the profile and its late fee policy back, got %+v (err %v)", got, err)
+	}
+	catalog := NewSQLiteCatalogStore(db)
+	if got, err := catalog.Get("user_1", item.ID); err != nil || got.Rate.WithCurrency(got.Currency) != money.New(12050, "EUR") {
+		t.Errorf("expected the catalog item and its rate back, got %+v (err %v)", got, err)
+	}
+	if _, err := catalog.Create("user_1", &models.CatalogItem{Name: "Other", SKU: "cons-1"}); !errors.Is(err, ErrDuplicateSKU) {
+		t.Errorf("expected ErrDuplicateSKU after reopen, got %v", err)
+	}
+	promoCodes := NewSQLitePromoCodeStore(db)
+	if got, err := promoCodes.Get("user_1", promo.ID); err != nil || got.MaxUses != 5 || got.Uses != 1 {
+		t.Errorf("expected the promo code and its use back, got %+v (err %v)", got, err)
+	}
+	if _, err := promoCodes.Create("user_1", &models.PromoCode{Code: "spring10"}); !errors.Is(err, ErrDuplicatePromoCode) {
+		t.Errorf("expected ErrDuplicatePromoCode after reopen, got %v", err)
+	}
+	if rates, _ := NewSQLiteExchangeRateStore(db).List(); len(rates) != 1 || rates[0].Date.String() != "2026-03-02" || rates[0].Rates["INR"] != 90.5 {
+		t.Errorf("expected the exchange rates back, got %+v", rates)
+	}
+}
@@ -0,0 +1,131 @@
+package terms
+
+import (
+	"errors"
+	"fmt"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Codes for the fixed terms. Net terms are written net_<days> (e.g. net_30)
+// and early-payment discounts <percent>/<days>_net_<days> (e.g. 2/10_net_30).
+const (
+	DueOnReceipt = "due_on_receipt"
+	EndOfMonth   = "eom"
+)
+
+// maxDays bounds net and discount periods.
+const maxDays = 365
+
+// ErrUnknown is wrapped by Parse errors for unrecognised codes.
+var ErrUnknown = errors.New("unknown payment terms")
+
+var (
+	netRegex      = regexp.MustCompile(`^net_(\d+)$`)
+	discountRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)/(\d+)_net_(\d+)$`)
+)
+
+// Terms are parsed payment terms.
+type Terms struct {
+	Code            string  // canonical code, e.g. "net_30" or "2/10_net_30"
+	NetDays         int     // payment is due this many days after the invoice date
+	EndOfMonth      bool    // payment is due on the last day of the invoice's month
+	DiscountPercent float64 // early-payment discount, or 0 for none
+	DiscountDays    int     // the discount applies if paid within this many days
+}
+
+// Parse parses a terms code. Case, surrounding space and spaces in place of
+// underscores are ignored, so "Net 30" and "2/10 net 30" are accepted.
+func Parse(code string) (Terms, error) {
+	norm := strings.ToLower(strings.Join(strings.Fields(code), "_"))
+
+	switch norm {
+	case DueOnReceipt:
+		return Terms{Code: DueOnReceipt}, nil
+	case EndOfMonth:
+		return Terms{Code: EndOfMonth, EndOfMonth: true}, nil
+	}
+
+	if m := netRegex.FindStringSubmatch(norm); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		if days < 1 || days > maxDays {
+			return Terms{}, fmt.Errorf("%w %q: net days must be between 1 and %d", ErrUnknown, code, maxDays)
+		}
+		return Terms{Code: fmt.Sprintf("net_%d", days), NetDays: days}, nil
+	}
+
+	if m := discountRegex.FindStringSubmatch(norm); m != nil {
+		percent, _ := strconv.ParseFloat(m[1], 64)
+		discountDays, _ := strconv.Atoi(m[2])
+		netDays, _ := strconv.Atoi(m[3])
+		if percent <= 0 || percent >= 100 {
+			return Terms{}, fmt.Errorf("%w %q: discount must be between 0 and 100 percent", ErrUnknown, code)
+		}
+		if netDays < 1 || netDays > maxDays || discountDays < 1 || discountDays >= netDays {
+			return Terms{}, fmt.Errorf("%w %q: discount days must be fewer than net days", ErrUnknown, code)
+		}
+		return Terms{
+			Code:            fmt.Sprintf("%s/%d_net_%d", strconv.FormatFloat(percent, 'f', -1, 64), discountDays, netDays),
+			NetDays:         netDays,
+			DiscountPercent: percent,
+			DiscountDays:    discountDays,
+		}, nil
+	}
+
+	return Terms{}, fmt.Errorf("%w %q: use %s, net_<days>, %s or <percent>/<days>_net_<days>", ErrUnknown, code, DueOnReceipt, EndOfMonth)
+}
+
+// DueDate returns the date payment is due for an invoice dated invoiceDate.
+func (t Terms) DueDate(invoiceDate civil.Date) civil.Date {
+	if t.EndOfMonth {
+		return invoiceDate.EndOfMonth()
+	}
+	return invoiceDate.AddDays(t.NetDays)
+}
+
+// HasDiscount reports whether the terms offer an early-payment discount.
+func (t Terms) HasDiscount() bool {
+	return t.DiscountPercent > 0
+}
+
+// DiscountDeadline returns the last day the early-payment discount applies,
+// or the zero Date if the terms offer none.
+func (t Terms) DiscountDeadline(invoiceDate civil.Date) civil.Date {
+	if !t.HasDiscount() {
+		return civil.Date{}
+	}
+	return invoiceDate.AddDays(t.DiscountDays)
+}
+
+// Label returns the terms as printed on the PDF, e.g. "Net 30" or "2/10 Net 30".
+func (t Terms) Label() string {
+	switch {
+	case t.Code == DueOnReceipt:
+		return "Due on receipt"
+	case t.EndOfMonth:
+		return "End of month"
+	case t.HasDiscount():
+		return fmt.Sprintf("%s/%d Net %d", strconv.FormatFloat(t.DiscountPercent, 'f', -1, 64), t.DiscountDays, t.NetDays)
+	}
+	return fmt.Sprintf("Net %d", t.NetDays)
+}
+
+// Apply normalises the invoice's payment terms and derives its due date from
+// them. Invoices without terms or an invoice date keep their due date; invalid
+// terms are left for validation to report.
+func Apply(invoice *models.Invoice) {
+	if invoice.PaymentTerms == "" {
+		return
+	}
+	t, err := Parse(invoice.PaymentTerms)
+	if err != nil {
+		return
+	}
+	invoice.PaymentTerms = t.Code
+	if !invoice.InvoiceDate.IsZero() {
+		invoice.DueDate = t.DueDate(invoice.InvoiceDate)
+	}
+}
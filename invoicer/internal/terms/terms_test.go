@@ -0,0 +1,112 @@
+package terms
+
+import (
+	"errors"
+	"invoice-generator/invoicer/internal/civil"
+	"invoice-generator/invoicer/internal/models"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code  string
+		want  string
+		label string
+	}{
+		{"due_on_receipt", "due_on_receipt", "Due on receipt"},
+		{"EOM", "eom", "End of month"},
+		{"net_30", "net_30", "Net 30"},
+		{"Net 15", "net_15", "Net 15"},
+		{"2/10 net 30", "2/10_net_30", "2/10 Net 30"},
+		{"1.5/10_NET_60", "1.5/10_net_60", "1.5/10 Net 60"},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.code)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.code, err)
+			continue
+		}
+		if got.Code != tt.want || got.Label() != tt.label {
+			t.Errorf("Parse(%q) = %q / %q, want %q / %q", tt.code, got.Code, got.Label(), tt.want, tt.label)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, code := range []string{"", "net", "net_0", "net_400", "net_-5", "30 days", "2/30_net_30", "0/10_net_30", "100/10_net_30"} {
+		if _, err := Parse(code); !errors.Is(err, ErrUnknown) {
+			t.Errorf("Parse(%q): expected ErrUnknown, got %v", code, err)
+		}
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	date := civil.MustParse("2026-01-31")
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"due_on_receipt", "2026-01-31"},
+		{"net_15", "2026-02-15"},
+		{"net_30", "2026-03-02"},
+		{"eom", "2026-01-31"},
+		{"2/10_net_30", "2026-03-02"},
+	}
+
+	for _, tt := range tests {
+		terms, err := Parse(tt.code)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.code, err)
+		}
+		if got := terms.DueDate(date).String(); got != tt.want {
+			t.Errorf("%s: DueDate = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+
+	eom, _ := Parse("eom")
+	if got := eom.DueDate(civil.MustParse("2028-02-03")).String(); got != "2028-02-29" {
+		t.Errorf("eom in a leap February: got %s, want 2028-02-29", got)
+	}
+}
+
+func TestDiscountDeadline(t *testing.T) {
+	date := civil.MustParse("2026-03-01")
+
+	discount, _ := Parse("2/10_net_30")
+	if !discount.HasDiscount() || discount.DiscountPercent != 2 {
+		t.Fatalf("expected a 2%% discount, got %+v", discount)
+	}
+	if got := discount.DiscountDeadline(date).String(); got != "2026-03-11" {
+		t.Errorf("DiscountDeadline = %s, want 2026-03-11", got)
+	}
+
+	net, _ := Parse("net_30")
+	if net.HasDiscount() || !net.DiscountDeadline(date).IsZero() {
+		t.Errorf("expected net terms to offer no discount, got %+v", net)
+	}
+}
+
+func TestApply(t *testing.T) {
+	invoice := &models.Invoice{
+		InvoiceDate:  civil.MustParse("2026-03-01"),
+		DueDate:      civil.MustParse("2026-12-31"),
+		PaymentTerms: "Net 30",
+	}
+	Apply(invoice)
+	if invoice.PaymentTerms != "net_30" || invoice.DueDate.String() != "2026-03-31" {
+		t.Errorf("expected net_30 due 2026-03-31, got %q due %s", invoice.PaymentTerms, invoice.DueDate)
+	}
+
+	manual := &models.Invoice{InvoiceDate: civil.MustParse("2026-03-01"), DueDate: civil.MustParse("2026-03-20")}
+	Apply(manual)
+	if manual.DueDate.String() != "2026-03-20" {
+		t.Errorf("expected invoices without terms to keep their due date, got %s", manual.DueDate)
+	}
+
+	invalid := &models.Invoice{InvoiceDate: civil.MustParse("2026-03-01"), PaymentTerms: "whenever"}
+	Apply(invalid)
+	if invalid.PaymentTerms != "whenever" || !invalid.DueDate.IsZero() {
+		t.Errorf("expected invalid terms to be left for validation, got %q due %s", invalid.PaymentTerms, invalid.DueDate)
+	}
+}
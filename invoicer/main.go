@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"invoice-generator/invoicer/internal/auth"
+	"invoice-generator/invoicer/internal/directory"
+	"invoice-generator/invoicer/internal/fx"
 	"invoice-generator/invoicer/internal/handlers"
 	"invoice-generator/invoicer/internal/middleware"
+	"invoice-generator/invoicer/internal/numbering"
+	"invoice-generator/invoicer/internal/overdue"
+	"invoice-generator/invoicer/internal/scheduler"
+	"invoice-generator/invoicer/internal/store"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -27,7 +35,70 @@ func main() {
 
 	// Initialize auth services
 	jwtService := auth.NewJWTService(authConfig.JWTSecret, authConfig.JWTExpiry, authConfig.JWTRefreshExpiry)
-	userStore := auth.NewUserStore()
+	userStore, err := auth.NewUserStoreFromConfig(authConfig)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize user store: %v", err)
+	}
+
+	// Invoices, schedules, number sequences, snapshots, history, clients,
+	// business profiles, the catalog, promo codes and exchange rates are kept
+	// in SQLite unless INVOICE_STORE=memory, so recurring billing, numbering
+	// and late fees carry on where they left off after a restart.
+	invoiceStoreDriver := "sqlite"
+	if v := os.Getenv("INVOICE_STORE"); v != "" {
+		if v != "sqlite" && v != "memory" {
+			log.Fatalf("❌ Invalid INVOICE_STORE %q: must be \"sqlite\" or \"memory\"", v)
+		}
+		invoiceStoreDriver = v
+	}
+	var (
+		invoiceStore      store.InvoiceStore
+		numberStore       numbering.Store
+		recurringStore    store.RecurringStore
+		auditStore        store.AuditStore
+		snapshotStore     store.SnapshotStore
+		clientStore       store.ClientStore
+		profileStore      store.BusinessProfileStore
+		catalogStore      store.CatalogStore
+		promoCodeStore    store.PromoCodeStore
+		exchangeRateStore store.ExchangeRateStore
+	)
+	if invoiceStoreDriver == "sqlite" {
+		db, err := store.OpenSQLite(authConfig.DatabasePath)
+		if err != nil {
+			log.Fatalf("❌ Failed to open invoice database: %v", err)
+		}
+		defer db.Close()
+		invoiceStore = store.NewSQLiteInvoiceStore(db)
+		numberStore = numbering.NewSQLiteStore(db)
+		recurringStore = store.NewSQLiteRecurringStore(db)
+		auditStore = store.NewSQLiteAuditStore(db)
+		snapshotStore = store.NewSQLiteSnapshotStore(db)
+		clientStore = store.NewSQLiteClientStore(db)
+		profileStore = store.NewSQLiteBusinessProfileStore(db)
+		catalogStore = store.NewSQLiteCatalogStore(db)
+		promoCodeStore = store.NewSQLitePromoCodeStore(db)
+		exchangeRateStore = store.NewSQLiteExchangeRateStore(db)
+	} else {
+		auditStore = store.NewMemoryAuditStore()
+		promoCodeStore = store.NewMemoryPromoCodeStore()
+		invoiceStore = store.NewMemoryInvoiceStore(auditStore, promoCodeStore)
+		numberStore = numbering.NewMemoryStore()
+		recurringStore = store.NewMemoryRecurringStore()
+		snapshotStore = store.NewMemorySnapshotStore()
+		clientStore = store.NewMemoryClientStore()
+		profileStore = store.NewMemoryBusinessProfileStore()
+		catalogStore = store.NewMemoryCatalogStore()
+		exchangeRateStore = store.NewMemoryExchangeRateStore()
+	}
+	ratesFile := os.Getenv("EXCHANGE_RATES_FILE")
+	ratesLoaded := 0
+	if ratesFile != "" {
+		if ratesLoaded, err = fx.Load(ratesFile, exchangeRateStore); err != nil {
+			log.Fatalf("❌ Failed to load exchange rates from %s: %v", ratesFile, err)
+		}
+	}
+	dir := directory.New(clientStore, profileStore, catalogStore)
 	oauthService := auth.NewOAuthService(
 		authConfig.GoogleClientID,
 		authConfig.GoogleClientSecret,
@@ -45,7 +116,18 @@ func main() {
 	router.Use(rateLimiter.Middleware())
 
 	// Initialize handlers
-	invoiceHandler := handlers.NewInvoiceHandler()
+	totalsPolicy, err := handlers.ParseTotalsPolicy(os.Getenv("TOTALS_POLICY"))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceStore, numberStore, dir, promoCodeStore, exchangeRateStore, auditStore, snapshotStore, totalsPolicy)
+	recurringHandler := handlers.NewRecurringHandler(recurringStore, dir)
+	clientHandler := handlers.NewClientHandler(clientStore)
+	profileHandler := handlers.NewBusinessProfileHandler(profileStore)
+	catalogHandler := handlers.NewCatalogHandler(catalogStore)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeStore)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateStore)
+	reportHandler := handlers.NewReportHandler(invoiceStore)
 	authHandler := handlers.NewAuthHandler(jwtService, userStore, oauthService)
 
 	// ── Public routes (no auth required) ─────────────────────────────
@@ -64,6 +146,94 @@ func main() {
 	protectedRouter.Use(middleware.AuthMiddleware(jwtService))
 	protectedRouter.HandleFunc("/generate-pdf", invoiceHandler.GeneratePDF).Methods("POST", "OPTIONS")
 
+	// Invoice persistence (scoped to the authenticated user)
+	protectedRouter.HandleFunc("/invoices", invoiceHandler.ListInvoices).Methods("GET")
+	protectedRouter.HandleFunc("/invoices", invoiceHandler.CreateInvoice).Methods("POST")
+	protectedRouter.HandleFunc("/invoices/next-number", invoiceHandler.NextInvoiceNumber).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}", invoiceHandler.GetInvoice).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}", invoiceHandler.UpdateInvoice).Methods("PUT")
+	protectedRouter.HandleFunc("/invoices/{id}", invoiceHandler.DeleteInvoice).Methods("DELETE")
+	protectedRouter.HandleFunc("/invoices/{id}/status", invoiceHandler.ChangeInvoiceStatus).Methods("POST")
+	protectedRouter.HandleFunc("/invoices/{id}/history", invoiceHandler.InvoiceHistory).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}/pdf", invoiceHandler.InvoicePDF).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}/snapshot", invoiceHandler.InvoiceSnapshot).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}/revisions", invoiceHandler.ReviseInvoice).Methods("POST")
+	protectedRouter.HandleFunc("/invoices/{id}/payments", invoiceHandler.ListPayments).Methods("GET")
+	protectedRouter.HandleFunc("/invoices/{id}/payments", invoiceHandler.RecordPayment).Methods("POST")
+	protectedRouter.HandleFunc("/invoices/{id}/credit-notes", invoiceHandler.CreateCreditNote).Methods("POST")
+
+	// Quotes (estimates)
+	protectedRouter.HandleFunc("/quotes", invoiceHandler.ListQuotes).Methods("GET")
+	protectedRouter.HandleFunc("/quotes", invoiceHandler.CreateQuote).Methods("POST")
+	protectedRouter.HandleFunc("/quotes/{id}", invoiceHandler.GetQuote).Methods("GET")
+	protectedRouter.HandleFunc("/quotes/{id}", invoiceHandler.UpdateQuote).Methods("PUT")
+	protectedRouter.HandleFunc("/quotes/{id}", invoiceHandler.DeleteQuote).Methods("DELETE")
+	protectedRouter.HandleFunc("/quotes/{id}/status", invoiceHandler.ChangeQuoteStatus).Methods("POST")
+	protectedRouter.HandleFunc("/quotes/{id}/history", invoiceHandler.QuoteHistory).Methods("GET")
+	protectedRouter.HandleFunc("/quotes/{id}/convert", invoiceHandler.ConvertQuote).Methods("POST")
+
+	// Invoice numbering
+	protectedRouter.HandleFunc("/settings/numbering", invoiceHandler.GetNumberingScheme).Methods("GET")
+	protectedRouter.HandleFunc("/settings/numbering", invoiceHandler.UpdateNumberingScheme).Methods("PUT")
+
+	// Client directory
+	protectedRouter.HandleFunc("/clients", clientHandler.ListClients).Methods("GET")
+	protectedRouter.HandleFunc("/clients", clientHandler.CreateClient).Methods("POST")
+	protectedRouter.HandleFunc("/clients/{id}", clientHandler.GetClient).Methods("GET")
+	protectedRouter.HandleFunc("/clients/{id}", clientHandler.UpdateClient).Methods("PUT")
+	protectedRouter.HandleFunc("/clients/{id}", clientHandler.DeleteClient).Methods("DELETE")
+
+	// Business profiles
+	protectedRouter.HandleFunc("/business-profiles", profileHandler.ListProfiles).Methods("GET")
+	protectedRouter.HandleFunc("/business-profiles", profileHandler.CreateProfile).Methods("POST")
+	protectedRouter.HandleFunc("/business-profiles/{id}", profileHandler.GetProfile).Methods("GET")
+	protectedRouter.HandleFunc("/business-profiles/{id}", profileHandler.UpdateProfile).Methods("PUT")
+	protectedRouter.HandleFunc("/business-profiles/{id}", profileHandler.DeleteProfile).Methods("DELETE")
+
+	// Product and service catalog
+	protectedRouter.HandleFunc("/catalog", catalogHandler.ListItems).Methods("GET")
+	protectedRouter.HandleFunc("/catalog", catalogHandler.CreateItem).Methods("POST")
+	protectedRouter.HandleFunc("/catalog/{id}", catalogHandler.GetItem).Methods("GET")
+	protectedRouter.HandleFunc("/catalog/{id}", catalogHandler.UpdateItem).Methods("PUT")
+	protectedRouter.HandleFunc("/catalog/{id}", catalogHandler.DeleteItem).Methods("DELETE")
+
+	// Promo codes
+	protectedRouter.HandleFunc("/promo-codes", promoCodeHandler.ListCodes).Methods("GET")
+	protectedRouter.HandleFunc("/promo-codes", promoCodeHandler.CreateCode).Methods("POST")
+	protectedRouter.HandleFunc("/promo-codes/{id}", promoCodeHandler.GetCode).Methods("GET")
+	protectedRouter.HandleFunc("/promo-codes/{id}", promoCodeHandler.UpdateCode).Methods("PUT")
+	protectedRouter.HandleFunc("/promo-codes/{id}", promoCodeHandler.DeleteCode).Methods("DELETE")
+
+	// Exchange rates (updated by administrators)
+	protectedRouter.HandleFunc("/exchange-rates", exchangeRateHandler.ListRates).Methods("GET")
+	adminRouter := protectedRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.RequireAdmin(strings.Split(os.Getenv("ADMIN_EMAILS"), ",")))
+	adminRouter.HandleFunc("/exchange-rates", exchangeRateHandler.PutRates).Methods("POST")
+
+	// Finance reports (JSON, or CSV with ?format=csv)
+	protectedRouter.HandleFunc("/reports/revenue", reportHandler.Revenue).Methods("GET")
+	protectedRouter.HandleFunc("/reports/aging", reportHandler.Aging).Methods("GET")
+	protectedRouter.HandleFunc("/reports/tax", reportHandler.Tax).Methods("GET")
+
+	// Recurring invoice schedules
+	protectedRouter.HandleFunc("/recurring", recurringHandler.ListSchedules).Methods("GET")
+	protectedRouter.HandleFunc("/recurring", recurringHandler.CreateSchedule).Methods("POST")
+	protectedRouter.HandleFunc("/recurring/{id}", recurringHandler.GetSchedule).Methods("GET")
+	protectedRouter.HandleFunc("/recurring/{id}", recurringHandler.UpdateSchedule).Methods("PUT")
+	protectedRouter.HandleFunc("/recurring/{id}", recurringHandler.DeleteSchedule).Methods("DELETE")
+
+	// Background generation of recurring invoices
+	schedulerInterval := time.Hour
+	if v := os.Getenv("SCHEDULER_INTERVAL"); v != "" {
+		if schedulerInterval, err = time.ParseDuration(v); err != nil || schedulerInterval <= 0 {
+			log.Fatalf("❌ Invalid SCHEDULER_INTERVAL %q: must be a positive duration such as 1h or 15m", v)
+		}
+	}
+	go scheduler.New(recurringStore, invoiceStore, numberStore, dir, exchangeRateStore, snapshotStore, schedulerInterval).Run(context.Background())
+
+	// Background overdue detection and late fees
+	go overdue.NewJob(invoiceStore, profileStore, schedulerInterval).Run(context.Background())
+
 	// Get allowed origins from environment
 	allowedOriginsEnv := os.Getenv("ALLOWED_ORIGINS")
 	var allowedOrigins []string
@@ -76,8 +246,9 @@ func main() {
 	// Setup CORS
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Total-Count", "X-Next-Cursor"},
 		AllowCredentials: true,
 	})
 
@@ -88,6 +259,12 @@ func main() {
 	port := "8080"
 	fmt.Printf("🚀 Invoice Generator API server starting on port %s\n", port)
 	fmt.Printf("📄 PDF generation endpoint: http://localhost:%s/api/generate-pdf (🔒 protected)\n", port)
+	fmt.Printf("🧾 Invoice endpoints:       http://localhost:%s/api/invoices (🔒 protected)\n", port)
+	fmt.Printf("🔁 Recurring schedules:     http://localhost:%s/api/recurring (🔒 protected, checked every %s)\n", port, schedulerInterval)
+	fmt.Printf("⏰ Overdue invoices:        checked every %s\n", schedulerInterval)
+	if ratesFile != "" {
+		fmt.Printf("💱 Exchange rates:          %d day(s) loaded from %s\n", ratesLoaded, ratesFile)
+	}
 	fmt.Printf("🔑 Auth endpoints:          http://localhost:%s/api/auth/*\n", port)
 	fmt.Printf("💚 Health check endpoint:    http://localhost:%s/health\n", port)
 	if oauthService != nil {
@@ -95,6 +272,16 @@ func main() {
 	} else {
 		fmt.Println("⚠️  Google OAuth is not configured (set GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET)")
 	}
+	if authConfig.UserStoreDriver == "sqlite" {
+		fmt.Printf("🗄️  User store:               sqlite (%s)\n", authConfig.DatabasePath)
+	} else {
+		fmt.Println("⚠️  User store is in-memory; accounts are lost on restart (set USER_STORE=sqlite)")
+	}
+	if invoiceStoreDriver == "sqlite" {
+		fmt.Printf("🗄️  Invoice store:            sqlite (%s)\n", authConfig.DatabasePath)
+	} else {
+		fmt.Println("⚠️  Invoice store is in-memory; invoices are lost on restart (set INVOICE_STORE=sqlite)")
+	}
 	fmt.Printf("🛡️  Rate limiting:            %d req/min (anonymous), %d req/min (authenticated)\n",
 		authConfig.RateLimitPerMin, authConfig.RateLimitAuthPerMin)
 
@@ -1,42 +1,96 @@
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { FileText, Search, Trash2, Edit, Eye, Calendar, DollarSign } from 'lucide-react';
-import { getInvoices, deleteInvoice } from '../utils/storage';
+import { listInvoices, deleteInvoice } from '../utils/invoiceApi';
 import { CURRENCIES } from '../utils/constants';
+import { useAuth } from '../context/AuthContext';
 import { useDialog } from '../context/DialogContext';
 import { useToast } from '../context/ToastContext';
 
+const PAGE_SIZE = 24;
+
+const STATUSES = [
+  { value: '', label: 'All statuses' },
+  { value: 'draft', label: 'Draft' },
+  { value: 'issued', label: 'Issued' },
+  { value: 'sent', label: 'Sent' },
+  { value: 'viewed', label: 'Viewed' },
+  { value: 'partially_paid', label: 'Partially paid' },
+  { value: 'paid', label: 'Paid' },
+  { value: 'overdue', label: 'Overdue' },
+  { value: 'void', label: 'Void' },
+];
+
+const SORTS = [
+  { value: '-createdAt', label: 'Newest first' },
+  { value: 'createdAt', label: 'Oldest first' },
+  { value: '-invoiceDate', label: 'Invoice date' },
+  { value: 'dueDate', label: 'Due date' },
+  { value: '-total', label: 'Highest total' },
+  { value: 'total', label: 'Lowest total' },
+  { value: 'clientName', label: 'Client' },
+  { value: 'invoiceNumber', label: 'Invoice number' },
+];
+
 function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
+  const { token } = useAuth();
   const { confirm } = useDialog();
   const { addToast } = useToast();
   const [invoices, setInvoices] = useState([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState(null);
+  const [loading, setLoading] = useState(true);
   const [searchTerm, setSearchTerm] = useState('');
-  const [filteredInvoices, setFilteredInvoices] = useState([]);
+  const [search, setSearch] = useState('');
+  const [status, setStatus] = useState('');
+  const [sort, setSort] = useState('-createdAt');
+  const [reload, setReload] = useState(0);
+  const listing = useRef(0); // bumped whenever the first page is reloaded
 
-  // Load invoices on mount
+  // Wait for typing to pause before searching
   useEffect(() => {
-    loadInvoices();
-  }, []);
+    const timer = setTimeout(() => setSearch(searchTerm.trim()), 300);
+    return () => clearTimeout(timer);
+  }, [searchTerm]);
+
+  // Filtering, sorting and paging are done by the server; a change of
+  // filters or sort starts again from the first page.
+  const query = { q: search, status, sort, limit: PAGE_SIZE };
 
-  // Filter invoices when search term changes
   useEffect(() => {
-    if (searchTerm.trim() === '') {
-      setFilteredInvoices(invoices);
-    } else {
-      const filtered = invoices.filter(invoice => 
-        invoice.invoiceNumber?.toLowerCase().includes(searchTerm.toLowerCase()) ||
-        invoice.clientName?.toLowerCase().includes(searchTerm.toLowerCase()) ||
-        invoice.businessName?.toLowerCase().includes(searchTerm.toLowerCase())
-      );
-      setFilteredInvoices(filtered);
+    let ignore = false;
+    listing.current += 1;
+    setLoading(true);
+    listInvoices(token, query)
+      .then((page) => {
+        if (ignore) return;
+        setInvoices(page.invoices);
+        setTotal(page.total);
+        setNextCursor(page.nextCursor);
+      })
+      .catch((error) => {
+        if (!ignore) addToast(error.message, 'error');
+      })
+      .finally(() => {
+        if (!ignore) setLoading(false);
+      });
+    return () => { ignore = true; };
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [token, search, status, sort, reload]);
+
+  const loadMore = async () => {
+    const current = listing.current;
+    setLoading(true);
+    try {
+      const page = await listInvoices(token, { ...query, cursor: nextCursor });
+      if (current !== listing.current) return;
+      setInvoices((loaded) => [...loaded, ...page.invoices]);
+      setTotal(page.total);
+      setNextCursor(page.nextCursor);
+    } catch (error) {
+      if (current === listing.current) addToast(error.message, 'error');
+    } finally {
+      if (current === listing.current) setLoading(false);
     }
-  }, [searchTerm, invoices]);
-
-  const loadInvoices = () => {
-    const data = getInvoices();
-    // Sort by creation date (newest first)
-    const sorted = data.sort((a, b) => new Date(b.createdAt) - new Date(a.createdAt));
-    setInvoices(sorted);
-    setFilteredInvoices(sorted);
   };
 
   const handleDelete = async (id) => {
@@ -49,9 +103,13 @@ function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
     });
 
     if (isConfirmed) {
-      deleteInvoice(id);
-      loadInvoices();
-      addToast('Invoice deleted successfully', 'success');
+      try {
+        await deleteInvoice(token, id);
+        setReload((n) => n + 1);
+        addToast('Invoice deleted successfully', 'success');
+      } catch (error) {
+        addToast(error.message, 'error');
+      }
     }
   };
 
@@ -74,8 +132,10 @@ function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
     return CURRENCIES.find(c => c.code === code)?.symbol || '$';
   };
 
+  const filtered = search !== '' || status !== '';
+
   // Empty state
-  if (invoices.length === 0) {
+  if (!loading && total === 0 && !filtered) {
     return (
       <div className="container mx-auto px-4 py-16">
         <div className="max-w-md mx-auto text-center">
@@ -104,7 +164,7 @@ function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
         <div className="flex items-center justify-between mb-6">
           <div>
             <h2 className="text-3xl font-bold text-gray-900">My Invoices</h2>
-            <p className="text-gray-600 mt-1">{invoices.length} invoice{invoices.length !== 1 ? 's' : ''} total</p>
+            <p className="text-gray-600 mt-1">{total} invoice{total !== 1 ? 's' : ''} {filtered ? 'found' : 'total'}</p>
           </div>
           <button
             onClick={() => setCurrentView('form')}
@@ -115,27 +175,47 @@ function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
           </button>
         </div>
 
-        {/* Search Bar */}
-        <div className="relative">
-          <Search className="absolute left-3 top-1/2 transform -translate-y-1/2 w-5 h-5 text-gray-400" />
-          <input
-            type="text"
-            placeholder="Search by invoice number, client, or business name..."
-            value={searchTerm}
-            onChange={(e) => setSearchTerm(e.target.value)}
-            className="w-full pl-10 pr-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
-          />
+        {/* Search Bar and Filters */}
+        <div className="flex flex-col md:flex-row gap-3">
+          <div className="relative flex-1">
+            <Search className="absolute left-3 top-1/2 transform -translate-y-1/2 w-5 h-5 text-gray-400" />
+            <input
+              type="text"
+              placeholder="Search by invoice number, client, or line description..."
+              value={searchTerm}
+              onChange={(e) => setSearchTerm(e.target.value)}
+              className="w-full pl-10 pr-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+            />
+          </div>
+          <select
+            value={status}
+            onChange={(e) => setStatus(e.target.value)}
+            className="px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+          >
+            {STATUSES.map(({ value, label }) => (
+              <option key={value} value={value}>{label}</option>
+            ))}
+          </select>
+          <select
+            value={sort}
+            onChange={(e) => setSort(e.target.value)}
+            className="px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+          >
+            {SORTS.map(({ value, label }) => (
+              <option key={value} value={value}>{label}</option>
+            ))}
+          </select>
         </div>
       </div>
 
       {/* Invoice Grid */}
-      {filteredInvoices.length === 0 ? (
+      {invoices.length === 0 ? (
         <div className="text-center py-12">
-          <p className="text-gray-500">No invoices match your search.</p>
+          <p className="text-gray-500">{loading ? 'Loading invoices...' : 'No invoices match your search.'}</p>
         </div>
       ) : (
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
-          {filteredInvoices.map((invoice) => (
+          {invoices.map((invoice) => (
             <div
               key={invoice.id}
               className="bg-white rounded-lg shadow-sm border border-gray-200 hover:shadow-md transition-shadow"
@@ -223,6 +303,19 @@ function InvoiceList({ setCurrentView, setInvoiceData, setSelectedTemplate }) {
           ))}
         </div>
       )}
+
+      {/* Next Page */}
+      {nextCursor && (
+        <div className="flex justify-center mt-8">
+          <button
+            onClick={loadMore}
+            disabled={loading}
+            className="px-6 py-3 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors disabled:opacity-50"
+          >
+            {loading ? 'Loading...' : `Load more (${total - invoices.length} remaining)`}
+          </button>
+        </div>
+      )}
     </div>
   );
 }
@@ -0,0 +1,44 @@
+const API_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080/api';
+
+/**
+ * Make an authenticated invoice API request.
+ * Throws an Error with the server message on failure.
+ */
+async function invoiceFetch(token, path, options = {}) {
+    const res = await fetch(`${API_URL}/invoices${path}`, {
+        ...options,
+        headers: { Authorization: `Bearer ${token}` },
+    });
+
+    if (!res.ok) {
+        const data = await res.json().catch(() => ({}));
+        throw new Error(data.message || 'Something went wrong');
+    }
+
+    return res;
+}
+
+/**
+ * Fetch one page of invoices. `params` holds the listing query parameters
+ * (q, status, sort, limit, cursor, ...); empty values are left out.
+ * Resolves to { invoices, total, nextCursor }; nextCursor is null on the last page.
+ */
+export async function listInvoices(token, params) {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([name, value]) => {
+        if (value !== undefined && value !== null && value !== '') {
+            query.set(name, value);
+        }
+    });
+
+    const res = await invoiceFetch(token, `?${query}`);
+    return {
+        invoices: await res.json(),
+        total: Number(res.headers.get('X-Total-Count')) || 0,
+        nextCursor: res.headers.get('X-Next-Cursor'),
+    };
+}
+
+export async function deleteInvoice(token, id) {
+    await invoiceFetch(token, `/${encodeURIComponent(id)}`, { method: 'DELETE' });
+}